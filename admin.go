@@ -0,0 +1,87 @@
+package goLogger
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// * mount behind auth, e.g. mux.Handle("/admin/log/", http.StripPrefix("/admin/log", logger.AdminHandler()))
+func (l *Logger) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, l.Stats())
+	})
+
+	mux.HandleFunc("GET /level", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, map[string]string{"level": l.Config().Level})
+	})
+
+	mux.HandleFunc("PUT /level", func(w http.ResponseWriter, r *http.Request) {
+		level := r.URL.Query().Get("level")
+		if level == "" {
+			var body struct {
+				Level string `json:"level"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			level = body.Level
+		}
+
+		// * with duration the change is temporary, see Elevate
+		if value := r.URL.Query().Get("duration"); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			if _, err := l.elevate(level, duration); err != nil {
+				writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+		} else if err := l.SetLevel(level); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]string{"level": l.Config().Level})
+	})
+
+	mux.HandleFunc("POST /rotate", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if file := r.URL.Query().Get("file"); file != "" {
+			err = l.Rotate(file)
+		} else {
+			err = l.RotateAll()
+		}
+		writeAdminResult(w, err)
+	})
+
+	mux.HandleFunc("POST /flush", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch {
+		case r.URL.Query().Get("file") != "":
+			err = l.FlushFile(r.URL.Query().Get("file"))
+		case r.URL.Query().Get("level") != "":
+			err = l.FlushLevel(r.URL.Query().Get("level"))
+		default:
+			err = l.Flush()
+		}
+		writeAdminResult(w, err)
+	})
+
+	return mux
+}
+
+func writeAdminResult(w http.ResponseWriter, err error) {
+	if err != nil {
+		writeAdminJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,141 @@
+package goLogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	defaultAlertWindow = time.Minute
+	alertTimeout       = 10 * time.Second
+)
+
+type AlertRule struct {
+	Name     string        `json:"name,omitempty"`     // 規則名稱，預設 "alert <索引>"
+	Level    string        `json:"level,omitempty"`    // 此層級以上的日誌才計數，預設 "ERROR"
+	Match    string        `json:"match,omitempty"`    // 訊息符合此正規表示式時才計數，預設全部
+	Count    int           `json:"count"`              // 窗口內達到此筆數時觸發
+	Window   time.Duration `json:"window,omitempty"`   // 計數窗口，預設 1 分鐘
+	Cooldown time.Duration `json:"cooldown,omitempty"` // 觸發後的冷卻時間，期間不再觸發，預設同 Window
+	Webhook  string        `json:"webhook,omitempty"`  // 觸發時以 POST 送出 Alert JSON 的網址
+	Callback func(Alert)   `json:"-"`                  // 觸發時呼叫，於獨立 goroutine 執行，可安全寫入日誌
+}
+
+type Alert struct {
+	Name    string        `json:"name"`    // 規則名稱
+	Level   string        `json:"level"`   // 觸發那一筆的層級
+	Message string        `json:"message"` // 觸發那一筆的訊息
+	Count   int           `json:"count"`   // 窗口內的筆數
+	Window  time.Duration `json:"window"`  // 計數窗口
+	Time    time.Time     `json:"time"`    // 觸發時間
+}
+
+type alert struct {
+	rule     AlertRule
+	minLevel string
+	match    *regexp.Regexp
+	hits     []time.Time
+	until    time.Time
+}
+
+func compileAlerts(rules []AlertRule) ([]*alert, error) {
+	alerts := make([]*alert, 0, len(rules))
+	for i, rule := range rules {
+		a := &alert{rule: rule, minLevel: logError}
+		if a.rule.Name == "" {
+			a.rule.Name = fmt.Sprintf("alert %d", i)
+		}
+		if a.rule.Window == 0 {
+			a.rule.Window = defaultAlertWindow
+		}
+		if a.rule.Cooldown == 0 {
+			a.rule.Cooldown = a.rule.Window
+		}
+		if rule.Level != "" {
+			level, err := parseLevel(rule.Level)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to compile alert %d: %w", i, err)
+			}
+			a.minLevel = level
+		}
+		if rule.Match != "" {
+			match, err := regexp.Compile(rule.Match)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to compile alert %d: %w", i, err)
+			}
+			a.match = match
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
+
+// * called under lock after the entry is written, delivery runs outside of it
+func (l *Logger) checkAlerts(entry Entry) {
+	for _, a := range l.alerts {
+		if levelRank[entry.Level] < levelRank[a.minLevel] {
+			continue
+		}
+		if a.match != nil && !a.match.MatchString(entry.Message) {
+			continue
+		}
+
+		now := l.now()
+		// * sliding window, drop hits that fell out of it
+		start := now.Add(-a.rule.Window)
+		kept := a.hits[:0]
+		for _, hit := range a.hits {
+			if hit.After(start) {
+				kept = append(kept, hit)
+			}
+		}
+		a.hits = append(kept, now)
+
+		if len(a.hits) < a.rule.Count || now.Before(a.until) {
+			continue
+		}
+		a.until = now.Add(a.rule.Cooldown)
+		fired := Alert{
+			Name:    a.rule.Name,
+			Level:   entry.Level,
+			Message: entry.Message,
+			Count:   len(a.hits),
+			Window:  a.rule.Window,
+			Time:    now,
+		}
+		a.hits = nil
+
+		if a.rule.Callback != nil {
+			go a.rule.Callback(fired)
+		}
+		if a.rule.Webhook != "" {
+			l.alerting.Add(1)
+			go l.postAlert(a.rule.Webhook, fired)
+		}
+	}
+}
+
+func (l *Logger) postAlert(target string, fired Alert) {
+	defer l.alerting.Done()
+
+	body, err := json.Marshal(fired)
+	if err != nil {
+		l.internalError(fmt.Errorf("Failed to encode alert %s: %w", fired.Name, err))
+		return
+	}
+	client := &http.Client{Timeout: alertTimeout}
+	resp, err := client.Post(target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		l.internalError(fmt.Errorf("Failed to send alert %s: %w", fired.Name, err))
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		l.internalError(fmt.Errorf("Failed to send alert %s: %s", fired.Name, resp.Status))
+	}
+}
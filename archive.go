@@ -0,0 +1,38 @@
+package goLogger
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type Archiver interface {
+	Archive(ctx context.Context, path string) error
+}
+
+// * upload a rotated backup, and its checksum sidecar when present; runs without
+// * the lock, so rotate passes the settings read under it
+func (l *Logger) archive(fsys FS, archiver Archiver, remove bool, path string) {
+	defer l.archiving.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	paths := []string{path}
+	if _, err := fsys.Stat(path + checksumExt); err == nil {
+		paths = append(paths, path+checksumExt)
+	}
+
+	for _, p := range paths {
+		if err := archiver.Archive(ctx, p); err != nil {
+			l.internalError(fmt.Errorf("Failed to archive %s: %w", p, err))
+			return
+		}
+	}
+
+	if remove {
+		for _, p := range paths {
+			fsys.Remove(p)
+		}
+	}
+}
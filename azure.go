@@ -0,0 +1,150 @@
+package goLogger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var azureLogType = regexp.MustCompile(`^[A-Za-z0-9_]{1,100}$`)
+
+// 透過 HTTP Data Collector API 將日誌批次送至 Azure Monitor Log Analytics 工作區
+type AzureSink struct {
+	WorkspaceID string          // Log Analytics 工作區 ID
+	SharedKey   string          // 工作區主要或次要金鑰（base64）
+	LogType     string          // 自訂日誌類型，Azure 會加上 _CL 後綴，預設 "GoLogger"
+	Endpoint    string          // 自訂端點，預設 https://<WorkspaceID>.ods.opinsights.azure.com
+	MinLevel    string          // 送出的最低層級，預設全部
+	BatchSize   int             // 單次請求最多筆數，預設 100
+	Interval    time.Duration   // 批次送出的間隔，預設 5 秒
+	Retry       *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker     *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue       *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	Client      *http.Client    // 預設逾時 30 秒的 http.Client
+	once        sync.Once
+	batcher     *batcher
+	key         []byte
+	logType     string
+	minLevel    string
+	initErr     error
+}
+
+func (a *AzureSink) init() error {
+	a.once.Do(func() {
+		if a.WorkspaceID == "" || a.SharedKey == "" {
+			a.initErr = fmt.Errorf("WorkspaceID and SharedKey are required")
+			return
+		}
+		if a.key, a.initErr = base64.StdEncoding.DecodeString(a.SharedKey); a.initErr != nil {
+			a.initErr = fmt.Errorf("SharedKey must be base64: %w", a.initErr)
+			return
+		}
+		a.logType = a.LogType
+		if a.logType == "" {
+			a.logType = "GoLogger"
+		}
+		if !azureLogType.MatchString(a.logType) {
+			a.initErr = fmt.Errorf("LogType %q may only contain letters, digits and _", a.logType)
+			return
+		}
+		if a.MinLevel != "" {
+			if a.minLevel, a.initErr = parseLevel(a.MinLevel); a.initErr != nil {
+				return
+			}
+		}
+		a.batcher, a.initErr = newBatcher(a.BatchSize, a.Interval, a.Retry, a.Breaker, a.Queue, a.send)
+	})
+	return a.initErr
+}
+
+func (a *AzureSink) reportErrors(report func(error)) {
+	if a.init() == nil {
+		a.batcher.setReport(report)
+	}
+}
+
+func (a *AzureSink) Write(entry Entry) error {
+	if err := a.init(); err != nil {
+		return err
+	}
+	if a.minLevel != "" && levelRank[entry.Level] < levelRank[a.minLevel] {
+		return nil
+	}
+	a.batcher.add(entry)
+	return nil
+}
+
+func (a *AzureSink) Flush() {
+	if a.init() == nil {
+		a.batcher.Flush()
+	}
+}
+
+func (a *AzureSink) Close() {
+	if a.init() == nil {
+		a.batcher.Close()
+	}
+}
+
+func (a *AzureSink) undelivered() int {
+	if a.init() != nil {
+		return 0
+	}
+	return a.batcher.undelivered()
+}
+
+func (a *AzureSink) queued() int {
+	if a.init() != nil {
+		return 0
+	}
+	return a.batcher.queued()
+}
+
+func (a *AzureSink) send(entries []Entry) error {
+	records := make([]map[string]any, len(entries))
+	for i, entry := range entries {
+		record := entryAttributes(entry)
+		delete(record, "time")
+		record["TimeGenerated"] = entry.Time.UTC().Format(time.RFC3339Nano)
+		records[i] = record
+	}
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("Failed to encode Azure batch: %w", err)
+	}
+
+	target := a.Endpoint
+	if target == "" {
+		target = "https://" + a.WorkspaceID + ".ods.opinsights.azure.com"
+	}
+	target += "/api/logs?api-version=2016-04-01"
+
+	date := time.Now().UTC().Format(http.TimeFormat)
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	if err := postBatch(ctx, a.Client, target, body, "", map[string]string{
+		"Authorization":        "SharedKey " + a.WorkspaceID + ":" + a.signature(len(body), date),
+		"Log-Type":             a.logType,
+		"x-ms-date":            date,
+		"time-generated-field": "TimeGenerated",
+	}); err != nil {
+		return fmt.Errorf("Failed to send to Azure Monitor: %w", err)
+	}
+	return nil
+}
+
+// * shared key authorization of the Data Collector API
+func (a *AzureSink) signature(length int, date string) string {
+	text := fmt.Sprintf("POST\n%d\napplication/json\nx-ms-date:%s\n/api/logs", length, date)
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(text))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,126 @@
+package goLogger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	backupTimestamp = "timestamp"
+	backupMillis    = "millis"
+	backupSequence  = "sequence"
+)
+
+var layoutTokens = []struct {
+	token   string
+	pattern string
+}{
+	{".000000000", `\.\d{9}`},
+	{".000000", `\.\d{6}`},
+	{".000", `\.\d{3}`},
+	{"2006", `\d{4}`},
+	{"01", `\d{2}`},
+	{"02", `\d{2}`},
+	{"15", `\d{2}`},
+	{"04", `\d{2}`},
+	{"05", `\d{2}`},
+}
+
+func backupLayout(format string) string {
+	switch format {
+	case "", backupTimestamp:
+		return "20060102_150405"
+	case backupMillis:
+		return "20060102_150405.000"
+	default:
+		return format
+	}
+}
+
+func (l *Logger) backupPath(path string) string {
+	if l.config.BackupFormat == backupSequence {
+		next := 1
+		for _, suffix := range l.backupSuffixes(path) {
+			if n, err := strconv.Atoi(suffix); err == nil && n >= next {
+				next = n + 1
+			}
+		}
+		return fmt.Sprintf("%s.%d", path, next)
+	}
+
+	backupPath := fmt.Sprintf("%s.%s", path, l.now().Format(backupLayout(l.config.BackupFormat)))
+	// * never overwrite an existing backup when rotating twice in the same tick
+	candidate := backupPath
+	for i := 1; ; i++ {
+		if _, err := l.fs().Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", backupPath, i)
+	}
+}
+
+func (l *Logger) backupSuffixes(path string) []string {
+	base := filepath.Base(path)
+	entries, err := l.fs().ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	pattern := l.backupPattern(base)
+	var suffixes []string
+	for _, entry := range entries {
+		if pattern.MatchString(entry.Name()) {
+			suffixes = append(suffixes, strings.TrimPrefix(entry.Name(), base+"."))
+		}
+	}
+	return suffixes
+}
+
+func (l *Logger) backupPattern(base string) *regexp.Regexp {
+	if l.config.DatedFiles {
+		return datedPattern(base)
+	}
+	if l.config.BackupFormat == backupSequence {
+		return regexp.MustCompile(`^` + regexp.QuoteMeta(base) + `\.\d+$`)
+	}
+
+	layout := backupLayout(l.config.BackupFormat)
+	var pattern strings.Builder
+	for len(layout) > 0 {
+		matched := false
+		for _, t := range layoutTokens {
+			if strings.HasPrefix(layout, t.token) {
+				pattern.WriteString(t.pattern)
+				layout = layout[len(t.token):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			pattern.WriteString(regexp.QuoteMeta(layout[:1]))
+			layout = layout[1:]
+		}
+	}
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(base) + `\.` + pattern.String() + `(-\d+)?$`)
+}
+
+// * backups written within the same clock tick share a mod time, fall back to
+// * the suffix so output.log.10 still sorts after output.log.9; suffixes only
+// * compare within one base name, other files' ties keep a fixed order by name
+func olderBackup(a, b backupFile) bool {
+	if !a.modTime.Equal(b.modTime) {
+		return a.modTime.Before(b.modTime)
+	}
+	if a.base != b.base {
+		return a.base < b.base
+	}
+	suffixA, suffixB := filepath.Base(a.path)[len(a.base):], filepath.Base(b.path)[len(b.base):]
+	if len(suffixA) != len(suffixB) {
+		return len(suffixA) < len(suffixB)
+	}
+	return suffixA < suffixB
+}
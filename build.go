@@ -0,0 +1,98 @@
+package goLogger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+type build struct {
+	module   string
+	version  string
+	revision string
+	time     string
+	modified bool
+}
+
+// * read once, the binary doesn't change while it runs
+var currentBuild = sync.OnceValue(func() build {
+	var b build
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	b.module, b.version = info.Main.Path, info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			b.revision = setting.Value
+		case "vcs.time":
+			b.time = setting.Value
+		case "vcs.modified":
+			b.modified = setting.Value == "true"
+		}
+	}
+	return b
+})
+
+// * stamped on every entry with BuildFields, empty values are left out
+var buildFields = sync.OnceValue(func() []Field {
+	b := currentBuild()
+	var fields []Field
+	if b.version != "" {
+		fields = append(fields, Field{Key: "version", Value: b.version})
+	}
+	if b.revision != "" {
+		fields = append(fields, Field{Key: "revision", Value: b.revision})
+	}
+	return fields
+})
+
+// * one NOTICE entry to output.log with the build, e.g. logger.Startup() right after New;
+// * the version is "(devel)" and the revision empty for go run or builds without VCS stamping
+func (l *Logger) Startup(messages ...any) {
+	b := currentBuild()
+	if len(messages) == 0 {
+		name := b.module
+		if name == "" {
+			name = filepath.Base(os.Args[0])
+		}
+		messages = []any{"Starting " + name}
+	}
+
+	l.Mutex.RLock()
+	config := []Field{
+		{Key: "path", Value: l.config.Path},
+		{Key: "type", Value: l.config.Type},
+		{Key: "level", Value: l.config.Level},
+		{Key: "max_size", Value: l.config.MaxSize},
+		{Key: "max_backups", Value: l.config.MaxBackup},
+	}
+	if l.config.MaxAge > 0 {
+		config = append(config, Field{Key: "max_age", Value: l.config.MaxAge.String()})
+	}
+	if l.instance != "" {
+		config = append(config, Field{Key: "instance", Value: l.instance})
+	}
+	if len(l.sinks) > 0 {
+		config = append(config, Field{Key: "sinks", Value: len(l.sinks)})
+	}
+	l.Mutex.RUnlock()
+
+	info := []Field{
+		{Key: "module", Value: b.module},
+		{Key: "version", Value: b.version},
+		{Key: "revision", Value: b.revision},
+		{Key: "vcs_time", Value: b.time},
+		{Key: "modified", Value: b.modified},
+		{Key: "go", Value: runtime.Version()},
+	}
+	fields := []Field{
+		{Key: "build", Value: info},
+		{Key: "config", Value: config},
+		{Key: "pid", Value: os.Getpid()},
+	}
+	l.writeFields(defaultOutputName, logNotice, fields, messages...)
+}
@@ -0,0 +1,41 @@
+package goLogger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const packagePath = "github.com/pardnchiu/go-logger."
+
+// * wrapper packages whose frames are skipped like our own
+var wrapperPaths = []string{packagePath, "github.com/pardnchiu/go-logger/glog."}
+
+// * first frame outside this package, the public method depth varies per call path
+func callerFrame() (runtime.Frame, bool) {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		inside := false
+		for _, path := range wrapperPaths {
+			inside = inside || strings.HasPrefix(frame.Function, path)
+		}
+		inside = inside && !strings.HasSuffix(frame.File, "_test.go")
+		if !inside && frame.File != "" {
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+func callerField() (Field, bool) {
+	frame, ok := callerFrame()
+	if !ok {
+		return Field{}, false
+	}
+	return Field{Key: "caller", Value: fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)}, true
+}
@@ -0,0 +1,21 @@
+package goLogger
+
+import "time"
+
+type Clock interface {
+	Now() time.Time
+}
+
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// * entry timestamps, backup and dated names go through here, deadlines and signatures don't
+func (l *Logger) now() time.Time {
+	if l.config.Clock != nil {
+		return l.config.Clock.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,12 @@
+package goLogger
+
+// * text entries get a "[prefix] " message prefix, json entries a component field
+func (l *Logger) Clone(prefix string) *Child {
+	return &Child{logger: l, component: prefix}
+}
+
+func (c *Child) Clone(prefix string) *Child {
+	child := *c
+	child.component = prefix
+	return &child
+}
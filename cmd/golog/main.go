@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	goLogger "github.com/pardnchiu/go-logger"
+)
+
+const usage = `Usage: golog <command> [flags] [path...]
+
+Commands:
+  tail     Follow new entries of every .log file in a directory
+  search   Print entries of files or directories matching the filters
+  convert  Rewrite entries between text and JSON formats
+  migrate  Convert a log directory in place, e.g. before switching Type
+
+Run "golog <command> -h" for command flags.
+`
+
+var levels = []string{"DEBUG", "TRACE", "INFO", "NOTICE", "WARNING", "ERROR", "FATAL", "CRITICAL"}
+
+var colors = map[string]string{
+	"DEBUG":    "\033[90m",
+	"TRACE":    "\033[90m",
+	"NOTICE":   "\033[36m",
+	"WARNING":  "\033[33m",
+	"ERROR":    "\033[31m",
+	"FATAL":    "\033[1;31m",
+	"CRITICAL": "\033[1;31m",
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "tail":
+		err = runTail(os.Args[2:])
+	case "search":
+		err = runSearch(os.Args[2:])
+	case "convert":
+		err = runConvert(os.Args[2:])
+	case "migrate":
+		err = runMigrate(os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "golog: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "golog: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type fieldFlags []string
+
+func (f *fieldFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fieldFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+type printer struct {
+	format string
+	color  bool
+}
+
+func (p *printer) print(w io.Writer, entry goLogger.Entry) {
+	data := goLogger.EncodeEntry(entry, p.format)
+	if code, ok := colors[entry.Level]; ok && p.color && p.format != "json" {
+		data = append(append([]byte(code), bytes.TrimSuffix(data, []byte("\n"))...), "\033[0m\n"...)
+	}
+	w.Write(data)
+}
+
+func newPrinter(asJSON bool, color string) *printer {
+	p := &printer{format: "text"}
+	if asJSON {
+		p.format = "json"
+	}
+	switch color {
+	case "always":
+		p.color = true
+	case "auto":
+		// * only colorize terminals and respect NO_COLOR
+		info, err := os.Stdout.Stat()
+		p.color = err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("NO_COLOR") == ""
+	}
+	return p
+}
+
+func rank(level string) int {
+	level = strings.ToUpper(level)
+	if level == "WARN" {
+		level = "WARNING"
+	}
+	for i, name := range levels {
+		if name == level {
+			return i
+		}
+	}
+	return -1
+}
+
+type matcher struct {
+	minLevel int
+	from     time.Time
+	to       time.Time
+	contains string
+	fields   []string
+}
+
+func (m *matcher) match(entry goLogger.Entry) bool {
+	if rank(entry.Level) < m.minLevel {
+		return false
+	}
+	if !m.from.IsZero() && entry.Time.Before(m.from) {
+		return false
+	}
+	if !m.to.IsZero() && entry.Time.After(m.to) {
+		return false
+	}
+	if m.contains != "" && !strings.Contains(entry.Message, m.contains) && !containsAny(entry.Data, m.contains) {
+		return false
+	}
+	for _, field := range m.fields {
+		if !hasField(entry, field) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(values []string, text string) bool {
+	for _, value := range values {
+		if strings.Contains(value, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// * text files keep fields as key=value branches in Data
+func hasField(entry goLogger.Entry, pair string) bool {
+	key, value, _ := strings.Cut(pair, "=")
+	for _, field := range entry.Fields {
+		if field.Key == key && fmt.Sprint(field.Value) == value {
+			return true
+		}
+	}
+	for _, data := range entry.Data {
+		if data == pair {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC3339 or 2006-01-02 15:04:05", value)
+}
+
+func newMatcher(level, from, to, contains string, fields []string) (*matcher, error) {
+	m := &matcher{contains: contains, fields: fields}
+	if level != "" {
+		if m.minLevel = rank(level); m.minLevel < 0 {
+			return nil, fmt.Errorf("unknown level %q", level)
+		}
+	}
+	var err error
+	if m.from, err = parseTime(from); err != nil {
+		return nil, err
+	}
+	if m.to, err = parseTime(to); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// * directories expand to live files and backups, oldest first
+func expand(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		type candidate struct {
+			path    string
+			modTime time.Time
+		}
+		var candidates []candidate
+		for _, entry := range entries {
+			name := entry.Name()
+			// * skip the symlink of DatedFiles mode, its target is listed already
+			if !entry.Type().IsRegular() || !isLogFile(name) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			candidates = append(candidates, candidate{filepath.Join(path, name), info.ModTime()})
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].modTime.Before(candidates[j].modTime)
+		})
+		for _, item := range candidates {
+			files = append(files, item.path)
+		}
+	}
+	return files, nil
+}
+
+func isLogFile(name string) bool {
+	return strings.Contains(name, ".log") && !strings.HasPrefix(name, ".") && !strings.HasSuffix(name, ".sha256")
+}
+
+func runSearch(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	level := flags.String("level", "", "minimum level, e.g. WARNING")
+	from := flags.String("from", "", "only entries at or after this time")
+	to := flags.String("to", "", "only entries at or before this time")
+	contains := flags.String("contains", "", "substring of the message or extra messages")
+	asJSON := flags.Bool("json", false, "print entries as JSON")
+	color := flags.String("color", "auto", "colorize levels: auto, always or never")
+	var fields fieldFlags
+	flags.Var(&fields, "field", "key=value that must be present, repeatable")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("search needs at least one file or directory")
+	}
+	m, err := newMatcher(*level, *from, *to, *contains, fields)
+	if err != nil {
+		return err
+	}
+	files, err := expand(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	p := newPrinter(*asJSON, *color)
+	reader := goLogger.NewReader(files...)
+	defer reader.Close()
+	for reader.Next() {
+		if entry := reader.Entry(); m.match(entry) {
+			p.print(os.Stdout, entry)
+		}
+	}
+	return reader.Err()
+}
+
+func runConvert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	to := flags.String("to", "json", "output format: json or text")
+	flags.Parse(args)
+
+	if *to != "json" && *to != "text" {
+		return fmt.Errorf("unknown format %q", *to)
+	}
+
+	var reader *goLogger.Reader
+	if flags.NArg() == 0 {
+		reader = goLogger.NewStreamReader(os.Stdin)
+	} else {
+		files, err := expand(flags.Args())
+		if err != nil {
+			return err
+		}
+		reader = goLogger.NewReader(files...)
+	}
+	defer reader.Close()
+
+	for reader.Next() {
+		os.Stdout.Write(goLogger.EncodeEntry(reader.Entry(), *to))
+	}
+	return reader.Err()
+}
+
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	to := flags.String("to", "json", "target format: json or text")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("migrate needs exactly one directory")
+	}
+	return goLogger.Migrate(flags.Arg(0), *to)
+}
+
+func runTail(args []string) error {
+	flags := flag.NewFlagSet("tail", flag.ExitOnError)
+	level := flags.String("level", "", "minimum level, e.g. WARNING")
+	asJSON := flags.Bool("json", false, "print entries as JSON")
+	color := flags.String("color", "auto", "colorize levels: auto, always or never")
+	interval := flags.Duration("interval", 500*time.Millisecond, "poll interval")
+	flags.Parse(args)
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+	m, err := newMatcher(*level, "", "", "", nil)
+	if err != nil {
+		return err
+	}
+
+	p := newPrinter(*asJSON, *color)
+	offsets := make(map[string]int64)
+	first := true
+	for {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.log"))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			offset, known := offsets[path]
+			switch {
+			case first:
+				// * start at the end like tail -f
+				offsets[path] = info.Size()
+				continue
+			case !known || info.Size() < offset:
+				// * new or rotated file, read from the start
+				offset = 0
+			}
+			offsets[path] = readFrom(path, offset, m, p)
+		}
+		first = false
+		time.Sleep(*interval)
+	}
+}
+
+// * only complete lines are decoded, a partial write is picked up next poll
+func readFrom(path string, offset int64, m *matcher, p *printer) int64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return offset
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return offset
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return offset
+	}
+
+	reader := goLogger.NewStreamReader(bytes.NewReader(data[:end+1]))
+	for reader.Next() {
+		if entry := reader.Entry(); m.match(entry) {
+			p.print(os.Stdout, entry)
+		}
+	}
+	if err := reader.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "golog: %s: %v\n", path, err)
+	}
+	return offset + int64(end) + 1
+}
@@ -0,0 +1,50 @@
+package goLogger
+
+import (
+	"bytes"
+	"io"
+)
+
+var levelColor = map[string]string{
+	logDebug:    "\033[90m",
+	logTrace:    "\033[90m",
+	logNotice:   "\033[36m",
+	logWarning:  "\033[33m",
+	logError:    "\033[31m",
+	logFatal:    "\033[1;31m",
+	logCritical: "\033[1;31m",
+}
+
+type colorWriter struct {
+	writer io.Writer
+}
+
+// * each line is wrapped on its own so pagers and line limits keep working
+func (w *colorWriter) Write(p []byte) (int, error) {
+	match := textHeader.FindSubmatch(bytes.SplitN(p, []byte("\n"), 2)[0])
+	if match == nil {
+		return w.writer.Write(p)
+	}
+	code, ok := levelColor[string(match[2])]
+	if !ok {
+		return w.writer.Write(p)
+	}
+
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		content, hasNewline := bytes.CutSuffix(line, []byte("\n"))
+		buf.WriteString(code)
+		buf.Write(content)
+		buf.WriteString("\033[0m")
+		if hasNewline {
+			buf.WriteByte('\n')
+		}
+	}
+	if _, err := w.writer.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
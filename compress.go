@@ -0,0 +1,110 @@
+package goLogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	compressionMutex sync.RWMutex
+	// * keyed by Content-Encoding, zstd needs a third-party encoder and is registered by the caller
+	compressions = map[string]func(io.Writer) (io.WriteCloser, error){
+		"gzip":    func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+		"deflate": func(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil },
+	}
+	// * matched by leading magic bytes when reading backups, later registrations win
+	decompressions = []decompression{
+		{magic: gzipMagic, newReader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }},
+	}
+)
+
+type decompression struct {
+	magic     []byte
+	newReader func(io.Reader) (io.ReadCloser, error)
+}
+
+// * e.g. RegisterCompression("zstd", func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) })
+func RegisterCompression(name string, newWriter func(io.Writer) (io.WriteCloser, error)) {
+	compressionMutex.Lock()
+	defer compressionMutex.Unlock()
+
+	compressions[name] = newWriter
+}
+
+// * lets Reader, Query and golog open backups compressed by other tools, e.g.
+// * RegisterDecompression([]byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.ReadCloser, error) {
+// *   d, err := zstd.NewReader(r)
+// *   if err != nil {
+// *     return nil, err
+// *   }
+// *   return d.IOReadCloser(), nil
+// * })
+func RegisterDecompression(magic []byte, newReader func(io.Reader) (io.ReadCloser, error)) {
+	compressionMutex.Lock()
+	defer compressionMutex.Unlock()
+
+	decompressions = append([]decompression{{magic: bytes.Clone(magic), newReader: newReader}}, decompressions...)
+}
+
+// * the reader for data starting with a registered magic, nil for plain data
+func findDecompression(head []byte) func(io.Reader) (io.ReadCloser, error) {
+	compressionMutex.RLock()
+	defer compressionMutex.RUnlock()
+
+	for _, d := range decompressions {
+		if len(d.magic) > 0 && bytes.HasPrefix(head, d.magic) {
+			return d.newReader
+		}
+	}
+	return nil
+}
+
+func maxMagicSize() int {
+	compressionMutex.RLock()
+	defer compressionMutex.RUnlock()
+
+	size := 0
+	for _, d := range decompressions {
+		size = max(size, len(d.magic))
+	}
+	return size
+}
+
+func checkCompression(name string) error {
+	if name == "" {
+		return nil
+	}
+	compressionMutex.RLock()
+	defer compressionMutex.RUnlock()
+
+	if compressions[name] == nil {
+		return fmt.Errorf("Compression %q is not supported, use gzip, deflate or RegisterCompression", name)
+	}
+	return nil
+}
+
+func compress(name string, data []byte) ([]byte, error) {
+	compressionMutex.RLock()
+	newWriter := compressions[name]
+	compressionMutex.RUnlock()
+	if newWriter == nil {
+		return nil, fmt.Errorf("compression %q is not supported", name)
+	}
+
+	var buf bytes.Buffer
+	writer, err := newWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,90 @@
+package goLogger
+
+import "fmt"
+
+// * a copy, changing its slices or maps doesn't reach the running logger
+func (l *Logger) Config() Log {
+	l.Mutex.RLock()
+	defer l.Mutex.RUnlock()
+
+	return copyConfig(l.config)
+}
+
+func (l *Logger) SetMaxSize(size int64) error {
+	if size <= 0 {
+		return fmt.Errorf("Failed to set max size: must be positive, got %d", size)
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.config.MaxSize = size
+	l.writeMeta()
+	return nil
+}
+
+func (l *Logger) SetMaxBackup(count int) error {
+	if count <= 0 {
+		return fmt.Errorf("Failed to set max backup: must be positive, got %d", count)
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.config.MaxBackup = count
+	l.writeMeta()
+	return nil
+}
+
+func (l *Logger) SetType(logType string) error {
+	if logType != "json" && logType != "text" {
+		return fmt.Errorf("Failed to set type: unsupported %q", logType)
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.config.Type = logType
+	l.writeMeta()
+	return nil
+}
+
+func (l *Logger) SetMaxEntrySize(size int) error {
+	if size < 0 {
+		return fmt.Errorf("Failed to set max entry size: must not be negative, got %d", size)
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.config.MaxEntrySize = size
+	l.writeMeta()
+	return nil
+}
+
+func (l *Logger) SetVerbosity(level int) error {
+	if level < 0 {
+		return fmt.Errorf("Failed to set verbosity: must not be negative, got %d", level)
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.config.Verbosity = level
+	return nil
+}
+
+func (l *Logger) SetLevel(level string) error {
+	level, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.config.Level = level
+	// * explicit level change ends any running elevation
+	l.elevation = nil
+	return nil
+}
@@ -0,0 +1,93 @@
+package goLogger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const crashLayout = "20060102_150405.000000"
+
+// * called under lock after a FATAL entry, the dump includes every goroutine waiting on it
+func (l *Logger) writeCrash(at time.Time, level string, fields []Field, messages []any) {
+	texts := truncate(toStrings(messages), l.config.MaxEntrySize)
+	entry := Entry{Time: at, Level: level, Message: texts[0], Data: texts[1:], Fields: cloneFields(fields)}
+	l.redact(&entry)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "crash report %s\npid: %d\ngo: %s\n\n", at.Format(time.RFC3339Nano), os.Getpid(), runtime.Version())
+	buf.WriteString("== entry ==\n")
+	buf.Write(l.encode(&entry, "text"))
+	buf.WriteString("\n== goroutines ==\n")
+	buf.Write(goroutineDump())
+	recent := l.recentEntries()
+	fmt.Fprintf(&buf, "\n== recent entries (%d) ==\n", len(recent))
+	for i := range recent {
+		buf.Write(l.encode(&recent[i], "text"))
+	}
+
+	name := "crash-" + at.Format(crashLayout)
+	if l.instance != "" {
+		name += "." + l.instance
+	}
+	// * not .log, Query and Reader must not take the report for a log file;
+	// * a second crash in the same tick gets a -1, -2... suffix like backups
+	candidate := name
+	for i := 1; ; i++ {
+		err := l.saveCrash(filepath.Join(l.config.Path, candidate+".txt"), buf.Bytes())
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			l.internalError(fmt.Errorf("Failed to write crash report: %w", err))
+			return
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// * synced right away, the process is likely about to exit
+func (l *Logger) saveCrash(path string, data []byte) error {
+	file, err := l.fs().OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, fileMode(l.config))
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return l.applyOwnership(path)
+}
+
+// * reads the ring buffer under the lock already held, oldest first
+func (l *Logger) recentEntries() []Entry {
+	total := len(l.recent)
+	entries := make([]Entry, 0, total)
+	for i := range total {
+		entries = append(entries, l.recent[(l.recentNext+i)%total])
+	}
+	return entries
+}
+
+func goroutineDump() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 64<<20 {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,167 @@
+package goLogger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxDatadogBatch = 1000
+
+// 將日誌批次送至 Datadog Logs intake，不需設定 agent 讀取檔案
+type DatadogSink struct {
+	APIKey      string          // Datadog API 金鑰
+	Site        string          // Datadog 站點，如 "datadoghq.eu"，預設 "datadoghq.com"
+	Endpoint    string          // 自訂 intake 網址，設定後忽略 Site
+	Service     string          // service 標籤
+	Source      string          // ddsource 標籤，預設 "go"
+	Tags        []string        // 附加標籤，如 "env:prod"
+	Hostname    string          // 主機名稱，預設 os.Hostname()
+	MinLevel    string          // 送出的最低層級，預設全部
+	Compress    bool            // 以 gzip 壓縮請求內容，同 Compression "gzip"
+	Compression string          // 請求內容的壓縮方式，"gzip"、"deflate" 或以 RegisterCompression 註冊的名稱
+	BatchSize   int             // 單次請求最多筆數，預設 100，上限 1000
+	Interval    time.Duration   // 批次送出的間隔，預設 5 秒
+	Retry       *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker     *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue       *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	Client      *http.Client    // 預設逾時 30 秒的 http.Client
+	once        sync.Once
+	batcher     *batcher
+	minLevel    string
+	initErr     error
+}
+
+func (d *DatadogSink) init() error {
+	d.once.Do(func() {
+		if d.APIKey == "" {
+			d.initErr = fmt.Errorf("APIKey is required")
+			return
+		}
+		if d.MinLevel != "" {
+			if d.minLevel, d.initErr = parseLevel(d.MinLevel); d.initErr != nil {
+				return
+			}
+		}
+		if d.initErr = checkCompression(d.Compression); d.initErr != nil {
+			return
+		}
+		if d.Hostname == "" {
+			d.Hostname, _ = os.Hostname()
+		}
+		d.batcher, d.initErr = newBatcher(min(d.BatchSize, maxDatadogBatch), d.Interval, d.Retry, d.Breaker, d.Queue, d.send)
+	})
+	return d.initErr
+}
+
+func (d *DatadogSink) reportErrors(report func(error)) {
+	if d.init() == nil {
+		d.batcher.setReport(report)
+	}
+}
+
+func (d *DatadogSink) Write(entry Entry) error {
+	if err := d.init(); err != nil {
+		return err
+	}
+	if d.minLevel != "" && levelRank[entry.Level] < levelRank[d.minLevel] {
+		return nil
+	}
+	d.batcher.add(entry)
+	return nil
+}
+
+func (d *DatadogSink) Flush() {
+	if d.init() == nil {
+		d.batcher.Flush()
+	}
+}
+
+func (d *DatadogSink) Close() {
+	if d.init() == nil {
+		d.batcher.Close()
+	}
+}
+
+func (d *DatadogSink) undelivered() int {
+	if d.init() != nil {
+		return 0
+	}
+	return d.batcher.undelivered()
+}
+
+func (d *DatadogSink) queued() int {
+	if d.init() != nil {
+		return 0
+	}
+	return d.batcher.queued()
+}
+
+func (d *DatadogSink) encoding() string {
+	if d.Compression == "" && d.Compress {
+		return "gzip"
+	}
+	return d.Compression
+}
+
+func (d *DatadogSink) send(entries []Entry) error {
+	source := d.Source
+	if source == "" {
+		source = "go"
+	}
+	logs := make([]map[string]any, len(entries))
+	for i, entry := range entries {
+		attrs := entryAttributes(entry)
+		delete(attrs, "msg")
+		delete(attrs, "time")
+		attrs["message"] = entry.Message
+		attrs["status"] = datadogStatus[entry.Level]
+		attrs["timestamp"] = entry.Time.UnixMilli()
+		attrs["ddsource"] = source
+		attrs["hostname"] = d.Hostname
+		if d.Service != "" {
+			attrs["service"] = d.Service
+		}
+		if len(d.Tags) > 0 {
+			attrs["ddtags"] = strings.Join(d.Tags, ",")
+		}
+		logs[i] = attrs
+	}
+	body, err := json.Marshal(logs)
+	if err != nil {
+		return fmt.Errorf("Failed to encode Datadog batch: %w", err)
+	}
+
+	target := d.Endpoint
+	if target == "" {
+		site := d.Site
+		if site == "" {
+			site = "datadoghq.com"
+		}
+		target = "https://http-intake.logs." + site + "/api/v2/logs"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	if err := postBatch(ctx, d.Client, target, body, d.encoding(), map[string]string{"DD-API-KEY": d.APIKey}); err != nil {
+		return fmt.Errorf("Failed to send to Datadog: %w", err)
+	}
+	return nil
+}
+
+// * Datadog status names, it has no trace or fatal
+var datadogStatus = map[string]string{
+	logDebug:    "debug",
+	logTrace:    "debug",
+	logInfo:     "info",
+	logNotice:   "notice",
+	logWarning:  "warning",
+	logError:    "error",
+	logFatal:    "critical",
+	logCritical: "critical",
+}
@@ -0,0 +1,99 @@
+package goLogger
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const datedLayout = "2006-01-02"
+
+func datedPrefix(filename string, now time.Time) string {
+	return strings.TrimSuffix(filename, ".log") + "-" + now.Format(datedLayout)
+}
+
+// * output.log -> output-2025-06-01.log, then output-2025-06-01.1.log once full
+func (l *Logger) datedName(filename string, now time.Time) string {
+	prefix := datedPrefix(l.diskName(filename), now)
+	candidate := prefix + ".log"
+	for i := 1; ; i++ {
+		info, err := l.fs().Stat(filepath.Join(l.config.Path, candidate))
+		if err != nil || (candidate != l.dated[filename] && info.Size() <= l.maxSize(filename)) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.%d.log", prefix, i)
+	}
+}
+
+func (l *Logger) openDated(filename string, mode os.FileMode) (File, error) {
+	name := l.datedName(filename, l.now())
+
+	file, err := l.fs().OpenFile(filepath.Join(l.config.Path, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, mode)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s: %w", name, err)
+	}
+	if err := l.applyOwnership(filepath.Join(l.config.Path, name)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	l.dated[filename] = name
+
+	if err := l.linkLatest(filename, name); err != nil {
+		l.internalError(fmt.Errorf("Failed to link %s: %w", filename, err))
+	}
+	return file, nil
+}
+
+// * output.log always points at the active dated file, so tailing it keeps working
+func (l *Logger) linkLatest(filename, name string) error {
+	link := filepath.Join(l.config.Path, l.diskName(filename))
+	if info, err := l.fs().Lstat(link); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		// * keep a regular file left from non-dated mode
+		if err := l.fs().Rename(link, link+"."+l.now().Format("20060102_150405")); err != nil {
+			return err
+		}
+	}
+
+	// * tenant files live in a subdirectory, the link target is relative to it
+	tmp := filepath.Join(l.config.Path, filepath.Dir(filename), "."+filepath.Base(l.diskName(filename))+".link")
+	l.fs().Remove(tmp)
+	if err := l.fs().Symlink(filepath.Base(name), tmp); err != nil {
+		return err
+	}
+	return l.fs().Rename(tmp, link)
+}
+
+func (l *Logger) livePath(filename string) string {
+	if l.config.DatedFiles {
+		if name, isExist := l.dated[filename]; isExist {
+			return filepath.Join(l.config.Path, name)
+		}
+	}
+	return filepath.Join(l.config.Path, l.diskName(filename))
+}
+
+func datedPattern(base string) *regexp.Regexp {
+	name := regexp.QuoteMeta(strings.TrimSuffix(base, ".log"))
+	return regexp.MustCompile(`^` + name + `-\d{4}-\d{2}-\d{2}(\.\d+)?\.log$`)
+}
+
+func (l *Logger) checkDate(filename string) {
+	name, isExist := l.dated[filename]
+	if !isExist || strings.HasPrefix(name, datedPrefix(l.diskName(filename), l.now())+".") {
+		return
+	}
+	if err := l.rotateFile(filename); err != nil {
+		l.internalError(err)
+	}
+}
+
+func (l *Logger) handler(filename string) *log.Logger {
+	if handler, isExist := l.handlers[filename]; isExist {
+		return handler
+	}
+	return l.OutputHandler
+}
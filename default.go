@@ -0,0 +1,113 @@
+package goLogger
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type defaultHolder struct {
+	logger Interface
+}
+
+var defaultLogger atomic.Pointer[defaultHolder]
+
+// * package functions delegate here, nil restores the stderr fallback
+func SetDefault(logger Interface) {
+	if logger == nil {
+		defaultLogger.Store(nil)
+		return
+	}
+	defaultLogger.Store(&defaultHolder{logger: logger})
+}
+
+func Default() Interface {
+	if holder := defaultLogger.Load(); holder != nil {
+		return holder.logger
+	}
+	return stderrFallback
+}
+
+// * text to stderr until SetDefault, so early entries aren't lost and no files are created
+type stderrLogger struct {
+	mutex sync.Mutex
+}
+
+var stderrFallback = &stderrLogger{}
+
+func (s *stderrLogger) write(level string, messages []any) {
+	if len(messages) == 0 {
+		return
+	}
+	texts := toStrings(resolveLazy(messages))
+	data := encodeText(&Entry{Time: time.Now(), Level: level, Message: texts[0], Data: texts[1:]})
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	os.Stderr.Write(data)
+}
+
+func (s *stderrLogger) Debug(messages ...any)  { s.write(logDebug, messages) }
+func (s *stderrLogger) Trace(messages ...any)  { s.write(logTrace, messages) }
+func (s *stderrLogger) Info(messages ...any)   { s.write(logInfo, messages) }
+func (s *stderrLogger) Notice(messages ...any) { s.write(logNotice, messages) }
+func (s *stderrLogger) Warn(messages ...any)   { s.write(logWarning, messages) }
+
+func (s *stderrLogger) WarnError(err error, messages ...any) error {
+	return s.writeError(logWarning, err, messages)
+}
+
+func (s *stderrLogger) Error(err error, messages ...any) error {
+	return s.writeError(logError, err, messages)
+}
+
+func (s *stderrLogger) Fatal(err error, messages ...any) error {
+	return s.writeError(logFatal, err, messages)
+}
+
+func (s *stderrLogger) Critical(err error, messages ...any) error {
+	return s.writeError(logCritical, err, messages)
+}
+
+func (s *stderrLogger) writeError(level string, err error, messages []any) error {
+	messages = resolveLazy(messages)
+	s.write(level, appendError(messages, err))
+	return composeError(err, messages)
+}
+
+func Debug(messages ...any) {
+	Default().Debug(messages...)
+}
+
+func Trace(messages ...any) {
+	Default().Trace(messages...)
+}
+
+func Info(messages ...any) {
+	Default().Info(messages...)
+}
+
+func Notice(messages ...any) {
+	Default().Notice(messages...)
+}
+
+func Warn(messages ...any) {
+	Default().Warn(messages...)
+}
+
+func WarnError(err error, messages ...any) error {
+	return Default().WarnError(err, messages...)
+}
+
+func Error(err error, messages ...any) error {
+	return Default().Error(err, messages...)
+}
+
+func Fatal(err error, messages ...any) error {
+	return Default().Fatal(err, messages...)
+}
+
+func Critical(err error, messages ...any) error {
+	return Default().Critical(err, messages...)
+}
@@ -0,0 +1,201 @@
+package goLogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const textTimeLayout = "2006/01/02 15:04:05.000000"
+
+// * TimePrecision values, the fraction is zero padded so every entry has the same width
+var timePrecisions = map[string]struct {
+	unit time.Duration
+	text string
+	json string
+}{
+	"second":      {time.Second, "2006/01/02 15:04:05", "2006-01-02T15:04:05Z07:00"},
+	"millisecond": {time.Millisecond, "2006/01/02 15:04:05.000", "2006-01-02T15:04:05.000Z07:00"},
+	"microsecond": {time.Microsecond, "2006/01/02 15:04:05.000000", "2006-01-02T15:04:05.000000Z07:00"},
+	"nanosecond":  {time.Nanosecond, "2006/01/02 15:04:05.000000000", "2006-01-02T15:04:05.000000000Z07:00"},
+}
+
+var slogLevel = map[string]slog.Level{
+	logDebug:    slog.LevelDebug,
+	logTrace:    slog.LevelInfo,
+	logInfo:     slog.LevelInfo,
+	logNotice:   slog.LevelInfo,
+	logWarning:  slog.LevelWarn,
+	logError:    slog.LevelError,
+	logFatal:    slog.LevelError,
+	logCritical: slog.LevelError,
+}
+
+// * replace values json can't marshal with a placeholder instead of losing the entry
+func checkFields(entry *Entry) error {
+	return errors.Join(checkFieldList(entry.Fields, "")...)
+}
+
+func checkFieldList(fields []Field, prefix string) []error {
+	var errs []error
+	for i, field := range fields {
+		switch value := field.Value.(type) {
+		case nil, string, bool, causeList, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Time, time.Duration:
+			continue
+		case []Field:
+			// * groups may be shared with a Child, check a copy
+			group := append([]Field(nil), value...)
+			errs = append(errs, checkFieldList(group, prefix+field.Key+".")...)
+			fields[i].Value = group
+			continue
+		}
+		if _, err := json.Marshal(field.Value); err != nil {
+			fields[i].Value = fmt.Sprintf("!ERROR: %v", err)
+			errs = append(errs, fmt.Errorf("field %q: %w", prefix+field.Key, err))
+		}
+	}
+	return errs
+}
+
+// * []Field values become nested objects
+func fieldAttr(field Field) slog.Attr {
+	group, isGroup := field.Value.([]Field)
+	if !isGroup {
+		return slog.Any(field.Key, field.Value)
+	}
+	attrs := make([]slog.Attr, len(group))
+	for i, nested := range group {
+		attrs[i] = fieldAttr(nested)
+	}
+	return slog.Attr{Key: field.Key, Value: slog.GroupValue(attrs...)}
+}
+
+// * called under lock, in the format and precision of the files
+func (l *Logger) encode(entry *Entry, format string) []byte {
+	if format == "json" {
+		return encodeJSONPrecision(entry, l.config.TimePrecision)
+	}
+	return encodeTextPrecision(entry, l.config.TimePrecision)
+}
+
+// * slog's default time, milliseconds
+func encodeJSON(entry *Entry) []byte {
+	return encodeJSONPrecision(entry, "")
+}
+
+func encodeJSONPrecision(entry *Entry, precision string) []byte {
+	var buf bytes.Buffer
+	options := &slog.HandlerOptions{
+		Level: slog.LevelDebug, // 確保 DEBUG 層級會被輸出
+	}
+	if layout := timePrecisions[precision].json; layout != "" {
+		options.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+				return slog.String(slog.TimeKey, a.Value.Time().Format(layout))
+			}
+			return a
+		}
+	}
+	handler := slog.NewJSONHandler(&buf, options)
+
+	record := slog.NewRecord(entry.Time, slogLevel[entry.Level], entry.Message, 0)
+	for i, data := range entry.Data {
+		record.AddAttrs(slog.String(fmt.Sprintf("msg%d", i+1), data))
+	}
+	switch entry.Level {
+	case logTrace, logNotice, logFatal, logCritical:
+		// * slog has no such levels, keep the original name as attribute
+		record.AddAttrs(slog.String("level", entry.Level))
+	}
+	for _, field := range entry.Fields {
+		record.AddAttrs(fieldAttr(field))
+	}
+
+	handler.Handle(context.Background(), record)
+	return buf.Bytes()
+}
+
+func encodeText(entry *Entry) []byte {
+	return encodeTextPrecision(entry, "")
+}
+
+func encodeTextPrecision(entry *Entry, precision string) []byte {
+	var buf bytes.Buffer
+	layout := textTimeLayout
+	if p, ok := timePrecisions[precision]; ok {
+		layout = p.text
+	}
+	timestamp := entry.Time.Format(layout)
+
+	prefix := ""
+	if entry.Level != logInfo {
+		prefix = fmt.Sprintf("[%s] ", entry.Level)
+	}
+	// * escape control characters so input can't forge extra lines
+	fmt.Fprintf(&buf, "%s %s%s\n", timestamp, prefix, sanitize(entry.Message))
+
+	branches := make([]textBranchNode, 0, len(entry.Data)+len(entry.Fields))
+	for _, data := range entry.Data {
+		branches = append(branches, textBranchNode{text: sanitize(data)})
+	}
+	branches = append(branches, fieldBranches(entry.Fields)...)
+
+	writeBranches(&buf, timestamp, "", branches)
+	return buf.Bytes()
+}
+
+type textBranchNode struct {
+	text     string
+	children []textBranchNode
+}
+
+func fieldBranches(fields []Field) []textBranchNode {
+	branches := make([]textBranchNode, 0, len(fields))
+	for _, field := range fields {
+		if group, isGroup := field.Value.([]Field); isGroup {
+			branches = append(branches, textBranchNode{text: sanitize(field.Key), children: fieldBranches(group)})
+			continue
+		}
+		if causes, isCauses := field.Value.(causeList); isCauses {
+			children := make([]textBranchNode, len(causes))
+			for i, cause := range causes {
+				children[i] = textBranchNode{text: sanitize(cause)}
+			}
+			branches = append(branches, textBranchNode{text: sanitize(field.Key), children: children})
+			continue
+		}
+		branches = append(branches, textBranchNode{text: sanitize(fmt.Sprintf("%s=%v", field.Key, field.Value))})
+	}
+	return branches
+}
+
+// * groups open with ├─┬ and indent their members, npm ls style
+func writeBranches(buf *bytes.Buffer, timestamp string, indent string, branches []textBranchNode) {
+	for i, branch := range branches {
+		connector, next := "├─", "│   "
+		if i == len(branches)-1 {
+			connector, next = "└─", "    "
+		}
+		if len(branch.children) > 0 {
+			connector += "┬"
+		} else {
+			connector += "─"
+		}
+		fmt.Fprintf(buf, "%s %s%s %s\n", timestamp, indent, connector, branch.text)
+		writeBranches(buf, timestamp, indent+next, branch.children)
+	}
+}
+
+// * encode an entry the way the logger writes it, for tools converting between formats
+func EncodeEntry(entry Entry, logType string) []byte {
+	if logType == "json" {
+		entry.Fields = append([]Field(nil), entry.Fields...)
+		checkFields(&entry)
+		return encodeJSON(&entry)
+	}
+	return encodeText(&entry)
+}
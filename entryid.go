@@ -0,0 +1,15 @@
+package goLogger
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// * random UUID (version 4), the same value reaches the files and every sink
+func newEntryID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
@@ -0,0 +1,100 @@
+package goLogger
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const envPrefix = "GOLOGGER_"
+
+// * GOLOGGER_<JSON TAG> overrides the programmatic config, e.g. GOLOGGER_LEVEL=DEBUG
+func applyEnv(config *Log) error {
+	value := reflect.ValueOf(config).Elem()
+	kind := value.Type()
+	for i := 0; i < kind.NumField(); i++ {
+		key, _, _ := strings.Cut(kind.Field(i).Tag.Get("json"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		name := envPrefix + strings.ToUpper(key)
+		text, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setEnvField(value.Field(i), strings.TrimSpace(text)); err != nil {
+			return fmt.Errorf("Failed to parse %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setEnvField(field reflect.Value, text string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+	if field.Type() == reflect.TypeOf(os.FileMode(0)) {
+		mode, err := strconv.ParseUint(text, 8, 32)
+		if err != nil {
+			return err
+		}
+		field.SetUint(mode)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int64:
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(value)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("not supported, use a config file")
+		}
+		var items []string
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		// * key=value pairs, e.g. GOLOGGER_LEVELS=http=DEBUG,db=WARN
+		if field.Type() != reflect.TypeOf(map[string]string(nil)) {
+			return fmt.Errorf("not supported, use a config file")
+		}
+		items := make(map[string]string)
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("expected key=value, got %q", item)
+			}
+			items[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("not supported, use a config file")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package goLogger
+
+import (
+	"errors"
+	"strings"
+)
+
+// * reads like the entry and unwraps to its causes, errors.Join when there are several
+type composedError struct {
+	message string
+	cause   error
+}
+
+func (e *composedError) Error() string {
+	return e.message
+}
+
+func (e *composedError) Unwrap() error {
+	return e.cause
+}
+
+// * err goes last as its own value, so error messages stay inspectable
+func appendError(messages []any, err error) []any {
+	if err == nil {
+		return messages
+	}
+	return append(messages[:len(messages):len(messages)], err)
+}
+
+func messageErrors(messages []any) []error {
+	var errs []error
+	for _, msg := range messages {
+		if err, isError := msg.(error); isError && err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func composeError(err error, messages []any) error {
+	messages = appendError(messages, err)
+	text := strings.Join(toStrings(messages), " ")
+
+	causes := messageErrors(messages)
+	switch len(causes) {
+	case 0:
+		return errors.New(text)
+	case 1:
+		return &composedError{message: text, cause: causes[0]}
+	default:
+		return &composedError{message: text, cause: errors.Join(causes...)}
+	}
+}
+
+// * unwrapped causes, a list in json and sub-branches in text
+type causeList []string
+
+const maxCauses = 32
+
+// * depth first through errors.Unwrap and Unwrap() []error, outermost error excluded
+func errorCauses(err error) causeList {
+	var causes causeList
+	var walk func(err error)
+	walk = func(err error) {
+		var children []error
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() []error }:
+			children = wrapped.Unwrap()
+		case interface{ Unwrap() error }:
+			children = []error{wrapped.Unwrap()}
+		}
+		for _, child := range children {
+			if child == nil || len(causes) >= maxCauses {
+				continue
+			}
+			causes = append(causes, child.Error())
+			walk(child)
+		}
+	}
+	walk(err)
+	return causes
+}
+
+func causeFields(err error) []Field {
+	if err == nil {
+		return nil
+	}
+	causes := errorCauses(err)
+	if len(causes) == 0 {
+		return nil
+	}
+	return []Field{{Key: "causes", Value: causes}}
+}
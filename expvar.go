@@ -0,0 +1,54 @@
+package goLogger
+
+import (
+	"expvar"
+	"strings"
+	"sync"
+)
+
+// * expvar can't unpublish, so names resolve the latest logger for the prefix
+var expvarLoggers sync.Map
+
+func (l *Logger) publishExpvar(prefix string) {
+	_, isExist := expvarLoggers.Swap(prefix, l)
+	if isExist {
+		return
+	}
+
+	stats := func() (Stats, bool) {
+		value, _ := expvarLoggers.Load(prefix)
+		logger, ok := value.(*Logger)
+		if !ok {
+			return Stats{}, false
+		}
+		return logger.Stats(), true
+	}
+	publish := func(name string, read func(Stats) any) {
+		if expvar.Get(name) != nil {
+			return
+		}
+		expvar.Publish(name, expvar.Func(func() any {
+			s, ok := stats()
+			if !ok {
+				return nil
+			}
+			return read(s)
+		}))
+	}
+
+	for _, filename := range l.fileNames() {
+		publish(prefix+"."+strings.TrimSuffix(filename, ".log")+".bytes", func(s Stats) any {
+			return s.Bytes[filename]
+		})
+	}
+	for level := range levelRank {
+		publish(prefix+"."+strings.ToLower(level)+".count", func(s Stats) any {
+			return s.Entries[level]
+		})
+	}
+	publish(prefix+".suppressed", func(s Stats) any { return s.Suppressed })
+	publish(prefix+".dropped", func(s Stats) any { return s.Dropped })
+	publish(prefix+".failed", func(s Stats) any { return s.Failed })
+	publish(prefix+".queue_depth", func(s Stats) any { return s.QueueDepth })
+	publish(prefix+".last_rotation", func(s Stats) any { return s.LastRotation })
+}
@@ -0,0 +1,93 @@
+package goLogger
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// 將日誌以獨立的格式與最低層級寫入 io.Writer，如標準輸出只顯示 INFO 以上的 text
+type WriterSink struct {
+	Writer    io.Writer // 輸出目的地，如 os.Stdout
+	Format    string    // "json" 或 "text"，預設與日誌檔案相同
+	MinLevel  string    // 輸出的最低層級，預設全部
+	Color     bool      // 依層級上色（僅 text 格式）
+	LineLimit int       // 單行長度上限（位元組），預設 0 不限制
+	once      sync.Once
+	writer    io.Writer
+	minLevel  string
+	initErr   error
+}
+
+// * sinks writing encoded lines synchronously; line returns the entry in a
+// * format, encoded once per entry and shared with the files and other sinks
+type lineSink interface {
+	writeLine(entry Entry, line func(format string) []byte) error
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case "", "json", "text":
+		return nil
+	}
+	return fmt.Errorf("Format %q is not supported, use json or text", format)
+}
+
+func encodeLine(entry *Entry, format string) []byte {
+	if format == "json" {
+		return encodeJSON(entry)
+	}
+	return encodeText(entry)
+}
+
+func (w *WriterSink) init() error {
+	w.once.Do(func() {
+		if w.Writer == nil {
+			w.initErr = fmt.Errorf("Writer is required")
+			return
+		}
+		if w.initErr = checkFormat(w.Format); w.initErr != nil {
+			return
+		}
+		if w.MinLevel != "" {
+			if w.minLevel, w.initErr = parseLevel(w.MinLevel); w.initErr != nil {
+				return
+			}
+		}
+		w.writer = w.Writer
+		if w.Color && w.Format != "json" {
+			w.writer = &colorWriter{writer: w.writer}
+		}
+		w.writer = limitLines(w.writer, w.LineLimit)
+	})
+	return w.initErr
+}
+
+// * outside a logger an empty Format means text
+func (w *WriterSink) Write(entry Entry) error {
+	return w.writeLine(entry, func(format string) []byte {
+		return encodeLine(&entry, format)
+	})
+}
+
+func (w *WriterSink) writeLine(entry Entry, line func(format string) []byte) error {
+	if err := w.init(); err != nil {
+		return err
+	}
+	if w.minLevel != "" && levelRank[entry.Level] < levelRank[w.minLevel] {
+		return nil
+	}
+	_, err := w.writer.Write(line(w.Format))
+	return err
+}
+
+func (w *WriterSink) Flush() {
+	if w.init() != nil {
+		return
+	}
+	if syncer, ok := w.Writer.(interface{ Sync() error }); ok {
+		syncer.Sync()
+	}
+}
+
+func (w *WriterSink) Close() {}
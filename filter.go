@@ -0,0 +1,65 @@
+package goLogger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type filter struct {
+	match     *regexp.Regexp
+	prefix    string
+	component string
+	minLevel  string
+}
+
+func compileFilters(rules []FilterRule) ([]filter, error) {
+	filters := make([]filter, 0, len(rules))
+	for i, rule := range rules {
+		f := filter{
+			prefix:    rule.Prefix,
+			component: rule.Component,
+		}
+		if rule.Match != "" {
+			match, err := regexp.Compile(rule.Match)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to compile filter %d: %w", i, err)
+			}
+			f.match = match
+		}
+		if rule.MinLevel != "" {
+			level, err := parseLevel(rule.MinLevel)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to compile filter %d: %w", i, err)
+			}
+			f.minLevel = level
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+func (f *filter) drops(entry *Entry) bool {
+	if f.match != nil && !f.match.MatchString(entry.Message) {
+		return false
+	}
+	if f.prefix != "" && !strings.HasPrefix(entry.Message, f.prefix) {
+		return false
+	}
+	if f.component != "" && entryComponent(entry) != f.component {
+		return false
+	}
+	if f.minLevel == "" {
+		return true
+	}
+	return levelRank[entry.Level] < levelRank[f.minLevel]
+}
+
+func entryComponent(entry *Entry) string {
+	for _, field := range entry.Fields {
+		if field.Key == "component" || field.Key == "logger" {
+			return fmt.Sprintf("%v", field.Value)
+		}
+	}
+	return ""
+}
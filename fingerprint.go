@@ -0,0 +1,41 @@
+package goLogger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// * variable parts replaced so "user 42 not found" and "user 7 not found" group together
+var fingerprintPatterns = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), "<str>"},
+	{regexp.MustCompile(`\b0[xX][0-9a-fA-F]+\b|\b[0-9a-fA-F]{8,}\b`), "<hex>"},
+	{regexp.MustCompile(`\d+(\.\d+)?`), "<n>"},
+}
+
+func normalizeMessage(message string) string {
+	for _, p := range fingerprintPatterns {
+		message = p.pattern.ReplaceAllString(message, p.replace)
+	}
+	return message
+}
+
+// * stable across line edits, the function of the call site is used rather than its line
+func Fingerprint(message string, function string) string {
+	sum := sha256.Sum256([]byte(normalizeMessage(message) + "\x00" + function))
+	return hex.EncodeToString(sum[:8])
+}
+
+// * called under lock for ERROR and above
+func fingerprintField(message any) Field {
+	function := ""
+	if frame, ok := callerFrame(); ok {
+		function = frame.Function
+	}
+	return Field{Key: "fingerprint", Value: Fingerprint(fmt.Sprintf("%v", message), function)}
+}
@@ -0,0 +1,161 @@
+package goLogger
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultFluentdTag = "app"
+
+// 以 fluentd forward 協定（msgpack over TCP）推送日誌至 fluentd 或 fluent-bit
+type FluentdSink struct {
+	Addr       string          // fluentd 位址，如 "fluentd:24224"
+	TLS        *tls.Config     // 設定後以 TLS 連線，可由 TLSFiles 載入憑證
+	Tag        string          // 事件標籤，預設 "app"
+	RequireAck bool            // 啟用 ack 模式，收到確認才視為送達
+	Compress   bool            // 以 gzip 壓縮每批事件（CompressedPackedForward），fluentd v0.14 以上支援
+	AckTimeout time.Duration   // 等待 ack 的時間，預設 10 秒
+	MinLevel   string          // 送出的最低層級，預設全部
+	BatchSize  int             // 單次送出最多筆數，預設 100
+	Interval   time.Duration   // 批次送出的間隔，預設 5 秒
+	Retry      *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker    *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue      *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	once       sync.Once
+	batcher    *batcher
+	conn       netConn
+	minLevel   string
+	initErr    error
+}
+
+func (f *FluentdSink) init() error {
+	f.once.Do(func() {
+		if f.Addr == "" {
+			f.initErr = fmt.Errorf("Addr is required")
+			return
+		}
+		if f.MinLevel != "" {
+			if f.minLevel, f.initErr = parseLevel(f.MinLevel); f.initErr != nil {
+				return
+			}
+		}
+		f.conn = netConn{network: "tcp", addr: f.Addr, tls: f.TLS}
+		f.batcher, f.initErr = newBatcher(f.BatchSize, f.Interval, f.Retry, f.Breaker, f.Queue, f.send)
+	})
+	return f.initErr
+}
+
+func (f *FluentdSink) reportErrors(report func(error)) {
+	if f.init() == nil {
+		f.batcher.setReport(report)
+	}
+}
+
+func (f *FluentdSink) Write(entry Entry) error {
+	if err := f.init(); err != nil {
+		return err
+	}
+	if f.minLevel != "" && levelRank[entry.Level] < levelRank[f.minLevel] {
+		return nil
+	}
+	f.batcher.add(entry)
+	return nil
+}
+
+func (f *FluentdSink) Flush() {
+	if f.init() == nil {
+		f.batcher.Flush()
+	}
+}
+
+func (f *FluentdSink) Close() {
+	if f.init() == nil {
+		f.batcher.Close()
+		f.conn.close()
+	}
+}
+
+func (f *FluentdSink) undelivered() int {
+	if f.init() != nil {
+		return 0
+	}
+	return f.batcher.undelivered()
+}
+
+func (f *FluentdSink) queued() int {
+	if f.init() != nil {
+		return 0
+	}
+	return f.batcher.queued()
+}
+
+// * Forward mode: [tag, [[time, record], ...], {"chunk": id}], compressed it is
+// * CompressedPackedForward: [tag, gzip(time record time record ...), {"compressed": "gzip"}]
+func (f *FluentdSink) send(entries []Entry) error {
+	tag := f.Tag
+	if tag == "" {
+		tag = defaultFluentdTag
+	}
+	events := make([]any, len(entries))
+	for i, entry := range entries {
+		record := entryAttributes(entry)
+		delete(record, "time")
+		events[i] = []any{eventTime(entry.Time), record}
+	}
+	option := map[string]any{"size": len(entries)}
+	var payload any = events
+	if f.Compress {
+		var stream []byte
+		for _, event := range events {
+			stream = appendMsgpack(stream, event)
+		}
+		compressed, err := compress("gzip", stream)
+		if err != nil {
+			return fmt.Errorf("Failed to compress for fluentd %s: %w", f.Addr, err)
+		}
+		payload = compressed
+		option["compressed"] = "gzip"
+	}
+	var chunk string
+	if f.RequireAck {
+		id := make([]byte, 16)
+		rand.Read(id)
+		chunk = base64.StdEncoding.EncodeToString(id)
+		option["chunk"] = chunk
+	}
+	data := appendMsgpack(nil, []any{tag, payload, option})
+
+	if err := f.deliver(data, chunk); err != nil {
+		return fmt.Errorf("Failed to send to fluentd %s: %w", f.Addr, err)
+	}
+	return nil
+}
+
+func (f *FluentdSink) deliver(data []byte, chunk string) error {
+	timeout := f.AckTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if err := f.conn.write(data, timeout); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	f.conn.conn.SetReadDeadline(time.Now().Add(timeout))
+	response, err := readMsgpack(f.conn.reader)
+	if err != nil {
+		f.conn.close()
+		return fmt.Errorf("waiting for ack: %w", err)
+	}
+	if ack, _ := response.(map[string]any); ack == nil || ack["ack"] != chunk {
+		f.conn.close()
+		return fmt.Errorf("unexpected ack %v", response)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package goLogger
+
+import "fmt"
+
+// * one message per entry, formatted output never splits into branches
+func (l *Logger) Debugf(format string, args ...any) {
+	l.writef(nil, logDebug, defaultDebugName, format, args)
+}
+
+func (l *Logger) Tracef(format string, args ...any) {
+	l.writef(nil, logTrace, defaultDebugName, format, args)
+}
+
+func (l *Logger) Infof(format string, args ...any) {
+	l.writef(nil, logInfo, defaultOutputName, format, args)
+}
+
+func (l *Logger) Noticef(format string, args ...any) {
+	l.writef(nil, logNotice, defaultOutputName, format, args)
+}
+
+func (l *Logger) Warnf(format string, args ...any) {
+	l.writef(nil, logWarning, defaultOutputName, format, args)
+}
+
+// * built with fmt.Errorf, so %w keeps the cause for errors.Is and errors.As
+func (l *Logger) Errorf(format string, args ...any) error {
+	return l.writeErrorf(nil, logError, format, args)
+}
+
+func (l *Logger) Fatalf(format string, args ...any) error {
+	return l.writeErrorf(nil, logFatal, format, args)
+}
+
+func (l *Logger) Criticalf(format string, args ...any) error {
+	return l.writeErrorf(nil, logCritical, format, args)
+}
+
+// * the level is checked first, a filtered entry costs no formatting
+func (l *Logger) writef(scope *Child, level string, filename string, format string, args []any) {
+	guard := scope
+	if guard == nil {
+		guard = &Child{logger: l}
+	}
+	if !guard.Enabled(level) {
+		return
+	}
+	l.writeScoped(scope, filename, level, nil, fmt.Sprintf(format, args...))
+}
+
+// * the wrapped error goes through, so its chain is written as causes
+func (l *Logger) writeErrorf(scope *Child, level string, format string, args []any) error {
+	err := fmt.Errorf(format, args...)
+	return withWriteError(err, l.writeScoped(scope, defaultErrorName, level, causeFields(err), err))
+}
+
+func (c *Child) Debugf(format string, args ...any) {
+	c.logger.writef(c, logDebug, defaultDebugName, format, args)
+}
+
+func (c *Child) Tracef(format string, args ...any) {
+	c.logger.writef(c, logTrace, defaultDebugName, format, args)
+}
+
+func (c *Child) Infof(format string, args ...any) {
+	c.logger.writef(c, logInfo, defaultOutputName, format, args)
+}
+
+func (c *Child) Noticef(format string, args ...any) {
+	c.logger.writef(c, logNotice, defaultOutputName, format, args)
+}
+
+func (c *Child) Warnf(format string, args ...any) {
+	c.logger.writef(c, logWarning, defaultOutputName, format, args)
+}
+
+func (c *Child) Errorf(format string, args ...any) error {
+	return c.logger.writeErrorf(c, logError, format, args)
+}
+
+func (c *Child) Fatalf(format string, args ...any) error {
+	return c.logger.writeErrorf(c, logFatal, format, args)
+}
+
+func (c *Child) Criticalf(format string, args ...any) error {
+	return c.logger.writeErrorf(c, logCritical, format, args)
+}
+
+func Debugf(format string, args ...any) {
+	if defaultEnabled(logDebug) {
+		Default().Debug(fmt.Sprintf(format, args...))
+	}
+}
+
+func Infof(format string, args ...any) {
+	if defaultEnabled(logInfo) {
+		Default().Info(fmt.Sprintf(format, args...))
+	}
+}
+
+func Warnf(format string, args ...any) {
+	if defaultEnabled(logWarning) {
+		Default().Warn(fmt.Sprintf(format, args...))
+	}
+}
+
+// * an Interface without Enabled, e.g. the stderr fallback, takes every level
+func defaultEnabled(level string) bool {
+	if guard, ok := Default().(interface{ Enabled(level string) bool }); ok {
+		return guard.Enabled(level)
+	}
+	return true
+}
+
+func Errorf(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	Default().Error(err)
+	return err
+}
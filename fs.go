@@ -0,0 +1,164 @@
+package goLogger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// * every file operation of a Logger goes through FS, standalone helpers such as
+// * Query(dir), Verify and Migrate work on the OS filesystem; Owner and Group
+// * also need a Chown(name string, uid, gid int) error method
+type FS interface {
+	OpenFile(name string, flag int, perm os.FileMode) (File, error)
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	MkdirAll(path string, perm os.FileMode) error
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+	Chmod(name string, mode os.FileMode) error
+	Symlink(oldname, newname string) error
+}
+
+type File interface {
+	io.ReadWriteCloser
+	io.Seeker
+	Name() string
+	Stat() (os.FileInfo, error)
+	Sync() error
+}
+
+type osFS struct{}
+
+func OSFS() FS {
+	return osFS{}
+}
+
+func (osFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	file, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		// * keep the interface nil instead of a typed nil pointer
+		return nil, err
+	}
+	return file, nil
+}
+
+func (osFS) Stat(name string) (os.FileInfo, error)        { return os.Stat(name) }
+func (osFS) Lstat(name string) (os.FileInfo, error)       { return os.Lstat(name) }
+func (osFS) ReadDir(name string) ([]os.DirEntry, error)   { return os.ReadDir(name) }
+func (osFS) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+func (osFS) Rename(oldpath, newpath string) error         { return os.Rename(oldpath, newpath) }
+func (osFS) Remove(name string) error                     { return os.Remove(name) }
+func (osFS) Chmod(name string, mode os.FileMode) error    { return os.Chmod(name, mode) }
+func (osFS) Symlink(oldname, newname string) error        { return os.Symlink(oldname, newname) }
+func (osFS) Chown(name string, uid, gid int) error        { return os.Chown(name, uid, gid) }
+
+// * files accept writes and keep nothing, for processes whose entries only go to sinks,
+// * e.g. siblings forwarding to an aggregator through UnixSink; Stat sees every path as
+// * an empty directory so the path validates and nothing ever rotates
+func DiscardFS() FS {
+	return discardFS{}
+}
+
+type discardFS struct{}
+
+type discardFile struct {
+	name string
+}
+
+type discardInfo struct {
+	name  string
+	isDir bool
+}
+
+func (discardFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	return discardFile{name: name}, nil
+}
+
+func (discardFS) Stat(name string) (os.FileInfo, error) {
+	return discardInfo{name: name, isDir: true}, nil
+}
+
+func (discardFS) Lstat(name string) (os.FileInfo, error) {
+	return nil, &os.PathError{Op: "lstat", Path: name, Err: os.ErrNotExist}
+}
+
+func (discardFS) ReadDir(name string) ([]os.DirEntry, error)   { return nil, nil }
+func (discardFS) MkdirAll(path string, perm os.FileMode) error { return nil }
+func (discardFS) Rename(oldpath, newpath string) error         { return nil }
+func (discardFS) Remove(name string) error                     { return nil }
+func (discardFS) Chmod(name string, mode os.FileMode) error    { return nil }
+func (discardFS) Symlink(oldname, newname string) error        { return nil }
+func (discardFS) Chown(name string, uid, gid int) error        { return nil }
+
+func (f discardFile) Name() string                                 { return f.name }
+func (f discardFile) Read(p []byte) (int, error)                   { return 0, io.EOF }
+func (f discardFile) Write(p []byte) (int, error)                  { return len(p), nil }
+func (f discardFile) Seek(offset int64, whence int) (int64, error) { return 0, nil }
+func (f discardFile) Stat() (os.FileInfo, error)                   { return discardInfo{name: f.name}, nil }
+func (f discardFile) Sync() error                                  { return nil }
+func (f discardFile) Close() error                                 { return nil }
+
+func (i discardInfo) Name() string       { return filepath.Base(i.name) }
+func (i discardInfo) Size() int64        { return 0 }
+func (i discardInfo) ModTime() time.Time { return time.Time{} }
+func (i discardInfo) IsDir() bool        { return i.isDir }
+func (i discardInfo) Sys() any           { return nil }
+
+func (i discardInfo) Mode() os.FileMode {
+	if i.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (l *Logger) fs() FS {
+	return fileSystem(l.config)
+}
+
+func fileSystem(config *Log) FS {
+	if config.FS != nil {
+		return config.FS
+	}
+	return osFS{}
+}
+
+func dirMode(config *Log) os.FileMode {
+	if config.DirMode != 0 {
+		return config.DirMode
+	}
+	return 0755
+}
+
+func fileMode(config *Log) os.FileMode {
+	if config.FileMode != 0 {
+		return config.FileMode
+	}
+	return 0644
+}
+
+// * OpenFile and MkdirAll leave existing paths alone, an explicit mode is applied to them too
+func applyMode(fsys FS, path string, mode os.FileMode) error {
+	if mode == 0 {
+		return nil
+	}
+	if err := fsys.Chmod(path, mode); err != nil {
+		return fmt.Errorf("Failed to set mode of %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeFile(fsys FS, name string, data []byte, perm os.FileMode) error {
+	file, err := fsys.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,233 @@
+package goLogger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcpLoggingEndpoint = "https://logging.googleapis.com/v2/entries:write"
+	gcpMetadataHost    = "http://metadata.google.internal/computeMetadata/v1/"
+)
+
+// 將日誌批次寫入 Google Cloud Logging，GKE、Cloud Run 可直接使用中繼資料伺服器的服務帳號
+type GCPSink struct {
+	ProjectID   string                                    // 專案 ID，預設由中繼資料伺服器取得
+	LogName     string                                    // 日誌名稱，預設 "app"
+	Resource    string                                    // 監控資源類型，如 "k8s_container"、"cloud_run_revision"，預設 "global"
+	Labels      map[string]string                         // 監控資源標籤，如 {"service_name": "api"}
+	EntryLabels map[string]string                         // 每筆日誌附加的標籤
+	MinLevel    string                                    // 送出的最低層級，預設全部
+	Compression string                                    // 請求內容的壓縮方式，如 "gzip"，預設不壓縮
+	BatchSize   int                                       // 單次請求最多筆數，預設 100
+	Interval    time.Duration                             // 批次送出的間隔，預設 5 秒
+	Retry       *RetryPolicy                              // 失敗時的重試策略，預設不重試
+	Breaker     *CircuitBreaker                           // 連續失敗時暫停嘗試，預設不啟用
+	Queue       *DiskQueue                                // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	Endpoint    string                                    // 自訂 API 網址，預設 https://logging.googleapis.com/v2/entries:write
+	TokenSource func(ctx context.Context) (string, error) // 取得 OAuth 存取權杖，預設由中繼資料伺服器取得
+	Client      *http.Client                              // 預設逾時 30 秒的 http.Client
+	once        sync.Once
+	batcher     *batcher
+	minLevel    string
+	initErr     error
+	tokenMutex  sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// * GCP has no FATAL, shift up to keep the order
+var gcpSeverity = map[string]string{
+	logDebug:    "DEBUG",
+	logTrace:    "DEBUG",
+	logInfo:     "INFO",
+	logNotice:   "NOTICE",
+	logWarning:  "WARNING",
+	logError:    "ERROR",
+	logFatal:    "CRITICAL",
+	logCritical: "ALERT",
+}
+
+func (g *GCPSink) init() error {
+	g.once.Do(func() {
+		if g.initErr = checkCompression(g.Compression); g.initErr != nil {
+			return
+		}
+		if g.MinLevel != "" {
+			if g.minLevel, g.initErr = parseLevel(g.MinLevel); g.initErr != nil {
+				return
+			}
+		}
+		g.batcher, g.initErr = newBatcher(g.BatchSize, g.Interval, g.Retry, g.Breaker, g.Queue, g.send)
+	})
+	return g.initErr
+}
+
+func (g *GCPSink) reportErrors(report func(error)) {
+	if g.init() == nil {
+		g.batcher.setReport(report)
+	}
+}
+
+func (g *GCPSink) Write(entry Entry) error {
+	if err := g.init(); err != nil {
+		return err
+	}
+	if g.minLevel != "" && levelRank[entry.Level] < levelRank[g.minLevel] {
+		return nil
+	}
+	g.batcher.add(entry)
+	return nil
+}
+
+func (g *GCPSink) Flush() {
+	if g.init() == nil {
+		g.batcher.Flush()
+	}
+}
+
+func (g *GCPSink) Close() {
+	if g.init() == nil {
+		g.batcher.Close()
+	}
+}
+
+func (g *GCPSink) undelivered() int {
+	if g.init() != nil {
+		return 0
+	}
+	return g.batcher.undelivered()
+}
+
+func (g *GCPSink) queued() int {
+	if g.init() != nil {
+		return 0
+	}
+	return g.batcher.queued()
+}
+
+func (g *GCPSink) send(entries []Entry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	project := g.ProjectID
+	if project == "" {
+		var err error
+		if project, err = g.metadata(ctx, "project/project-id"); err != nil {
+			return fmt.Errorf("Failed to send to Cloud Logging: project id: %w", err)
+		}
+		g.ProjectID = project
+	}
+	token, err := g.accessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to send to Cloud Logging: token: %w", err)
+	}
+
+	logName, resource := g.LogName, g.Resource
+	if logName == "" {
+		logName = "app"
+	}
+	if resource == "" {
+		resource = "global"
+	}
+	items := make([]map[string]any, len(entries))
+	for i, entry := range entries {
+		payload := entryAttributes(entry)
+		delete(payload, "msg")
+		delete(payload, "time")
+		delete(payload, "level")
+		payload["message"] = entry.Message
+		item := map[string]any{
+			"severity":    gcpSeverity[entry.Level],
+			"timestamp":   entry.Time.UTC().Format(time.RFC3339Nano),
+			"jsonPayload": payload,
+		}
+		if len(g.EntryLabels) > 0 {
+			item["labels"] = g.EntryLabels
+		}
+		items[i] = item
+	}
+	body, err := json.Marshal(map[string]any{
+		"logName":  "projects/" + project + "/logs/" + strings.ReplaceAll(logName, "/", "%2F"),
+		"resource": map[string]any{"type": resource, "labels": g.Labels},
+		"entries":  items,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to encode Cloud Logging batch: %w", err)
+	}
+
+	target := g.Endpoint
+	if target == "" {
+		target = gcpLoggingEndpoint
+	}
+	if err := postBatch(ctx, g.Client, target, body, g.Compression, map[string]string{"Authorization": "Bearer " + token}); err != nil {
+		return fmt.Errorf("Failed to send to Cloud Logging: %w", err)
+	}
+	return nil
+}
+
+// * cached until a minute before it expires
+func (g *GCPSink) accessToken(ctx context.Context) (string, error) {
+	if g.TokenSource != nil {
+		return g.TokenSource(ctx)
+	}
+
+	g.tokenMutex.Lock()
+	defer g.tokenMutex.Unlock()
+
+	if g.token != "" && time.Now().Before(g.tokenExpiry) {
+		return g.token, nil
+	}
+	text, err := g.metadata(ctx, "instance/service-accounts/default/token")
+	if err != nil {
+		return "", err
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal([]byte(text), &token); err != nil {
+		return "", err
+	}
+	g.token = token.AccessToken
+	g.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return g.token, nil
+}
+
+func (g *GCPSink) metadata(ctx context.Context, path string) (string, error) {
+	host := gcpMetadataHost
+	if env := os.Getenv("GCE_METADATA_HOST"); env != "" {
+		host = "http://" + env + "/computeMetadata/v1/"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	client := g.Client
+	if client == nil {
+		client = &http.Client{Timeout: remoteTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return strings.TrimSpace(string(body)), nil
+}
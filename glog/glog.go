@@ -0,0 +1,162 @@
+package glog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	goLogger "github.com/pardnchiu/go-logger"
+)
+
+// * glog and klog call shapes on top of goLogger.Default(), set it with goLogger.SetDefault
+
+type Level int32
+
+// * glog's Verbose is a bool, `if glog.V(2) { ... }` keeps compiling
+type Verbose bool
+
+// * replaced in tests, Fatal and Exit terminate like glog does
+var exit = os.Exit
+
+type verboser interface {
+	V(level int) goLogger.Verbose
+}
+
+type structured interface {
+	With(args ...any) *goLogger.Child
+}
+
+type flusher interface {
+	Flush() error
+}
+
+func V(level Level) Verbose {
+	if logger, ok := goLogger.Default().(verboser); ok {
+		return Verbose(logger.V(int(level)).Enabled())
+	}
+	// * the stderr fallback behaves like glog's default -v=0
+	return level <= 0
+}
+
+func (v Verbose) Enabled() bool {
+	return bool(v)
+}
+
+func (v Verbose) Info(args ...any) {
+	if v {
+		Info(args...)
+	}
+}
+
+func (v Verbose) Infof(format string, args ...any) {
+	if v {
+		Infof(format, args...)
+	}
+}
+
+func (v Verbose) Infoln(args ...any) {
+	if v {
+		Infoln(args...)
+	}
+}
+
+func (v Verbose) InfoS(msg string, keysAndValues ...any) {
+	if v {
+		InfoS(msg, keysAndValues...)
+	}
+}
+
+func Info(args ...any) {
+	goLogger.Default().Info(fmt.Sprint(args...))
+}
+
+func Infof(format string, args ...any) {
+	goLogger.Default().Info(fmt.Sprintf(format, args...))
+}
+
+func Infoln(args ...any) {
+	goLogger.Default().Info(sprintln(args))
+}
+
+func Warning(args ...any) {
+	goLogger.Default().Warn(fmt.Sprint(args...))
+}
+
+func Warningf(format string, args ...any) {
+	goLogger.Default().Warn(fmt.Sprintf(format, args...))
+}
+
+func Warningln(args ...any) {
+	goLogger.Default().Warn(sprintln(args))
+}
+
+func Error(args ...any) {
+	goLogger.Default().Error(nil, fmt.Sprint(args...))
+}
+
+func Errorf(format string, args ...any) {
+	goLogger.Default().Error(nil, fmt.Sprintf(format, args...))
+}
+
+func Errorln(args ...any) {
+	goLogger.Default().Error(nil, sprintln(args))
+}
+
+// * written as FATAL, then the process exits with 255 like glog
+func Fatal(args ...any) {
+	fatal(255, fmt.Sprint(args...))
+}
+
+func Fatalf(format string, args ...any) {
+	fatal(255, fmt.Sprintf(format, args...))
+}
+
+func Fatalln(args ...any) {
+	fatal(255, sprintln(args))
+}
+
+// * like Fatal with exit code 1
+func Exit(args ...any) {
+	fatal(1, fmt.Sprint(args...))
+}
+
+func Exitf(format string, args ...any) {
+	fatal(1, fmt.Sprintf(format, args...))
+}
+
+func Exitln(args ...any) {
+	fatal(1, sprintln(args))
+}
+
+// * klog structured calls, key/value pairs become fields when the default logger supports them
+func InfoS(msg string, keysAndValues ...any) {
+	if logger, ok := goLogger.Default().(structured); ok && len(keysAndValues) > 0 {
+		logger.With(keysAndValues...).Info(msg)
+		return
+	}
+	goLogger.Default().Info(msg)
+}
+
+func ErrorS(err error, msg string, keysAndValues ...any) {
+	if logger, ok := goLogger.Default().(structured); ok && len(keysAndValues) > 0 {
+		logger.With(keysAndValues...).Error(err, msg)
+		return
+	}
+	goLogger.Default().Error(err, msg)
+}
+
+func Flush() {
+	if logger, ok := goLogger.Default().(flusher); ok {
+		logger.Flush()
+	}
+}
+
+func fatal(code int, message string) {
+	goLogger.Default().Fatal(nil, message)
+	Flush()
+	exit(code)
+}
+
+func sprintln(args []any) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
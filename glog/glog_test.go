@@ -0,0 +1,56 @@
+package glog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	goLogger "github.com/pardnchiu/go-logger"
+)
+
+func TestShim(t *testing.T) {
+	fsys := goLogger.NewMemFS()
+	logger, err := goLogger.NewWithOptions(goLogger.WithFS(fsys), goLogger.WithPath("logs"), goLogger.WithVerbosity(1), goLogger.WithCaller())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	goLogger.SetDefault(logger)
+	defer goLogger.SetDefault(nil)
+
+	var code int
+	original := exit
+	exit = func(c int) { code = c }
+	defer func() { exit = original }()
+
+	Infof("pod %s ready", "web-0")
+	Info("joined", 1, "x")
+	V(1).Infoln("verbose", "one")
+	V(2).Info("too verbose")
+	if V(2) {
+		t.Error("V(2) should be disabled at verbosity 1")
+	}
+	Warningf("retry %d", 3)
+	ErrorS(errors.New("timeout"), "sync failed", "pod", "web-0")
+	Fatalf("unrecoverable %s", "state")
+	if code != 255 {
+		t.Errorf("Fatal should exit with 255, got %d", code)
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	for _, want := range []string{"pod web-0 ready", "joined1x", "verbose one", "[WARNING] retry 3", "caller=glog_test.go:"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected %q in output, got %s", want, output)
+		}
+	}
+	if strings.Contains(string(output), "too verbose") {
+		t.Errorf("V(2) should be dropped, got %s", output)
+	}
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	for _, want := range []string{"[ERROR] sync failed", "pod=web-0", "timeout", "[FATAL] unrecoverable state"} {
+		if !strings.Contains(string(errorLog), want) {
+			t.Errorf("Expected %q in error log, got %s", want, errorLog)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package goLogger
+
+// * slog-style arguments: key/value pairs or Field values, a key without value becomes !BADKEY
+func (l *Logger) With(args ...any) *Child {
+	return (&Child{logger: l}).With(args...)
+}
+
+// * fields added afterwards nest under name, {"http":{"status":200}} in json
+func (l *Logger) WithGroup(name string) *Child {
+	return (&Child{logger: l}).WithGroup(name)
+}
+
+func (c *Child) With(args ...any) *Child {
+	added := argsToFields(args)
+	if len(added) == 0 {
+		return c
+	}
+	child := *c
+	child.fields = addFields(c.fields, c.groups, added)
+	return &child
+}
+
+func (c *Child) WithGroup(name string) *Child {
+	if name == "" {
+		return c
+	}
+	child := *c
+	child.groups = append(c.groups[:len(c.groups):len(c.groups)], name)
+	return &child
+}
+
+func argsToFields(args []any) []Field {
+	var fields []Field
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case Field:
+			fields = append(fields, arg)
+		case string:
+			if i+1 < len(args) {
+				fields = append(fields, Field{Key: arg, Value: args[i+1]})
+				i++
+			} else {
+				fields = append(fields, Field{Key: "!BADKEY", Value: arg})
+			}
+		default:
+			fields = append(fields, Field{Key: "!BADKEY", Value: arg})
+		}
+	}
+	return fields
+}
+
+// * copy on write, children keep sharing their parent's slices
+func addFields(fields []Field, groups []string, added []Field) []Field {
+	result := make([]Field, len(fields), len(fields)+len(added))
+	copy(result, fields)
+	if len(groups) == 0 {
+		return append(result, added...)
+	}
+
+	for i := len(result) - 1; i >= 0; i-- {
+		if nested, isGroup := result[i].Value.([]Field); isGroup && result[i].Key == groups[0] {
+			result[i].Value = addFields(nested, groups[1:], added)
+			return result
+		}
+	}
+	return append(result, Field{Key: groups[0], Value: addFields(nil, groups[1:], added)})
+}
+
+func cloneFields(fields []Field) []Field {
+	if fields == nil {
+		return nil
+	}
+	cloned := make([]Field, len(fields))
+	for i, field := range fields {
+		if group, isGroup := field.Value.([]Field); isGroup {
+			field.Value = cloneFields(group)
+		}
+		cloned[i] = field
+	}
+	return cloned
+}
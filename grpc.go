@@ -0,0 +1,215 @@
+package goLogger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// * gRPC status codes used by the handler
+const (
+	grpcOK              = 0
+	grpcInvalidArgument = 3
+	grpcUnimplemented   = 12
+	grpcInternal        = 13
+	grpcUnavailable     = 14
+	maxGRPCRequest      = 1 << 20
+)
+
+// * LogService from proto/logger.proto over HTTP/2, without a gRPC dependency, e.g.
+// * server := &http.Server{Addr: ":9090", Handler: logger.GRPCHandler(), Protocols: new(http.Protocols)}
+// * server.Protocols.SetUnencryptedHTTP2(true)
+func (l *Logger) GRPCHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+			return
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+
+		request, err := readGRPCMessage(r.Body)
+		if err != nil {
+			writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+			return
+		}
+		switch r.URL.Path {
+		case "/gologger.v1.LogService/Stream":
+			l.grpcStream(w, r, request)
+		case "/gologger.v1.LogService/SetLevel":
+			l.grpcSetLevel(w, request)
+		default:
+			writeGRPCStatus(w, grpcUnimplemented, "unknown method "+r.URL.Path)
+		}
+	})
+}
+
+func (l *Logger) grpcStream(w http.ResponseWriter, r *http.Request, request []byte) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeGRPCStatus(w, grpcInternal, "streaming unsupported")
+		return
+	}
+	var levels []string
+	err := readProto(request, func(field int, value uint64, data []byte) {
+		if field == 1 {
+			levels = append(levels, string(data))
+		}
+	})
+	if err != nil {
+		writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+		return
+	}
+	entries, cancel, err := l.subscribe(levels...)
+	if err != nil {
+		writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+		return
+	}
+	defer cancel()
+
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry, ok := <-entries:
+			if !ok {
+				writeGRPCStatus(w, grpcUnavailable, "logger closed")
+				return
+			}
+			if _, err := w.Write(grpcMessage(protoEntry(&entry))); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (l *Logger) grpcSetLevel(w http.ResponseWriter, request []byte) {
+	var level string
+	var duration int64
+	err := readProto(request, func(field int, value uint64, data []byte) {
+		switch field {
+		case 1:
+			level = string(data)
+		case 2:
+			duration = int64(value)
+		}
+	})
+	if err != nil {
+		writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+		return
+	}
+
+	// * with duration the change is temporary, see Elevate
+	if duration > 0 {
+		if _, err := l.elevate(level, time.Duration(duration)*time.Millisecond); err != nil {
+			writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+			return
+		}
+	} else if err := l.SetLevel(level); err != nil {
+		writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+		return
+	}
+	w.Write(grpcMessage(appendProtoString(nil, 1, l.Config().Level)))
+	writeGRPCStatus(w, grpcOK, "")
+}
+
+// * a status after the body goes out as trailers, without a body it is a trailers-only response
+func writeGRPCStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", message)
+	}
+}
+
+// * 1 byte compressed flag, 4 byte length, protobuf message
+func readGRPCMessage(body io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(body, header[:]); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("malformed request: %w", err)
+	}
+	if header[0] != 0 {
+		return nil, fmt.Errorf("compressed requests are not supported")
+	}
+	n := binary.BigEndian.Uint32(header[1:])
+	if n > maxGRPCRequest {
+		return nil, fmt.Errorf("request of %d bytes exceeds the limit", n)
+	}
+	message := make([]byte, n)
+	if _, err := io.ReadFull(body, message); err != nil {
+		return nil, fmt.Errorf("malformed request: %w", err)
+	}
+	return message, nil
+}
+
+func grpcMessage(message []byte) []byte {
+	buf := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(buf[1:], uint32(len(message)))
+	return append(buf, message...)
+}
+
+func protoEntry(entry *Entry) []byte {
+	buf := binary.AppendUvarint(nil, 1<<3)
+	buf = binary.AppendUvarint(buf, uint64(entry.Time.UnixNano()))
+	buf = appendProtoString(buf, 2, entry.Level)
+	buf = appendProtoString(buf, 3, entry.Message)
+	for _, data := range entry.Data {
+		buf = appendProtoString(buf, 4, data)
+	}
+	return appendProtoString(buf, 5, string(bytes.TrimSuffix(encodeJSON(entry), []byte("\n"))))
+}
+
+func appendProtoString(buf []byte, field int, value string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+// * calls visit with the varint value or the length-delimited data of each field
+func readProto(message []byte, visit func(field int, value uint64, data []byte)) error {
+	for len(message) > 0 {
+		tag, n := binary.Uvarint(message)
+		if n <= 0 {
+			return fmt.Errorf("malformed message")
+		}
+		message = message[n:]
+		field := int(tag >> 3)
+		switch tag & 7 {
+		case 0:
+			value, n := binary.Uvarint(message)
+			if n <= 0 {
+				return fmt.Errorf("malformed varint in field %d", field)
+			}
+			message = message[n:]
+			visit(field, value, nil)
+		case 1, 5:
+			size := 8
+			if tag&7 == 5 {
+				size = 4
+			}
+			if len(message) < size {
+				return fmt.Errorf("malformed fixed field %d", field)
+			}
+			message = message[size:]
+		case 2:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || uint64(len(message)-n) < length {
+				return fmt.Errorf("malformed length in field %d", field)
+			}
+			visit(field, 0, message[n:n+int(length)])
+			message = message[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", tag&7, field)
+		}
+	}
+	return nil
+}
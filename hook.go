@@ -0,0 +1,126 @@
+package goLogger
+
+import (
+	"fmt"
+	"os"
+)
+
+func (l *Logger) AddHook(hook Hook) {
+	if hook == nil {
+		return
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.hooks = append(l.hooks, hook)
+}
+
+// * runs outside the lock before the logging call returns, the callback may log through l
+func (l *Logger) OnWrite(callback func(Entry, error)) {
+	if callback == nil {
+		return
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.onWrite = append(l.onWrite, callback)
+}
+
+func (l *Logger) OnExpire(callback func(path string)) {
+	if callback == nil {
+		return
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.onExpire = append(l.onExpire, callback)
+}
+
+func (l *Logger) OnRotate(callback func(oldPath, newPath string)) {
+	if callback == nil {
+		return
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	l.onRotate = append(l.onRotate, callback)
+}
+
+func (l *Logger) OnInternalError(callback func(error)) {
+	if callback == nil {
+		return
+	}
+
+	l.errorMutex.Lock()
+	defer l.errorMutex.Unlock()
+
+	l.onInternalError = append(l.onInternalError, callback)
+}
+
+// * failures of the logger itself, safe to call with or without the main lock;
+// * while the lock is held the callbacks wait for unlock, so they may log
+func (l *Logger) internalError(err error) {
+	l.errorMutex.Lock()
+	callbacks := l.onInternalError
+	l.errorMutex.Unlock()
+
+	if len(callbacks) == 0 {
+		fmt.Fprintf(os.Stderr, "goLogger: %v\n", err)
+		return
+	}
+	l.afterUnlock(func() {
+		for _, callback := range callbacks {
+			callback(err)
+		}
+	})
+}
+
+// * OnWrite callbacks get a copy of the entry after the lock is released
+func (l *Logger) notifyWrite(entry Entry, err error) {
+	if len(l.onWrite) == 0 {
+		return
+	}
+	callbacks := l.onWrite
+	l.afterUnlock(func() {
+		for _, callback := range callbacks {
+			callback(entry, err)
+		}
+	})
+}
+
+// * runs fn right away unless some goroutine holds the main lock, then unlock runs it
+func (l *Logger) afterUnlock(fn func()) {
+	l.errorMutex.Lock()
+	if l.locked {
+		l.pending = append(l.pending, fn)
+		l.errorMutex.Unlock()
+		return
+	}
+	l.errorMutex.Unlock()
+	fn()
+}
+
+func (l *Logger) lock() {
+	l.Mutex.Lock()
+	l.errorMutex.Lock()
+	l.locked = true
+	l.errorMutex.Unlock()
+}
+
+// * callbacks queued while the lock was held run once it is free, in order
+func (l *Logger) unlock() {
+	l.errorMutex.Lock()
+	l.locked = false
+	pending := l.pending
+	l.pending = nil
+	l.errorMutex.Unlock()
+	l.Mutex.Unlock()
+
+	for _, fn := range pending {
+		fn()
+	}
+}
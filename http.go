@@ -0,0 +1,37 @@
+package goLogger
+
+import (
+	"net/http"
+)
+
+func (l *Logger) HTTPError(r *http.Request, status int, err error, messages ...any) error {
+	if len(messages) == 0 {
+		messages = []any{http.StatusText(status)}
+	}
+	messages = resolveLazy(messages)
+
+	fields := []Field{{Key: "status", Value: status}}
+	if r != nil {
+		fields = append([]Field{
+			{Key: "method", Value: r.Method},
+			{Key: "path", Value: r.URL.Path},
+		}, fields...)
+		fields = append(fields,
+			Field{Key: "remote", Value: r.RemoteAddr},
+			Field{Key: "user_agent", Value: r.UserAgent()},
+		)
+	}
+	if err != nil {
+		fields = append(fields, Field{Key: "error", Value: err.Error()})
+		fields = append(fields, causeFields(err)...)
+	}
+
+	// * client errors are expected noise, server errors need attention
+	level := logWarning
+	if status >= http.StatusInternalServerError {
+		level = logError
+	}
+	writeErr := l.writeFields(defaultErrorName, level, fields, messages...)
+
+	return withWriteError(composeError(err, messages), writeErr)
+}
@@ -1,13 +1,16 @@
 package goLogger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,78 +23,264 @@ func New(config *Log) (*Logger, error) {
 			MaxBackup: 5,
 		}
 	}
-	if config.Path == "" {
-		config.Path = "./logs"
+	if err := applyEnv(config); err != nil {
+		return nil, err
 	}
-	if config.MaxSize == 0 {
-		config.MaxSize = 16 * 1024 * 1024
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
-	if config.MaxBackup == 0 {
-		config.MaxBackup = 5
+	if err := initSinks(config.Sinks); err != nil {
+		return nil, err
 	}
-	if config.Type == "" {
-		config.Type = "text"
+	if err := applyDefaults(config); err != nil {
+		return nil, err
 	}
 
-	if err := os.MkdirAll(config.Path, 0755); err != nil {
+	filters, err := compileFilters(config.Filters)
+	if err != nil {
+		return nil, err
+	}
+	redactors, err := compileRedactors(config.Redact)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := compileLevels(config.Levels)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := compileAlerts(config.Alerts)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := lookupOwnership(config.Owner, config.Group)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := resolveInstance(config.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fileSystem(config).MkdirAll(config.Path, dirMode(config)); err != nil {
 		return nil, fmt.Errorf("Failed to create: %w", err)
 	}
+	if err := applyMode(fileSystem(config), config.Path, config.DirMode); err != nil {
+		return nil, err
+	}
+	if err := applyOwnership(fileSystem(config), config.Path, owner); err != nil {
+		return nil, err
+	}
 
+	// * built last, later failures close them through logger.Close
+	sinks, err := buildSinks(config.SinkConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	// * copy config so caller can't mutate it after construction
+	cfg := copyConfig(config)
 	logger := &Logger{
-		Config: config,
-		File:   make(map[string]*os.File),
+		config:  &cfg,
+		File:    make(map[string]File),
+		standby: make(map[string]File),
+		chain:   make(map[string]string),
+		dated:   make(map[string]string),
+		sizes:   make(map[string]int64),
+		stats: Stats{
+			Entries: make(map[string]uint64),
+			Bytes:   make(map[string]uint64),
+		},
+		expired:     make(map[string]bool),
+		filters:     filters,
+		redactors:   redactors,
+		levels:      levels,
+		alerts:      alerts,
+		routes:      compileRoutes(config.Routes),
+		tenants:     make(map[string]uint64),
+		badTenants:  make(map[string]bool),
+		maskKeys:    make(map[string]bool, len(config.MaskKeys)),
+		sinks:       append(cfg.Sinks[:len(cfg.Sinks):len(cfg.Sinks)], sinks...),
+		configSinks: sinks,
+		owner:       owner,
+		instance:    instance,
+	}
+	if config.RecentSize > 0 {
+		logger.recent = make([]Entry, 0, config.RecentSize)
+	}
+	for _, key := range config.MaskKeys {
+		logger.maskKeys[strings.ToLower(key)] = true
+	}
+
+	if err := logger.prepareLock(); err != nil {
+		logger.Close()
+		return nil, err
+	}
+
+	if err := logger.init(fileMode(config)); err != nil {
+		logger.Close()
+		return nil, err
 	}
 
-	if err := logger.init(0644); err != nil {
+	if err := logger.writeMeta(); err != nil {
 		logger.Close()
 		return nil, err
 	}
 
+	if config.Expvar != "" {
+		logger.publishExpvar(config.Expvar)
+	}
+
+	logger.startSinks()
 	logger.startRotateTimer()
+	logger.startFlushTimer()
+	logger.startWatchTimer()
+	if config.ReopenOnSIGHUP {
+		logger.handleSIGHUP()
+	}
 
 	return logger, nil
 }
 
-func (l *Logger) init(mode os.FileMode) error {
-	files := []string{defaultDebugName, defaultOutputName, defaultErrorName}
+func copyConfig(config *Log) Log {
+	cfg := *config
+	if config.Sampling != nil {
+		sampling := *config.Sampling
+		cfg.Sampling = &sampling
+	}
+	cfg.Filters = append([]FilterRule(nil), config.Filters...)
+	cfg.Redact = append([]RedactRule(nil), config.Redact...)
+	cfg.MaskKeys = append([]string(nil), config.MaskKeys...)
+	cfg.Routes = append([]Route(nil), config.Routes...)
+	cfg.Alerts = append([]AlertRule(nil), config.Alerts...)
+	cfg.Sinks = append([]Sink(nil), config.Sinks...)
+	cfg.SinkConfigs = append([]SinkConfig(nil), config.SinkConfigs...)
+	if config.Levels != nil {
+		cfg.Levels = make(map[string]string, len(config.Levels))
+		for name, level := range config.Levels {
+			cfg.Levels[name] = level
+		}
+	}
+	return cfg
+}
 
-	for _, filename := range files {
-		file, err := l.open(filename, mode)
-		if err != nil {
+func applyDefaults(config *Log) error {
+	if config.Path == "" {
+		config.Path = "./logs"
+	}
+	if config.MaxSize == 0 {
+		config.MaxSize = 16 * 1024 * 1024
+	}
+	if config.MaxBackup == 0 {
+		config.MaxBackup = 5
+	}
+	if config.Type == "" {
+		config.Type = "text"
+	}
+	if config.Level == "" {
+		config.Level = logDebug
+	}
+	level, err := parseLevel(config.Level)
+	if err != nil {
+		return err
+	}
+	config.Level = level
+	return nil
+}
+
+func (l *Logger) init(mode os.FileMode) error {
+	for _, filename := range l.fileNames() {
+		if err := l.openLive(filename, mode); err != nil {
 			return err
 		}
-		l.File[filename] = file
 	}
 
 	return l.initHandler()
 }
 
+func (l *Logger) openLive(filename string, mode os.FileMode) error {
+	file, err := l.open(filename, mode)
+	if err != nil {
+		return err
+	}
+	if err := applyMode(l.fs(), l.livePath(filename), l.config.FileMode); err != nil {
+		file.Close()
+		return err
+	}
+	l.File[filename] = file
+	l.prepareStandby(filename)
+	if l.config.Audit {
+		// * resume the chain of an existing file
+		l.chain[filename] = lastChainHash(l.fs(), l.livePath(filename))
+	}
+	return nil
+}
+
 func (l *Logger) initHandler() error {
 	flags := log.LstdFlags | log.Lmicroseconds
 
-	var debugWriters []io.Writer = []io.Writer{l.File[defaultDebugName]}
-	var outputWriters []io.Writer = []io.Writer{l.File[defaultOutputName]}
-	var errorWriters []io.Writer = []io.Writer{l.File[defaultErrorName]}
+	var debugWriters []io.Writer = []io.Writer{limitLines(l.File[defaultDebugName], l.config.FileLineLimit)}
+	var outputWriters []io.Writer = []io.Writer{limitLines(l.File[defaultOutputName], l.config.FileLineLimit)}
+	var errorWriters []io.Writer = []io.Writer{limitLines(l.File[defaultErrorName], l.config.FileLineLimit)}
 
-	if l.Config.Stdout {
-		debugWriters = append(debugWriters, os.Stdout)
-		outputWriters = append(outputWriters, os.Stdout)
-		errorWriters = append(errorWriters, os.Stderr)
+	var stdoutWriters, stderrWriters []io.Writer
+	if l.config.Stdout {
+		var stdout, stderr io.Writer = os.Stdout, os.Stderr
+		if l.config.Color && l.config.Type != "json" {
+			stdout, stderr = &colorWriter{writer: stdout}, &colorWriter{writer: stderr}
+		}
+		debugWriters = append(debugWriters, limitLines(stdout, l.config.StdoutLineLimit))
+		outputWriters = append(outputWriters, limitLines(stdout, l.config.StdoutLineLimit))
+		errorWriters = append(errorWriters, limitLines(stderr, l.config.StdoutLineLimit))
+		stdoutWriters = append(stdoutWriters, limitLines(stdout, l.config.StdoutLineLimit))
+		stderrWriters = append(stderrWriters, limitLines(stderr, l.config.StdoutLineLimit))
 	}
 
 	l.DebugHandler = log.New(io.MultiWriter(debugWriters...), "", flags)
 	l.OutputHandler = log.New(io.MultiWriter(outputWriters...), "", flags)
 	l.ErrorHandler = log.New(io.MultiWriter(errorWriters...), "", flags)
 
+	l.handlers = map[string]*log.Logger{
+		defaultDebugName:  l.DebugHandler,
+		defaultOutputName: l.OutputHandler,
+		defaultErrorName:  l.ErrorHandler,
+	}
+	// * route and tenant files, stderr only for a tenant's error.log
+	for _, filename := range l.fileNames() {
+		if _, isExist := l.handlers[filename]; isExist {
+			continue
+		}
+		std := stdoutWriters
+		if filepath.Base(filename) == defaultErrorName {
+			std = stderrWriters
+		}
+		writers := append([]io.Writer{limitLines(l.File[filename], l.config.FileLineLimit)}, std...)
+		l.handlers[filename] = log.New(io.MultiWriter(writers...), "", flags)
+	}
+	l.handlerNames = make(map[*log.Logger]string, len(l.handlers))
+	for filename, handler := range l.handlers {
+		l.handlerNames[handler] = filename
+	}
+
 	return nil
 }
 
-func (l *Logger) open(filename string, mode os.FileMode) (*os.File, error) {
-	fullPath := filepath.Join(l.Config.Path, filename)
+func (l *Logger) open(filename string, mode os.FileMode) (File, error) {
+	unlock, err := l.lockRotation()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	if info, err := os.Stat(fullPath); err == nil {
+	if l.config.DatedFiles {
+		return l.openDated(filename, mode)
+	}
+
+	fullPath := filepath.Join(l.config.Path, l.diskName(filename))
+
+	if info, err := l.fs().Stat(fullPath); err == nil {
 		// * file exists
-		if info.Size() > l.Config.MaxSize {
+		if info.Size() > l.maxSize(filename) {
 			// * size exceeds max size
 			if err := l.rotate(fullPath); err != nil {
 				// * failed to rotate
@@ -100,44 +289,96 @@ func (l *Logger) open(filename string, mode os.FileMode) (*os.File, error) {
 		}
 	}
 
-	file, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, mode)
+	file, err := l.fs().OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, mode)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to open %s: %w", filename, err)
 	}
+	if err := l.applyOwnership(fullPath); err != nil {
+		file.Close()
+		return nil, err
+	}
 	return file, nil
 }
 
 func (l *Logger) rotate(path string) error {
-	timestamp := time.Now().Format("20060102_150405")
-	backupPath := fmt.Sprintf("%s.%s", path, timestamp)
+	var backupPath string
+	if l.config.DatedFiles {
+		// * dated files are already their own backup
+		backupPath = l.livePath(l.logicalName(filepath.Base(path)))
+	} else {
+		backupPath = l.backupPath(path)
+
+		if err := l.fs().Rename(path, backupPath); err != nil {
+			// * failed to rename old log
+			return fmt.Errorf("Failed to rotate: %w", err)
+		}
+	}
+
+	if l.config.Checksum {
+		if err := writeChecksum(l.fs(), backupPath); err != nil {
+			l.internalError(err)
+		} else if err := l.applyOwnership(backupPath + checksumExt); err != nil {
+			l.internalError(err)
+		}
+	}
+
+	if l.config.AppendOnly {
+		// * backups are sealed once rotated
+		l.fs().Chmod(backupPath, fileMode(l.config)&^0222)
+	}
+
+	l.stats.LastRotation = l.now()
+
+	for _, callback := range l.onRotate {
+		callback(path, backupPath)
+	}
 
-	if err := os.Rename(path, backupPath); err != nil {
-		// * failed to rename old log
-		return fmt.Errorf("Failed to rotate: %w", err)
+	if l.config.Archiver != nil {
+		l.archiving.Add(1)
+		go l.archive(l.fs(), l.config.Archiver, l.config.DeleteArchived && !l.config.AppendOnly, backupPath)
 	}
 
-	if err := l.Cleanup(path); err != nil {
-		fmt.Printf("Failed to clean: %v", err)
+	if err := l.cleanup(path); err != nil {
+		l.internalError(err)
+	}
+
+	if err := l.enforceQuota(); err != nil {
+		l.internalError(err)
 	}
 
 	return nil
 }
 
 func (l *Logger) Cleanup(path string) error {
+	l.lock()
+	defer l.unlock()
+
+	return l.cleanup(path)
+}
+
+func (l *Logger) cleanup(path string) error {
 	dir := filepath.Dir(path)
 	base := filepath.Base(path)
 
-	files, err := os.ReadDir(dir)
+	files, err := l.fs().ReadDir(dir)
 	if err != nil {
 		return fmt.Errorf("Failed to read: %w", err)
 	}
 
-	backupPattern := regexp.MustCompile(`^` + regexp.QuoteMeta(base) + `\.\d{8}_\d{6}$`)
+	backupPattern := l.backupPattern(base)
+	active := ""
+	if filename, err := filepath.Rel(l.config.Path, path); err == nil && l.dated[l.logicalName(filename)] != "" {
+		active = filepath.Base(l.dated[l.logicalName(filename)])
+	}
 
 	var backupFiles []backupFile
 	for _, file := range files {
 		name := file.Name()
-		// * filename.YYYYMMDD_HHMMSS
+		if name == active {
+			// * active dated file
+			continue
+		}
+		// * filename.YYYYMMDD_HHMMSS or configured format
 		if backupPattern.MatchString(name) {
 			info, err := file.Info()
 			if err != nil {
@@ -146,20 +387,35 @@ func (l *Logger) Cleanup(path string) error {
 
 			backupFiles = append(backupFiles, backupFile{
 				path:    filepath.Join(dir, name),
+				base:    base,
 				modTime: info.ModTime(),
 			})
 		}
 	}
 
-	if len(backupFiles) > l.Config.MaxBackup {
-		sort.Slice(backupFiles, func(i, j int) bool {
-			return backupFiles[i].modTime.After(backupFiles[j].modTime)
-		})
+	sort.Slice(backupFiles, func(i, j int) bool {
+		return olderBackup(backupFiles[j], backupFiles[i])
+	})
 
-		for i := l.Config.MaxBackup; i < len(backupFiles); i++ {
-			if err := os.Remove(backupFiles[i].path); err != nil {
-				return fmt.Errorf("Failed to remove %s: %w", backupFiles[i].path, err)
+	for i, backup := range backupFiles {
+		isExpired := l.config.MaxAge > 0 && l.now().Sub(backup.modTime) > l.config.MaxAge
+
+		if l.config.AppendOnly {
+			// * never destroy records, hand expired backups to export hooks instead
+			if isExpired && !l.expired[backup.path] {
+				l.expired[backup.path] = true
+				for _, callback := range l.onExpire {
+					callback(backup.path)
+				}
 			}
+			continue
+		}
+
+		if i >= l.maxBackup(l.logicalName(base)) || isExpired {
+			if err := l.fs().Remove(backup.path); err != nil {
+				return fmt.Errorf("Failed to remove %s: %w", backup.path, err)
+			}
+			l.fs().Remove(backup.path + checksumExt)
 		}
 	}
 
@@ -174,9 +430,24 @@ func (l *Logger) startRotateTimer() {
 		for {
 			select {
 			case <-l.timer.C:
-				l.checkAndRotate(defaultDebugName)
-				l.checkAndRotate(defaultOutputName)
-				l.checkAndRotate(defaultErrorName)
+				l.lock()
+				if !l.IsClose {
+					for _, filename := range l.fileNames() {
+						if err := l.checkAndRotate(filename); err != nil {
+							l.internalError(err)
+						}
+						if l.config.MaxAge > 0 {
+							// * age based expiry can't wait for the next rotation
+							if err := l.cleanup(filepath.Join(l.config.Path, l.diskName(filename))); err != nil {
+								l.internalError(err)
+							}
+						}
+					}
+					if err := l.enforceQuota(); err != nil {
+						l.internalError(err)
+					}
+				}
+				l.unlock()
 				l.timer.Reset(1 * time.Hour)
 			case <-l.stopTimer:
 				if l.timer != nil {
@@ -199,35 +470,113 @@ func (l *Logger) checkAndRotate(filename string) error {
 		return fmt.Errorf("Failed to get stats: %w", err)
 	}
 
-	if stat.Size() > l.Config.MaxSize {
-		oldFile.Close()
+	if stat.Size() > l.maxSize(filename) {
+		return l.rotateFile(filename)
+	}
 
-		path := filepath.Join(l.Config.Path, filename)
+	return nil
+}
+
+func (l *Logger) rotateFile(filename string) error {
+	oldFile, isExist := l.File[filename]
+	if !isExist {
+		return fmt.Errorf("Failed to read: %s", filename)
+	}
+	unlock, err := l.lockRotation()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path := filepath.Join(l.config.Path, l.diskName(filename))
+	// * with SharedPath another process may have rotated the file under us, then it is only reopened
+	elsewhere := !l.config.DatedFiles && l.rotatedElsewhere(oldFile, path)
+	oldFile.Close()
+
+	if !elsewhere {
 		if err := l.rotate(path); err != nil {
 			return fmt.Errorf("Failed to rotate %s: %w", filename, err)
 		}
+	}
 
-		newFile, err := l.open(filename, 0644)
+	newFile := l.takeStandby(filename)
+	if newFile == nil {
+		var err error
+		newFile, err = l.open(filename, fileMode(l.config))
 		if err != nil {
 			return fmt.Errorf("Failed to reopen %s: %w", filename, err)
 		}
+	}
 
-		l.File[filename] = newFile
+	l.File[filename] = newFile
+	delete(l.chain, filename)
+	delete(l.sizes, filename)
+	go l.refillStandby(filename)
+
+	if err := l.initHandler(); err != nil {
+		return fmt.Errorf("Failed to re-init: %w", err)
+	}
+
+	return nil
+}
 
-		if err := l.initHandler(); err != nil {
-			return fmt.Errorf("Failed to re-init: %w", err)
+func (l *Logger) Rotate(name string) error {
+	if !strings.HasSuffix(name, ".log") {
+		name += ".log"
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	if l.IsClose {
+		return fmt.Errorf("logger is closed")
+	}
+	return l.rotateFile(name)
+}
+
+func (l *Logger) RotateAll() error {
+	l.lock()
+	defer l.unlock()
+
+	if l.IsClose {
+		return fmt.Errorf("logger is closed")
+	}
+
+	var errs []error
+	for _, filename := range l.fileNames() {
+		if err := l.rotateFile(filename); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
+	if len(errs) > 0 {
+		return fmt.Errorf("errors rotating log files: %v", errs)
+	}
+
 	return nil
 }
 
+// * waits at most CloseTimeout for sinks, see CloseContext
 func (l *Logger) Close() error {
-	l.Mutex.Lock()
-	defer l.Mutex.Unlock()
+	ctx := context.Background()
+	if timeout := l.Config().CloseTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	_, err := l.CloseContext(ctx)
+	return err
+}
+
+// * drains the sinks until ctx is done and returns how many entries they never
+// * delivered, given up after retries or abandoned at the deadline; entries
+// * kept in a DiskQueue are replayed on the next start and not counted
+func (l *Logger) CloseContext(ctx context.Context) (int, error) {
+	l.lock()
 
 	if l.IsClose {
-		return nil
+		l.unlock()
+		return 0, nil
 	}
 
 	l.IsClose = true
@@ -236,6 +585,9 @@ func (l *Logger) Close() error {
 		close(l.stopTimer)
 	}
 
+	l.closeStandby()
+	l.closeSubscribers()
+
 	var errs []error
 
 	for filename, file := range l.File {
@@ -244,31 +596,191 @@ func (l *Logger) Close() error {
 		}
 	}
 
+	// * drained outside the lock, a dead letter or error callback may log
+	sinks := l.sinks
+	l.unlock()
+
+	// * uploads and alert webhooks don't take the lock, safe to wait for them here;
+	// * sinks send what they still hold, side by side so a slow one doesn't starve the rest
+	done := make(chan struct{})
+	go func() {
+		l.archiving.Wait()
+		l.alerting.Wait()
+		l.retiring.Wait()
+		var wg sync.WaitGroup
+		for _, sink := range sinks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sink.Close()
+			}()
+		}
+		wg.Wait()
+		close(done)
+	}()
+
+	var drainErr error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		drainErr = ctx.Err()
+	}
+
+	dropped := 0
+	for _, sink := range sinks {
+		if counter, ok := sink.(deliveryCounter); ok {
+			dropped += counter.undelivered()
+		}
+	}
+	if drainErr != nil {
+		// * those still held may go out later, the count is what wasn't confirmed
+		drainErr = fmt.Errorf("Failed to drain sinks, %d entries undelivered: %w", dropped, drainErr)
+	}
+
 	if len(errs) > 0 {
-		return fmt.Errorf("errors closing log files: %v", errs)
+		return dropped, errors.Join(drainErr, fmt.Errorf("errors closing log files: %v", errs))
 	}
 
-	return nil
+	return dropped, drainErr
 }
 
 func (l *Logger) Flush() error {
 	l.Mutex.RLock()
-	defer l.Mutex.RUnlock()
 
 	if l.IsClose {
+		l.Mutex.RUnlock()
 		return fmt.Errorf("logger is closed")
 	}
 
+	errs := l.syncFiles()
+	sinks := l.sinks
+	l.Mutex.RUnlock()
+
+	// * remote sinks may take a while, don't hold up writers
+	for _, sink := range sinks {
+		sink.Flush()
+	}
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("errors flushing log files: %v", errs)
+		l.internalError(err)
+		return err
+	}
+
+	return nil
+}
+
+// * returns once ctx is done even if a disk or sink is still syncing,
+// * the sync itself carries on in the background
+func (l *Logger) FlushContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("Failed to flush: %w", err)
+	}
+	result := make(chan error, 1)
+	go func() {
+		result <- l.Flush()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("Failed to flush: %w", ctx.Err())
+	}
+}
+
+const (
+	syncNever    = "never"
+	syncInterval = "interval"
+	syncError    = "error"
+	syncAlways   = "always"
+)
+
+// * error and always sync right after the write, FlushInterval still applies to the rest
+func (l *Logger) syncsOnWrite(level string) bool {
+	switch l.config.SyncPolicy {
+	case syncAlways:
+		return true
+	case syncError:
+		return levelRank[level] >= levelRank[logError]
+	}
+	return false
+}
+
+// * called under lock
+func (l *Logger) syncFiles() []error {
 	var errs []error
 	for filename, file := range l.File {
 		if err := file.Sync(); err != nil {
 			errs = append(errs, fmt.Errorf("flushing %s: %w", filename, err))
 		}
 	}
+	return errs
+}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("errors flushing log files: %v", errs)
+// * bounds what a crash loses without callers sprinkling Flush, sinks keep
+// * their own Interval
+func (l *Logger) startFlushTimer() {
+	interval, stop := l.config.FlushInterval, l.stopTimer
+	if interval <= 0 {
+		return
 	}
 
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.Mutex.RLock()
+				var errs []error
+				if !l.IsClose {
+					errs = l.syncFiles()
+				}
+				l.Mutex.RUnlock()
+				if len(errs) > 0 {
+					l.internalError(fmt.Errorf("errors flushing log files: %v", errs))
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (l *Logger) FlushFile(name string) error {
+	if !strings.HasSuffix(name, ".log") {
+		name += ".log"
+	}
+
+	l.Mutex.RLock()
+	if l.IsClose {
+		l.Mutex.RUnlock()
+		return fmt.Errorf("logger is closed")
+	}
+
+	file, isExist := l.File[name]
+	if !isExist {
+		l.Mutex.RUnlock()
+		return fmt.Errorf("Failed to flush %s: unknown file", name)
+	}
+	err := file.Sync()
+	l.Mutex.RUnlock()
+
+	// * reported outside the read lock, the callback may log
+	if err != nil {
+		err = fmt.Errorf("Failed to flush %s: %w", name, err)
+		l.internalError(err)
+		return err
+	}
 	return nil
 }
+
+func (l *Logger) FlushLevel(level string) error {
+	level, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	return l.FlushFile(levelFile[level])
+}
@@ -0,0 +1,223 @@
+package goLogger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	jsonSignature = regexp.MustCompile(`,"hmac":"([0-9a-f]{64})"}$`)
+	textSignature = regexp.MustCompile(` \[hmac:([0-9a-f]{64})\]$`)
+)
+
+func computeHMAC(key []byte, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// * sign the encoded entry, signature covers every byte before it
+func signEntry(key []byte, data []byte) []byte {
+	body := bytes.TrimSuffix(data, []byte("\n"))
+	if len(body) > 0 && body[0] == '{' && body[len(body)-1] == '}' {
+		signature := computeHMAC(key, body)
+		return fmt.Appendf(body[:len(body)-1:len(body)-1], `,"hmac":"%s"}`+"\n", signature)
+	}
+
+	signature := computeHMAC(key, body)
+	return fmt.Appendf(body[:len(body):len(body)], " [hmac:%s]\n", signature)
+}
+
+func Verify(path string, key string) error {
+	reader, err := openLogFile(osFS{}, path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	var pending bytes.Buffer
+	lineNum, startLine := 0, 1
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+
+		if match := jsonSignature.FindSubmatchIndex(line); match != nil {
+			body := append(append([]byte{}, line[:match[0]]...), '}')
+			if !hmac.Equal([]byte(computeHMAC([]byte(key), body)), line[match[2]:match[3]]) {
+				return fmt.Errorf("Failed to verify %s: invalid signature at line %d", path, lineNum)
+			}
+			continue
+		}
+
+		if match := textSignature.FindSubmatchIndex(line); match != nil {
+			pending.Write(line[:match[0]])
+			if !hmac.Equal([]byte(computeHMAC([]byte(key), pending.Bytes())), line[match[2]:match[3]]) {
+				return fmt.Errorf("Failed to verify %s: invalid signature at line %d", path, startLine)
+			}
+			pending.Reset()
+			startLine = lineNum + 1
+			continue
+		}
+
+		if len(line) > 0 && line[0] == '{' {
+			return fmt.Errorf("Failed to verify %s: missing signature at line %d", path, lineNum)
+		}
+		// * text entries span several lines, signature is on the last one
+		pending.Write(line)
+		pending.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Failed to read %s: %w", path, err)
+	}
+	if pending.Len() > 0 {
+		return fmt.Errorf("Failed to verify %s: missing signature at line %d", path, startLine)
+	}
+	return nil
+}
+
+var (
+	jsonChain = regexp.MustCompile(`,"hash":"([0-9a-f]{64})"}$`)
+	textChain = regexp.MustCompile(` \[hash:([0-9a-f]{64})\]$`)
+)
+
+func computeChain(prev string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(prev))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// * link the encoded entry to the previous one, hash covers prev hash and every byte before it
+func chainEntry(prev string, data []byte) ([]byte, string) {
+	body := bytes.TrimSuffix(data, []byte("\n"))
+	hash := computeChain(prev, body)
+	if len(body) > 0 && body[0] == '{' && body[len(body)-1] == '}' {
+		return fmt.Appendf(body[:len(body)-1:len(body)-1], `,"hash":"%s"}`+"\n", hash), hash
+	}
+	return fmt.Appendf(body[:len(body):len(body)], " [hash:%s]\n", hash), hash
+}
+
+func lastChainHash(fsys FS, path string) string {
+	file, err := fsys.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	// * only the tail is needed to resume the chain
+	const tailSize = 64 * 1024
+	if info, err := file.Stat(); err == nil && info.Size() > tailSize {
+		file.Seek(info.Size()-tailSize, io.SeekStart)
+	}
+
+	last := ""
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), tailSize+1)
+	for scanner.Scan() {
+		line := stripSignature(scanner.Bytes())
+		if match := jsonChain.FindSubmatch(line); match != nil {
+			last = string(match[1])
+		} else if match := textChain.FindSubmatch(line); match != nil {
+			last = string(match[1])
+		}
+	}
+	return last
+}
+
+func stripSignature(line []byte) []byte {
+	if match := jsonSignature.FindIndex(line); match != nil {
+		return append(append([]byte{}, line[:match[0]]...), '}')
+	}
+	if match := textSignature.FindIndex(line); match != nil {
+		return line[:match[0]]
+	}
+	return line
+}
+
+func VerifyChain(path string) error {
+	reader, err := openLogFile(osFS{}, path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	var pending bytes.Buffer
+	prev := ""
+	lineNum, startLine := 0, 1
+	for scanner.Scan() {
+		lineNum++
+		line := stripSignature(scanner.Bytes())
+
+		var match []int
+		var body []byte
+		if match = jsonChain.FindSubmatchIndex(line); match != nil {
+			body = append(append([]byte{}, line[:match[0]]...), '}')
+		} else if match = textChain.FindSubmatchIndex(line); match != nil {
+			pending.Write(line[:match[0]])
+			body = pending.Bytes()
+		} else if len(line) > 0 && line[0] == '{' {
+			return fmt.Errorf("Failed to verify %s: missing hash at line %d", path, lineNum)
+		} else {
+			// * text entries span several lines, hash is on the last one
+			pending.Write(line)
+			pending.WriteByte('\n')
+			continue
+		}
+
+		hash := string(line[match[2]:match[3]])
+		if computeChain(prev, body) != hash {
+			return fmt.Errorf("Failed to verify %s: broken chain at line %d", path, startLine)
+		}
+		prev = hash
+		pending.Reset()
+		startLine = lineNum + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Failed to read %s: %w", path, err)
+	}
+	if pending.Len() > 0 {
+		return fmt.Errorf("Failed to verify %s: missing hash at line %d", path, startLine)
+	}
+	return nil
+}
+
+const checksumExt = ".sha256"
+
+// * sha256sum compatible, `sha256sum -c` works on the sidecar
+func writeChecksum(fsys FS, path string) error {
+	file, err := fsys.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return fmt.Errorf("Failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("Failed to stat %s: %w", path, err)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("Failed to hash %s: %w", path, err)
+	}
+
+	// * the sidecar is as readable as the backup it covers
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(hash.Sum(nil)), filepath.Base(path))
+	if err := writeFile(fsys, path+checksumExt, []byte(line), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("Failed to write checksum: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package goLogger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJournalSocket   = "/run/systemd/journal/socket"
+	defaultJournalInterval = 100 * time.Millisecond
+)
+
+// 透過 journald 原生 socket 寫入 systemd journal（僅 Linux），保留層級與結構化欄位
+type JournaldSink struct {
+	Identifier string          // SYSLOG_IDENTIFIER，預設執行檔名稱
+	Socket     string          // journald socket 路徑，預設 "/run/systemd/journal/socket"
+	MinLevel   string          // 送出的最低層級，預設全部
+	Interval   time.Duration   // 批次送出的間隔，預設 100 毫秒
+	Retry      *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker    *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue      *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	once       sync.Once
+	batcher    *batcher
+	conn       journalConn
+	minLevel   string
+	initErr    error
+}
+
+// * syslog priorities, FATAL and CRITICAL shift up to keep the order
+var journalPriority = map[string]int{
+	logDebug:    7,
+	logTrace:    7,
+	logInfo:     6,
+	logNotice:   5,
+	logWarning:  4,
+	logError:    3,
+	logFatal:    2,
+	logCritical: 1,
+}
+
+type journalConn interface {
+	Write(data []byte) (int, error)
+	Close() error
+}
+
+func (j *JournaldSink) init() error {
+	j.once.Do(func() {
+		if j.MinLevel != "" {
+			if j.minLevel, j.initErr = parseLevel(j.MinLevel); j.initErr != nil {
+				return
+			}
+		}
+		socket := j.Socket
+		if socket == "" {
+			socket = defaultJournalSocket
+		}
+		if j.conn, j.initErr = dialJournal(socket); j.initErr != nil {
+			return
+		}
+		if j.Identifier == "" {
+			j.Identifier = filepath.Base(os.Args[0])
+		}
+		interval := j.Interval
+		if interval <= 0 {
+			interval = defaultJournalInterval
+		}
+		j.batcher, j.initErr = newBatcher(0, interval, j.Retry, j.Breaker, j.Queue, j.send)
+	})
+	return j.initErr
+}
+
+func (j *JournaldSink) reportErrors(report func(error)) {
+	if j.init() == nil {
+		j.batcher.setReport(report)
+	}
+}
+
+func (j *JournaldSink) Write(entry Entry) error {
+	if err := j.init(); err != nil {
+		return err
+	}
+	if j.minLevel != "" && levelRank[entry.Level] < levelRank[j.minLevel] {
+		return nil
+	}
+	j.batcher.add(entry)
+	return nil
+}
+
+func (j *JournaldSink) Flush() {
+	if j.init() == nil {
+		j.batcher.Flush()
+	}
+}
+
+func (j *JournaldSink) Close() {
+	if j.init() == nil {
+		j.batcher.Close()
+		j.conn.Close()
+	}
+}
+
+func (j *JournaldSink) undelivered() int {
+	if j.init() != nil {
+		return 0
+	}
+	return j.batcher.undelivered()
+}
+
+func (j *JournaldSink) queued() int {
+	if j.init() != nil {
+		return 0
+	}
+	return j.batcher.queued()
+}
+
+// * one datagram per entry
+func (j *JournaldSink) send(entries []Entry) error {
+	var errs []error
+	for _, entry := range entries {
+		if _, err := j.conn.Write(j.encode(entry)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Failed to write %d entries to journald: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+func (j *JournaldSink) encode(entry Entry) []byte {
+	var buf bytes.Buffer
+	appendJournalField(&buf, "MESSAGE", entry.Message)
+	appendJournalField(&buf, "PRIORITY", fmt.Sprint(journalPriority[entry.Level]))
+	appendJournalField(&buf, "SYSLOG_IDENTIFIER", j.Identifier)
+	appendJournalField(&buf, "GOLOGGER_LEVEL", entry.Level)
+	appendJournalField(&buf, "GOLOGGER_TIME", entry.Time.Format(time.RFC3339Nano))
+
+	attrs := entryAttributes(entry)
+	delete(attrs, "msg")
+	delete(attrs, "time")
+	delete(attrs, "level")
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := journalFieldName(key)
+		if name == "" {
+			continue
+		}
+		value, isString := attrs[key].(string)
+		if !isString {
+			data, _ := json.Marshal(attrs[key])
+			value = string(data)
+		}
+		appendJournalField(&buf, name, value)
+	}
+	return buf.Bytes()
+}
+
+// * uppercase letters, digits and _, not starting with _ or a digit, at most 64 characters
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_0123456789")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// * values with a newline use the binary form: name, newline, little-endian length, value
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(name + "=" + value + "\n")
+		return
+	}
+	buf.WriteString(name + "\n")
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+}
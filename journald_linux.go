@@ -0,0 +1,14 @@
+package goLogger
+
+import (
+	"fmt"
+	"net"
+)
+
+func dialJournal(socket string) (journalConn, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to journald: %w", err)
+	}
+	return conn, nil
+}
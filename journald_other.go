@@ -0,0 +1,9 @@
+//go:build !linux
+
+package goLogger
+
+import "fmt"
+
+func dialJournal(socket string) (journalConn, error) {
+	return nil, fmt.Errorf("Failed to connect to journald: only available on Linux")
+}
@@ -0,0 +1,28 @@
+package goLogger
+
+// * deferred message, only evaluated once the entry passes level filtering and sampling
+type Lazy func() any
+
+// * copies before replacing, the caller's slice is left untouched
+func resolveLazy(messages []any) []any {
+	var resolved []any
+	for i, msg := range messages {
+		var value any
+		switch fn := msg.(type) {
+		case Lazy:
+			value = fn()
+		case func() any:
+			value = fn()
+		default:
+			continue
+		}
+		if resolved == nil {
+			resolved = append([]any(nil), messages...)
+		}
+		resolved[i] = value
+	}
+	if resolved == nil {
+		return messages
+	}
+	return resolved
+}
@@ -0,0 +1,103 @@
+package goLogger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+func parseLevel(level string) (string, error) {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if level == "WARN" {
+		level = logWarning
+	}
+	if _, isValid := levelRank[level]; !isValid {
+		return "", fmt.Errorf("Failed to parse level: unknown %q", level)
+	}
+	return level, nil
+}
+
+func (l *Logger) enabled(name string, level string) bool {
+	return levelRank[level] >= levelRank[l.levelOf(name)]
+}
+
+// * nested calls stack: the level is the most verbose one still running and
+// * the original returns when the last elevation ends or expires
+func (l *Logger) Elevate(level string, duration time.Duration) func() {
+	restore, err := l.elevate(level, duration)
+	if err != nil {
+		return func() {}
+	}
+	return restore
+}
+
+// * the handlers report why nothing changed, Elevate itself stays a no-op then
+func (l *Logger) elevate(level string, duration time.Duration) (func(), error) {
+	level, err := parseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	l.lock()
+	if l.IsClose {
+		l.unlock()
+		return nil, fmt.Errorf("logger is closed")
+	}
+	if l.elevation == nil && levelRank[level] >= levelRank[l.config.Level] {
+		// * already at or below requested level
+		current := l.config.Level
+		l.unlock()
+		return nil, fmt.Errorf("Failed to elevate: %s is not more verbose than %s, a temporary change only lowers the level", level, current)
+	}
+
+	current := l.elevation
+	if current == nil {
+		current = &elevation{previous: l.config.Level, active: make(map[int]string)}
+		l.elevation = current
+	}
+	id := current.next
+	current.next++
+	current.active[id] = level
+	if levelRank[level] < levelRank[l.config.Level] {
+		l.config.Level = level
+		l.emit(l.OutputHandler, logNotice, nil, fmt.Sprintf("Verbosity elevated to %s for %s", level, duration))
+	}
+
+	var once sync.Once
+	var timer *time.Timer
+	restore := func() {
+		once.Do(func() {
+			l.lock()
+			defer l.unlock()
+
+			timer.Stop()
+			if l.elevation != current {
+				// * ended by an explicit SetLevel or Reconfigure
+				return
+			}
+			delete(current.active, id)
+			next := current.previous
+			for _, active := range current.active {
+				if levelRank[active] < levelRank[next] {
+					next = active
+				}
+			}
+			if len(current.active) == 0 {
+				l.elevation = nil
+			}
+			if next == l.config.Level {
+				return
+			}
+			l.config.Level = next
+			if !l.IsClose {
+				l.emit(l.OutputHandler, logNotice, nil, fmt.Sprintf("Verbosity restored to %s", next))
+			}
+		})
+	}
+	// * set under the lock restore takes, so an instant expiry still sees it
+	timer = time.AfterFunc(duration, restore)
+	l.unlock()
+
+	return restore, nil
+}
@@ -0,0 +1,149 @@
+package goLogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// * fields kept when a JSON line has to be shortened
+var preservedKeys = []string{"time", "level", "trace_id", "span_id", "error"}
+
+type lineLimitWriter struct {
+	writer io.Writer
+	limit  int
+}
+
+func limitLines(writer io.Writer, limit int) io.Writer {
+	if limit <= 0 {
+		return writer
+	}
+	return &lineLimitWriter{writer: writer, limit: limit}
+}
+
+func (w *lineLimitWriter) Write(p []byte) (int, error) {
+	if !hasLongLine(p, w.limit) {
+		return w.writer.Write(p)
+	}
+
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		content, hasNewline := bytes.CutSuffix(line, []byte("\n"))
+		if len(content) > w.limit {
+			content = limitLine(content, w.limit)
+		}
+		buf.Write(content)
+		if hasNewline {
+			buf.WriteByte('\n')
+		}
+	}
+
+	if _, err := w.writer.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func hasLongLine(p []byte, limit int) bool {
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			return len(p) > limit
+		}
+		if i > limit {
+			return true
+		}
+		p = p[i+1:]
+	}
+	return false
+}
+
+func limitLine(line []byte, limit int) []byte {
+	if line[0] == '{' {
+		if result, ok := limitJSONLine(line, limit); ok {
+			return result
+		}
+	}
+	return limitTextLine(line, limit)
+}
+
+func limitTextLine(line []byte, limit int) []byte {
+	marker := fmt.Sprintf("...truncated (%d bytes)", len(line))
+	cut := limit - len(marker)
+	if cut < 0 {
+		cut = 0
+	}
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return append(append([]byte{}, line[:cut]...), marker...)
+}
+
+func limitJSONLine(line []byte, limit int) ([]byte, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, false
+	}
+
+	var msg string
+	json.Unmarshal(fields["msg"], &msg)
+	var errText string
+	hasError := json.Unmarshal(fields["error"], &errText) == nil
+
+	build := func(msg, errText string) []byte {
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for _, key := range preservedKeys {
+			value, ok := fields[key]
+			if !ok {
+				continue
+			}
+			if key == "error" && hasError {
+				value, _ = json.Marshal(errText)
+			}
+			fmt.Fprintf(&buf, "%q:%s,", key, value)
+			if key == "level" {
+				encoded, _ := json.Marshal(msg)
+				fmt.Fprintf(&buf, `"msg":%s,`, encoded)
+			}
+		}
+		fmt.Fprintf(&buf, `"truncated":%d}`, len(line))
+		return buf.Bytes()
+	}
+
+	// * shrink msg first, then error, until the line fits
+	for i := 0; i < 8; i++ {
+		result := build(msg, errText)
+		over := len(result) - limit
+		if over <= 0 {
+			return result, true
+		}
+		switch {
+		case len(msg) > 0:
+			msg = cutString(msg, len(msg)-over)
+		case hasError && len(errText) > 0:
+			errText = cutString(errText, len(errText)-over)
+		default:
+			return result, true
+		}
+	}
+	return build(msg, errText), true
+}
+
+func cutString(text string, size int) string {
+	if size <= 0 {
+		return ""
+	}
+	if size >= len(text) {
+		return text
+	}
+	for size > 0 && !utf8.RuneStart(text[size]) {
+		size--
+	}
+	return text[:size]
+}
@@ -0,0 +1,118 @@
+package goLogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func NewFromFile(path string) (*Logger, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(config)
+}
+
+// * JSON or YAML by extension, durations may be written as "24h"
+func LoadConfig(path string) (*Log, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read config: %w", err)
+	}
+
+	var tree any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if tree, err = parseYAML(data); err != nil {
+			return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+		}
+	default:
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+		}
+	}
+
+	values, ok := tree.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("Failed to parse %s: expected an object at top level", path)
+	}
+	if err := parseDurations(values, reflect.TypeOf(Log{})); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+	}
+
+	// * round trip through JSON so both formats share the struct tags
+	normalized, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(normalized))
+	decoder.DisallowUnknownFields()
+
+	var config Log
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// * durations as "5s" and file modes as "0700", walks nested structs too, e.g. sampling.tick
+func parseDurations(values map[string]any, kind reflect.Type) error {
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}) {
+			if nested, ok := values[key].(map[string]any); ok {
+				if err := parseDurations(nested, fieldType); err != nil {
+					return fmt.Errorf("%s.%w", key, err)
+				}
+			}
+			continue
+		}
+		if fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Struct {
+			items, _ := values[key].([]any)
+			for _, item := range items {
+				if nested, ok := item.(map[string]any); ok {
+					if err := parseDurations(nested, fieldType.Elem()); err != nil {
+						return fmt.Errorf("%s.%w", key, err)
+					}
+				}
+			}
+			continue
+		}
+
+		text, ok := values[key].(string)
+		if !ok {
+			continue
+		}
+		// * JSON has no octal numbers, modes are written as "0700"
+		if field.Type == reflect.TypeOf(os.FileMode(0)) {
+			mode, err := strconv.ParseUint(text, 8, 32)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			values[key] = mode
+			continue
+		}
+		if field.Type != reflect.TypeOf(time.Duration(0)) {
+			continue
+		}
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = int64(duration)
+	}
+	return nil
+}
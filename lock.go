@@ -0,0 +1,74 @@
+package goLogger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const rotateLockName = ".rotate.lock"
+
+// * optional for FS implementations, needed for SharedPath; blocks until the
+// * lock on name is held, unlock releases it
+type locker interface {
+	Lock(name string) (unlock func() error, err error)
+}
+
+func (osFS) Lock(name string) (func() error, error) { return lockFile(name) }
+
+func (discardFS) Lock(name string) (func() error, error) {
+	return func() error { return nil }, nil
+}
+
+func (l *Logger) lockPath() string {
+	return filepath.Join(l.config.Path, rotateLockName)
+}
+
+// * the lock file exists before any rotation, with the mode and owner of the logs
+func (l *Logger) prepareLock() error {
+	if !l.config.SharedPath {
+		return nil
+	}
+	if _, ok := l.fs().(locker); !ok {
+		return fmt.Errorf("Failed to prepare shared path: FS does not support Lock")
+	}
+	file, err := l.fs().OpenFile(l.lockPath(), os.O_CREATE|os.O_WRONLY, fileMode(l.config))
+	if err != nil {
+		return fmt.Errorf("Failed to prepare shared path: %w", err)
+	}
+	file.Close()
+	if err := l.applyOwnership(l.lockPath()); err != nil {
+		return err
+	}
+
+	// * platforms without flock fail here rather than on the first rotation
+	unlock, err := l.fs().(locker).Lock(l.lockPath())
+	if err != nil {
+		return fmt.Errorf("Failed to prepare shared path: %w", err)
+	}
+	return unlock()
+}
+
+// * called under lock; flock belongs to an open file, so an open during a rotation
+// * reuses the held lock instead of waiting on itself
+func (l *Logger) lockRotation() (func(), error) {
+	if !l.config.SharedPath || l.rotationLocked {
+		return func() {}, nil
+	}
+	unlock, err := l.fs().(locker).Lock(l.lockPath())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to lock rotation: %w", err)
+	}
+	l.rotationLocked = true
+	return func() {
+		l.rotationLocked = false
+		if err := unlock(); err != nil {
+			l.internalError(fmt.Errorf("Failed to unlock rotation: %w", err))
+		}
+	}, nil
+}
+
+// * another process sharing Path already moved the file away
+func (l *Logger) rotatedElsewhere(file File, path string) bool {
+	return l.config.SharedPath && l.fileMoved(file, path)
+}
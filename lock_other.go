@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package goLogger
+
+import "fmt"
+
+func lockFile(name string) (func() error, error) {
+	return nil, fmt.Errorf("file locking is not supported on this platform")
+}
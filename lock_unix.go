@@ -0,0 +1,24 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package goLogger
+
+import (
+	"os"
+	"syscall"
+)
+
+// * advisory, only other SharedPath loggers honor it
+func lockFile(name string) (func() error, error) {
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() error {
+		// * closing the file releases the lock as well
+		return file.Close()
+	}, nil
+}
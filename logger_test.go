@@ -1,12 +1,43 @@
 package goLogger
 
 import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"expvar"
 	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -392,7 +423,7 @@ func TestLogRotationTrigger(t *testing.T) {
 	defer logger.Close()
 
 	// Set very small max size to trigger rotation
-	logger.Config.MaxSize = 10
+	logger.SetMaxSize(10)
 
 	// Log enough data to trigger rotation
 	for i := 0; i < 100; i++ {
@@ -444,3 +475,5394 @@ func TestNilErrorInAllErrorMethods(t *testing.T) {
 		t.Error("Error log should contain critical message")
 	}
 }
+
+func TestMaxEntrySizeTruncation(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	logger.SetMaxEntrySize(16)
+	logger.Info(strings.Repeat("a", 10), strings.Repeat("b", 100))
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "output.log"))
+
+	if !strings.Contains(content, strings.Repeat("a", 10)) {
+		t.Error("Message within limit should be kept")
+	}
+	if !strings.Contains(content, "└── bbbbbb...truncated (94 bytes)") {
+		t.Errorf("Oversized message should be truncated with marker, got %q", content)
+	}
+}
+
+func TestConfigSetters(t *testing.T) {
+	logger, testDir := createTestLogger(t, "json")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	if err := logger.SetType("xml"); err == nil {
+		t.Error("SetType should reject unsupported type")
+	}
+	if err := logger.SetMaxSize(0); err == nil {
+		t.Error("SetMaxSize should reject non-positive size")
+	}
+
+	logger.SetType("text")
+	logger.SetMaxBackup(7)
+
+	config := logger.Config()
+	config.Type = "json"
+	if logger.Config().Type != "text" || logger.Config().MaxBackup != 7 {
+		t.Error("Config should return a copy reflecting setter changes")
+	}
+
+	logger.Info("Switched to text")
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "output.log"))
+	if strings.Contains(content, `"level"`) {
+		t.Error("SetType should switch output format at runtime")
+	}
+
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithNamedLevel("db", "ERROR"), WithMaskKeys("password"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	config = logger.Config()
+	config.Levels["db"] = "DEBUG"
+	config.MaskKeys[0] = "token"
+	if config = logger.Config(); config.Levels["db"] != "ERROR" || config.MaskKeys[0] != "password" {
+		t.Errorf("Config should copy maps and slices, got %v and %v", config.Levels, config.MaskKeys)
+	}
+}
+
+func TestControlCharacterSanitization(t *testing.T) {
+	for _, logType := range []string{"text", "json"} {
+		logger, testDir := createTestLogger(t, logType)
+
+		logger.Info("Login failed\n2024/01/01 00:00:00 [ERROR] forged", "bell\a\x1b[31m")
+		logger.Flush()
+
+		content := readLogContent(t, filepath.Join(testDir, "output.log"))
+		lines := strings.Split(strings.TrimSpace(content), "\n")
+
+		if logType == "text" && len(lines) != 2 {
+			t.Errorf("Text log should keep one line per message, got %d", len(lines))
+		}
+		if logType == "json" && len(lines) != 1 {
+			t.Errorf("JSON log should keep one line per entry, got %d", len(lines))
+		}
+		if strings.ContainsAny(content, "\a\x1b") {
+			t.Errorf("%s log should escape control characters", logType)
+		}
+
+		logger.Close()
+		os.RemoveAll(testDir)
+	}
+}
+
+func TestLevelFiltering(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	if err := logger.SetLevel("verbose"); err == nil {
+		t.Error("SetLevel should reject unknown level")
+	}
+
+	logger.SetLevel("warn")
+	logger.Info("Filtered info")
+	logger.Warn("Kept warning")
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "output.log"))
+	if strings.Contains(content, "Filtered info") {
+		t.Error("Entries below minimum level should be dropped")
+	}
+	if !strings.Contains(content, "Kept warning") {
+		t.Error("Entries at minimum level should be written")
+	}
+}
+
+func TestElevate(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	logger.SetLevel("INFO")
+
+	restore := logger.Elevate("DEBUG", time.Hour)
+	logger.Debug("Visible while elevated")
+	restore()
+	logger.Debug("Hidden after restore")
+
+	expired := logger.Elevate("DEBUG", 10*time.Millisecond)
+	defer expired()
+	time.Sleep(50 * time.Millisecond)
+	logger.Debug("Hidden after expiry")
+	logger.Flush()
+
+	debugContent := readLogContent(t, filepath.Join(testDir, "debug.log"))
+	outputContent := readLogContent(t, filepath.Join(testDir, "output.log"))
+
+	if !strings.Contains(debugContent, "Visible while elevated") {
+		t.Error("Elevated level should allow debug entries")
+	}
+	if strings.Contains(debugContent, "Hidden after") {
+		t.Error("Level should be restored after restore or expiry")
+	}
+	if strings.Count(outputContent, "Verbosity elevated to DEBUG") != 2 || strings.Count(outputContent, "Verbosity restored to INFO") != 2 {
+		t.Errorf("Elevation window should be marked by meta entries, got %q", outputContent)
+	}
+	if logger.Config().Level != "INFO" {
+		t.Errorf("Expected level INFO after restore, got %s", logger.Config().Level)
+	}
+
+	// * nested elevations keep the most verbose one until the last ends
+	outer := logger.Elevate("TRACE", time.Hour)
+	inner := logger.Elevate("DEBUG", time.Hour)
+	if level := logger.Config().Level; level != "DEBUG" {
+		t.Errorf("Expected DEBUG while both run, got %s", level)
+	}
+	inner()
+	if level := logger.Config().Level; level != "TRACE" {
+		t.Errorf("Expected the outer TRACE after the inner ends, got %s", level)
+	}
+	inner = logger.Elevate("DEBUG", time.Hour)
+	outer()
+	if level := logger.Config().Level; level != "DEBUG" {
+		t.Errorf("Expected DEBUG while the inner still runs, got %s", level)
+	}
+	inner()
+	if level := logger.Config().Level; level != "INFO" {
+		t.Errorf("Expected INFO after the last elevation, got %s", level)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	logger, testDir := createTestLogger(t, "json")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	logger.AddHook(func(entry *Entry) *Entry {
+		if strings.Contains(entry.Message, "healthcheck") {
+			return nil
+		}
+		return entry
+	})
+	logger.AddHook(func(entry *Entry) *Entry {
+		entry.Fields = append(entry.Fields, Field{Key: "service", Value: "api"})
+		return entry
+	})
+
+	logger.Info("GET /healthcheck")
+	logger.Info("GET /users")
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "output.log"))
+
+	if strings.Contains(content, "healthcheck") {
+		t.Error("Hook returning nil should drop the entry")
+	}
+	if !strings.Contains(content, `"service":"api"`) {
+		t.Error("Hook should be able to add fields")
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	logger, testDir := createTestLogger(t, "json")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/users?id=1", nil)
+	r.Header.Set("User-Agent", "test-agent")
+
+	returnedError := logger.HTTPError(r, http.StatusBadGateway, fmt.Errorf("upstream timeout"))
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "error.log"))
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &logEntry); err != nil {
+		t.Fatalf("Failed to parse JSON log: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"level":      "ERROR",
+		"msg":        "Bad Gateway",
+		"method":     "POST",
+		"path":       "/users",
+		"status":     float64(502),
+		"remote":     "192.0.2.1:1234",
+		"user_agent": "test-agent",
+		"error":      "upstream timeout",
+	}
+	for key, value := range expected {
+		if logEntry[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, logEntry[key])
+		}
+	}
+	if returnedError == nil || !strings.Contains(returnedError.Error(), "upstream timeout") {
+		t.Error("HTTPError should return composed error")
+	}
+}
+
+func TestFileLineLimit(t *testing.T) {
+	for _, logType := range []string{"text", "json"} {
+		testDir := fmt.Sprintf("./test_limit_%s_%d", logType, time.Now().UnixNano())
+		logger, err := New(&Log{Path: testDir, Type: logType, FileLineLimit: 200})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.HTTPError(nil, http.StatusInternalServerError, fmt.Errorf("db down"), strings.Repeat("x", 1000))
+		logger.Flush()
+
+		content := readLogContent(t, filepath.Join(testDir, "error.log"))
+		for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+			if len(line) > 200 {
+				t.Errorf("%s line exceeds limit: %d bytes", logType, len(line))
+			}
+		}
+		if !strings.Contains(content, "truncated") {
+			t.Errorf("%s line should be marked as truncated", logType)
+		}
+
+		if logType == "json" {
+			var logEntry map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &logEntry); err != nil {
+				t.Fatalf("Truncated JSON should stay valid: %v", err)
+			}
+			if logEntry["level"] != "ERROR" || logEntry["error"] != "db down" {
+				t.Errorf("Truncated JSON should keep key fields, got %v", logEntry)
+			}
+		}
+
+		logger.Close()
+		os.RemoveAll(testDir)
+	}
+}
+
+func TestOnWrite(t *testing.T) {
+	logger, testDir := createTestLogger(t, "json")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	var entries []Entry
+	logger.OnWrite(func(entry Entry, err error) {
+		if err != nil {
+			t.Errorf("Unexpected write error: %v", err)
+		}
+		entries = append(entries, entry)
+	})
+
+	logger.Info("First")
+	logger.Error(nil, "Second")
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 callbacks, got %d", len(entries))
+	}
+	if entries[1].Level != "ERROR" || entries[1].Message != "Second" {
+		t.Errorf("Callback should receive the written entry, got %+v", entries[1])
+	}
+}
+
+func TestCallbacksMayLog(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithTenantField("tenant"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.OnWrite(func(entry Entry, err error) {
+		if entry.Level == "CRITICAL" {
+			logger.Info("mirrored", entry.Message)
+		}
+	})
+	logger.OnInternalError(func(err error) {
+		logger.Warn("internal", err.Error())
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Critical(nil, "disk gone")
+		logger.With("tenant", "../escape").Info("bad tenant")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Callbacks logging through the same logger deadlocked")
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(output), "mirrored") || !strings.Contains(string(output), "invalid tenant") {
+		t.Errorf("Expected both callbacks to log, got %q", output)
+	}
+	if strings.Index(string(output), "mirrored") > strings.Index(string(output), "bad tenant") {
+		t.Errorf("Expected the callback to run before the next call returns, got %q", output)
+	}
+}
+
+func TestFlushFileCallbackMayLog(t *testing.T) {
+	fsys := &syncCountFS{MemFS: NewMemFS(), err: errors.New("disk gone")}
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	logger.OnInternalError(func(err error) {
+		logger.Info("internal", err.Error())
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- logger.FlushFile("output")
+	}()
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "Failed to flush output.log: disk gone" {
+			t.Errorf("Expected the sync error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("FlushFile deadlocked on a callback that logs")
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(output), "disk gone") {
+		t.Errorf("Expected the callback to log the failure, got %q", output)
+	}
+}
+
+func TestFilterRules(t *testing.T) {
+	testDir := fmt.Sprintf("./test_filter_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+
+	if _, err := New(&Log{Path: testDir, Filters: []FilterRule{{Match: "("}}}); err == nil {
+		t.Error("New should reject invalid filter regex")
+	}
+
+	logger, err := New(&Log{
+		Path: testDir,
+		Filters: []FilterRule{
+			{Match: `^GET /healthz`},
+			{Prefix: "[sarama]", MinLevel: "WARNING"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("GET /healthz 200")
+	logger.Info("[sarama] connected to broker")
+	logger.Warn("[sarama] broker unreachable")
+	logger.Info("GET /users 200")
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "output.log"))
+	if strings.Contains(content, "healthz") || strings.Contains(content, "connected to broker") {
+		t.Errorf("Matching entries should be dropped, got %q", content)
+	}
+	if !strings.Contains(content, "broker unreachable") || !strings.Contains(content, "GET /users") {
+		t.Errorf("Non-matching entries should be kept, got %q", content)
+	}
+}
+
+func TestOpenLogFileDecompression(t *testing.T) {
+	testDir := fmt.Sprintf("./test_read_%d", time.Now().UnixNano())
+	os.MkdirAll(testDir, 0755)
+	defer os.RemoveAll(testDir)
+
+	plainPath := filepath.Join(testDir, "output.log")
+	os.WriteFile(plainPath, []byte("plain line\n"), 0644)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("compressed line\n"))
+	gz.Close()
+	gzPath := filepath.Join(testDir, "output.log.20240101_000000.gz")
+	os.WriteFile(gzPath, buf.Bytes(), 0644)
+
+	for path, expected := range map[string]string{plainPath: "plain line\n", gzPath: "compressed line\n"} {
+		reader, err := openLogFile(OSFS(), path)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", path, err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+
+		if string(content) != expected {
+			t.Errorf("Expected %q from %s, got %q", expected, path, content)
+		}
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	for _, logType := range []string{"text", "json"} {
+		testDir := fmt.Sprintf("./test_redact_%s_%d", logType, time.Now().UnixNano())
+		logger, err := New(&Log{
+			Path: testDir,
+			Type: logType,
+			Redact: []RedactRule{
+				{Name: "email"},
+				{Name: "credit_card"},
+				{Name: "bearer", Replace: "Bearer ***"},
+				{Pattern: `sk_live_\w+`},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.Info("Signup john.doe@example.com", "card 4111 1111 1111 1111", "Authorization: Bearer abc.def-123", "key sk_live_42")
+		logger.Flush()
+
+		content := readLogContent(t, filepath.Join(testDir, "output.log"))
+		for _, secret := range []string{"john.doe@example.com", "4111", "abc.def-123", "sk_live_42"} {
+			if strings.Contains(content, secret) {
+				t.Errorf("%s log should redact %q", logType, secret)
+			}
+		}
+		if !strings.Contains(content, "[REDACTED]") || !strings.Contains(content, "Bearer ***") {
+			t.Errorf("%s log should contain masks, got %q", logType, content)
+		}
+
+		logger.Close()
+		os.RemoveAll(testDir)
+	}
+}
+
+func TestUnencodableFieldRecovery(t *testing.T) {
+	addChannel := func(entry *Entry) *Entry {
+		if entry.Level == "ERROR" {
+			entry.Fields = append(entry.Fields, Field{Key: "ch", Value: make(chan int)})
+		}
+		return entry
+	}
+
+	logger, testDir := createTestLogger(t, "json")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	logger.AddHook(addChannel)
+	logger.Error(nil, "Entry with channel")
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "error.log"))
+	if !strings.Contains(content, "Entry with channel") || !strings.Contains(content, `"ch":"!ERROR:`) {
+		t.Errorf("Entry should be kept with placeholder, got %q", content)
+	}
+	if !strings.Contains(content, "Failed to encode fields") {
+		t.Error("Encoding failure should produce a meta warning")
+	}
+
+	strictDir := fmt.Sprintf("./test_strict_%d", time.Now().UnixNano())
+	defer os.RemoveAll(strictDir)
+	strict, err := New(&Log{Path: strictDir, Type: "json", StrictEncoding: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer strict.Close()
+
+	strict.AddHook(addChannel)
+	returnedError := strict.Error(nil, "Strict entry")
+	if returnedError == nil || !strings.Contains(returnedError.Error(), "unsupported type") {
+		t.Errorf("Strict mode should surface encoding failure, got %v", returnedError)
+	}
+}
+
+func TestMaskKeys(t *testing.T) {
+	testDir := fmt.Sprintf("./test_mask_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	logger, err := New(&Log{Path: testDir, Type: "json", MaskKeys: []string{"password", "Authorization"}})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.AddHook(func(entry *Entry) *Entry {
+		entry.Fields = append(entry.Fields,
+			Field{Key: "Password", Value: "hunter2"},
+			Field{Key: "headers", Value: map[string]string{"authorization": "Bearer xyz", "accept": "json"}},
+		)
+		return entry
+	})
+	logger.Info("Login")
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "output.log"))
+	if strings.Contains(content, "hunter2") || strings.Contains(content, "Bearer xyz") {
+		t.Errorf("Masked keys should not leak values, got %q", content)
+	}
+	if !strings.Contains(content, `"Password":"***"`) || !strings.Contains(content, `"accept":"json"`) {
+		t.Errorf("Masked keys should be replaced and others kept, got %q", content)
+	}
+}
+
+func TestWarmStandbyRotation(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+
+	standbyPath := filepath.Join(testDir, ".output.log.next")
+	if _, err := os.Stat(standbyPath); err != nil {
+		t.Fatalf("Standby file should be pre-created: %v", err)
+	}
+
+	logger.Info(strings.Repeat("x", 2048))
+	logger.Mutex.Lock()
+	standby := logger.standby["output.log"]
+	err := logger.checkAndRotate("output.log")
+	swapped := logger.File["output.log"] == standby
+	logger.Mutex.Unlock()
+	if err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	if !swapped {
+		t.Error("Rotation should swap in the standby file")
+	}
+
+	logger.Info("After rotation")
+	logger.Flush()
+
+	content := readLogContent(t, filepath.Join(testDir, "output.log"))
+	if strings.TrimSpace(content) == "" || strings.Contains(content, "xxxx") {
+		t.Errorf("New entries should go to the fresh file, got %q", content)
+	}
+
+	logger.Close()
+	if _, err := os.Stat(standbyPath); !os.IsNotExist(err) {
+		t.Error("Standby file should be removed on close")
+	}
+}
+
+func TestFlushFileAndLevel(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	logger.Error(nil, "Before 500")
+	if err := logger.FlushLevel("error"); err != nil {
+		t.Errorf("FlushLevel should succeed: %v", err)
+	}
+	if err := logger.FlushFile("debug"); err != nil {
+		t.Errorf("FlushFile should accept name without extension: %v", err)
+	}
+	if err := logger.FlushFile("missing.log"); err == nil || err.Error() != "Failed to flush missing.log: unknown file" {
+		t.Errorf("FlushFile should reject unknown file, got %v", err)
+	}
+	if err := logger.FlushLevel("loud"); err == nil {
+		t.Error("FlushLevel should reject unknown level")
+	}
+}
+
+func TestHMACSignAndVerify(t *testing.T) {
+	for _, logType := range []string{"text", "json"} {
+		testDir := fmt.Sprintf("./test_hmac_%s_%d", logType, time.Now().UnixNano())
+		logger, err := New(&Log{Path: testDir, Type: logType, HMACKey: "secret"})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.Info("Transfer approved", "amount=100", "to=alice")
+		logger.Info("Transfer approved", "amount=5")
+		logger.Close()
+
+		path := filepath.Join(testDir, "output.log")
+		if err := Verify(path, "secret"); err != nil {
+			t.Errorf("%s log should verify: %v", logType, err)
+		}
+		if err := Verify(path, "wrong"); err == nil {
+			t.Errorf("%s log should fail with wrong key", logType)
+		}
+
+		content := readLogContent(t, path)
+		os.WriteFile(path, []byte(strings.Replace(content, "amount=5", "amount=9", 1)), 0644)
+		if err := Verify(path, "secret"); err == nil {
+			t.Errorf("%s log should detect tampering", logType)
+		}
+
+		os.RemoveAll(testDir)
+	}
+}
+
+func TestAuditHashChain(t *testing.T) {
+	for _, logType := range []string{"text", "json"} {
+		testDir := fmt.Sprintf("./test_audit_%s_%d", logType, time.Now().UnixNano())
+		config := &Log{Path: testDir, Type: logType, Audit: true, HMACKey: "secret"}
+
+		logger, err := New(config)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		logger.Info("Record 1", "detail")
+		logger.Info("Record 2")
+		logger.Close()
+
+		// * reopen to make sure the chain resumes
+		logger, _ = New(config)
+		logger.Info("Record 3")
+		logger.Close()
+
+		path := filepath.Join(testDir, "output.log")
+		if err := VerifyChain(path); err != nil {
+			t.Errorf("%s chain should verify: %v", logType, err)
+		}
+		if err := Verify(path, "secret"); err != nil {
+			t.Errorf("%s signatures should verify alongside chain: %v", logType, err)
+		}
+
+		content := readLogContent(t, path)
+		lines := strings.SplitAfter(content, "\n")
+		os.WriteFile(path, []byte(strings.Join(append(lines[:1], lines[2:]...), "")), 0644)
+		if err := VerifyChain(path); err == nil {
+			t.Errorf("%s chain should detect removed lines", logType)
+		}
+
+		os.RemoveAll(testDir)
+	}
+}
+
+func TestMetaFile(t *testing.T) {
+	logger, testDir := createTestLogger(t, "json")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	readMeta := func() map[string]interface{} {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(readLogContent(t, filepath.Join(testDir, "logger-meta.json"))), &m); err != nil {
+			t.Fatalf("Failed to parse meta: %v", err)
+		}
+		return m
+	}
+
+	m := readMeta()
+	if m["format"] != "json" || m["schema_version"] != float64(1) {
+		t.Errorf("Meta should describe active format, got %v", m)
+	}
+
+	logger.SetType("text")
+	logger.SetMaxBackup(9)
+
+	m = readMeta()
+	rotation := m["rotation"].(map[string]interface{})
+	if m["format"] != "text" || rotation["max_backup"] != float64(9) {
+		t.Errorf("Meta should follow config changes, got %v", m)
+	}
+}
+
+func TestRotationChecksum(t *testing.T) {
+	testDir := fmt.Sprintf("./test_checksum_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	logger, err := New(&Log{Path: testDir, MaxSize: 10, Checksum: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Enough content to exceed max size")
+	logger.Mutex.Lock()
+	logger.checkAndRotate("output.log")
+	logger.Mutex.Unlock()
+
+	sums, _ := filepath.Glob(filepath.Join(testDir, "output.log.*.sha256"))
+	if len(sums) != 1 {
+		t.Fatalf("Expected one checksum file, got %v", sums)
+	}
+
+	backup := strings.TrimSuffix(sums[0], ".sha256")
+	data, _ := os.ReadFile(backup)
+	sum := sha256.Sum256(data)
+	expected := fmt.Sprintf("%x  %s\n", sum, filepath.Base(backup))
+	if content := readLogContent(t, sums[0]); content != expected {
+		t.Errorf("Expected checksum %q, got %q", expected, content)
+	}
+}
+
+func TestAppendOnlyCleanup(t *testing.T) {
+	for _, appendOnly := range []bool{true, false} {
+		testDir := fmt.Sprintf("./test_worm_%t_%d", appendOnly, time.Now().UnixNano())
+		logger, err := New(&Log{Path: testDir, MaxBackup: 1, MaxAge: time.Hour, AppendOnly: appendOnly})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		old := time.Now().Add(-2 * time.Hour)
+		for _, suffix := range []string{"20240101_000000", "20240102_000000", "20240103_000000"} {
+			path := filepath.Join(testDir, "output.log."+suffix)
+			os.WriteFile(path, []byte("record\n"), 0644)
+			os.Chtimes(path, old, old)
+		}
+
+		var exported []string
+		logger.OnExpire(func(path string) {
+			exported = append(exported, path)
+		})
+
+		path := filepath.Join(testDir, "output.log")
+		logger.Cleanup(path)
+		logger.Cleanup(path)
+
+		backups, _ := filepath.Glob(path + ".2024*")
+		if appendOnly && (len(backups) != 3 || len(exported) != 3) {
+			t.Errorf("Append-only mode should keep all backups and export each once, got %d kept %d exported", len(backups), len(exported))
+		}
+		if !appendOnly && (len(backups) != 0 || len(exported) != 0) {
+			t.Errorf("Expired backups should be removed, got %d kept %d exported", len(backups), len(exported))
+		}
+
+		logger.Close()
+		os.RemoveAll(testDir)
+	}
+}
+
+func TestManualRotate(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	logger.Error(nil, "Before maintenance")
+	if err := logger.Rotate("error"); err != nil {
+		t.Fatalf("Rotate should succeed: %v", err)
+	}
+	if err := logger.Rotate("missing.log"); err == nil {
+		t.Error("Rotate should reject unknown file")
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(testDir, "error.log.*"))
+	if len(backups) != 1 || !strings.Contains(readLogContent(t, backups[0]), "Before maintenance") {
+		t.Errorf("Rotate should move current content to a backup, got %v", backups)
+	}
+
+	logger.Error(nil, "After maintenance")
+	logger.Flush()
+	if content := readLogContent(t, filepath.Join(testDir, "error.log")); strings.Contains(content, "Before maintenance") {
+		t.Error("Live file should start empty after Rotate")
+	}
+
+	if err := logger.RotateAll(); err != nil {
+		t.Fatalf("RotateAll should succeed: %v", err)
+	}
+	for _, name := range []string{"debug.log", "output.log", "error.log"} {
+		if backups, _ := filepath.Glob(filepath.Join(testDir, name+".*")); len(backups) == 0 {
+			t.Errorf("RotateAll should rotate %s", name)
+		}
+	}
+}
+
+func TestOnRotate(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	var oldPath, newPath string
+	logger.OnRotate(func(from, to string) {
+		oldPath, newPath = from, to
+	})
+
+	logger.Info("Rotated content")
+	logger.Rotate("output.log")
+
+	if oldPath != filepath.Join(testDir, "output.log") {
+		t.Errorf("Expected old path of live file, got %q", oldPath)
+	}
+	if !strings.Contains(readLogContent(t, newPath), "Rotated content") {
+		t.Errorf("New path should point at the produced backup, got %q", newPath)
+	}
+}
+
+func TestS3Archiver(t *testing.T) {
+	var mu sync.Mutex
+	uploads := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if r.Method != http.MethodPut || !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		uploads[r.URL.Path] = string(body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	testDir := fmt.Sprintf("./test_archive_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	logger, err := New(&Log{
+		Path: testDir,
+		Archiver: &S3Archiver{
+			Bucket:    "logs",
+			Region:    "us-east-1",
+			Prefix:    "api/",
+			AccessKey: "AKID",
+			SecretKey: "secret",
+			Endpoint:  server.URL,
+		},
+		DeleteArchived: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Info("Archived content")
+	logger.Rotate("output.log")
+	logger.Close()
+
+	if len(uploads) != 1 {
+		t.Fatalf("Expected one upload, got %v", uploads)
+	}
+	for path, body := range uploads {
+		if !strings.HasPrefix(path, "/logs/api/output.log.") || !strings.Contains(body, "Archived content") {
+			t.Errorf("Unexpected upload %s: %q", path, body)
+		}
+	}
+	if backups, _ := filepath.Glob(filepath.Join(testDir, "output.log.*")); len(backups) != 0 {
+		t.Errorf("Archived backup should be deleted locally, got %v", backups)
+	}
+}
+
+func TestReopenAfterExternalRotation(t *testing.T) {
+	testDir := fmt.Sprintf("./test_reopen_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	logger, err := New(&Log{Path: testDir, ReopenOnSIGHUP: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	path := filepath.Join(testDir, "output.log")
+	logger.Info("Before logrotate")
+	os.Rename(path, path+".1")
+
+	logger.Info("Still old inode")
+	if err := logger.Reopen(); err != nil {
+		t.Fatalf("Reopen should succeed: %v", err)
+	}
+	logger.Info("After reopen")
+
+	process, _ := os.FindProcess(os.Getpid())
+	process.Signal(syscall.SIGHUP)
+	time.Sleep(100 * time.Millisecond)
+	logger.Info("After SIGHUP")
+	logger.Flush()
+
+	moved := readLogContent(t, path+".1")
+	current := readLogContent(t, path)
+	if !strings.Contains(moved, "Still old inode") || strings.Contains(moved, "After reopen") {
+		t.Errorf("Moved file should only hold entries before reopen, got %q", moved)
+	}
+	if !strings.Contains(current, "After reopen") || !strings.Contains(current, "After SIGHUP") {
+		t.Errorf("Reopened file should receive new entries, got %q", current)
+	}
+}
+
+func TestBackupFormats(t *testing.T) {
+	cases := map[string]*regexp.Regexp{
+		"timestamp":     regexp.MustCompile(`^output\.log\.\d{8}_\d{6}(-\d+)?$`),
+		"millis":        regexp.MustCompile(`^output\.log\.\d{8}_\d{6}\.\d{3}(-\d+)?$`),
+		"sequence":      regexp.MustCompile(`^output\.log\.[123]$`),
+		"2006-01-02T15": regexp.MustCompile(`^output\.log\.\d{4}-\d{2}-\d{2}T\d{2}(-\d+)?$`),
+	}
+
+	for format, expected := range cases {
+		testDir := fmt.Sprintf("./test_backup_%d", time.Now().UnixNano())
+		logger, err := New(&Log{Path: testDir, BackupFormat: format, MaxBackup: 10})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		for i := 0; i < 3; i++ {
+			logger.Info(fmt.Sprintf("Entry %d", i))
+			logger.Rotate("output.log")
+		}
+		logger.Close()
+
+		backups, _ := filepath.Glob(filepath.Join(testDir, "output.log.*"))
+		if len(backups) != 3 {
+			t.Errorf("%s: rotating within the same second should keep 3 backups, got %v", format, backups)
+		}
+		for _, backup := range backups {
+			if !expected.MatchString(filepath.Base(backup)) {
+				t.Errorf("%s: unexpected backup name %s", format, backup)
+			}
+		}
+
+		os.RemoveAll(testDir)
+	}
+}
+
+func TestDatedFiles(t *testing.T) {
+	testDir := fmt.Sprintf("./test_dated_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	logger, err := New(&Log{Path: testDir, DatedFiles: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	today := time.Now().Format("2006-01-02")
+	link := filepath.Join(testDir, "output.log")
+	if target, err := os.Readlink(link); err != nil || target != "output-"+today+".log" {
+		t.Fatalf("output.log should link to dated file, got %q %v", target, err)
+	}
+
+	logger.Info("First file")
+	var backup string
+	logger.OnRotate(func(oldPath, newPath string) {
+		backup = newPath
+	})
+	logger.Rotate("output.log")
+	logger.Info("Second file")
+	logger.Flush()
+
+	if filepath.Base(backup) != "output-"+today+".log" || !strings.Contains(readLogContent(t, backup), "First file") {
+		t.Errorf("Rotation should finish the dated file in place, got %q", backup)
+	}
+	if target, _ := os.Readlink(link); target != "output-"+today+".1.log" {
+		t.Errorf("output.log should follow the new dated file, got %q", target)
+	}
+	if content := readLogContent(t, link); !strings.Contains(content, "Second file") || strings.Contains(content, "First file") {
+		t.Errorf("Reading the link should return the active file, got %q", content)
+	}
+}
+
+func TestMaxTotalSize(t *testing.T) {
+	testDir := fmt.Sprintf("./test_quota_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	logger, err := New(&Log{Path: testDir, MaxBackup: 100, MaxTotalSize: 4096, BackupFormat: "sequence"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	for i := 0; i < 10; i++ {
+		logger.Info(strings.Repeat("x", 1000))
+		logger.Rotate("output.log")
+	}
+
+	var total int64
+	entries, _ := os.ReadDir(testDir)
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+	}
+	if total > 4096 {
+		t.Errorf("Directory should stay within quota, got %d bytes", total)
+	}
+	if _, err := os.Stat(filepath.Join(testDir, "output.log.10")); err != nil {
+		t.Error("Newest backup should be kept")
+	}
+	if _, err := os.Stat(filepath.Join(testDir, "output.log.1")); !os.IsNotExist(err) {
+		t.Error("Oldest backup should be removed first")
+	}
+}
+
+func TestMaxTotalSizeTenants(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithTenantField("tenant_id"), WithMaxTenants(1),
+		WithMaxBackup(100), WithConfig(func(c *Log) { c.MaxTotalSize = 8192; c.BackupFormat = "sequence" }))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	// * acme fills its directory, then is evicted when globex starts writing
+	for i := 0; i < 6; i++ {
+		logger.Tenant("acme").Info(strings.Repeat("a", 1000))
+		logger.Rotate("acme/output")
+	}
+	for i := 0; i < 6; i++ {
+		logger.Tenant("globex").Info(strings.Repeat("g", 1000))
+		logger.Rotate("globex/output")
+	}
+
+	var total int64
+	for _, dir := range []string{"logs", "logs/acme", "logs/globex"} {
+		items, _ := fsys.ReadDir(dir)
+		for _, item := range items {
+			if info, err := item.Info(); err == nil && info.Mode().IsRegular() {
+				total += info.Size()
+			}
+		}
+	}
+	if total > 8192 {
+		t.Errorf("Expected one budget across all tenants, got %d bytes", total)
+	}
+	if _, err := fsys.Stat("logs/acme/output.log.1"); err == nil {
+		t.Error("Expected the evicted tenant's oldest backups to go first")
+	}
+	if _, err := fsys.Stat("logs/globex/output.log.6"); err != nil {
+		t.Errorf("Expected the newest backup to be kept, got %v", err)
+	}
+}
+
+func TestOlderBackup(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	backups := []backupFile{
+		{path: "logs/output.log.10", base: "output.log", modTime: at},
+		{path: "logs/db.log.2", base: "db.log", modTime: at},
+		{path: "logs/output.log.9", base: "output.log", modTime: at},
+		{path: "logs/output.log.1", base: "output.log", modTime: at.Add(-time.Second)},
+		{path: "logs/db.log.10", base: "db.log", modTime: at},
+	}
+	slices.SortFunc(backups, func(a, b backupFile) int {
+		if olderBackup(a, b) {
+			return -1
+		}
+		if olderBackup(b, a) {
+			return 1
+		}
+		return 0
+	})
+
+	var order []string
+	for _, backup := range backups {
+		order = append(order, filepath.Base(backup.path))
+	}
+	// * suffixes only break ties within one base name, never across files
+	expected := []string{"output.log.1", "db.log.2", "db.log.10", "output.log.9", "output.log.10"}
+	if !slices.Equal(order, expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+}
+
+func TestMaxAgeClock(t *testing.T) {
+	testDir := t.TempDir()
+	// * the clock runs two days ahead, backups written now are already expired
+	clock := ClockFunc(func() time.Time { return time.Now().Add(48 * time.Hour) })
+	logger, err := New(&Log{Path: testDir, MaxAge: 24 * time.Hour, Clock: clock})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("first")
+	logger.Rotate("output.log")
+	logger.Info("second")
+	logger.Rotate("output.log")
+
+	if backups, _ := filepath.Glob(filepath.Join(testDir, "output.log.*")); len(backups) != 0 {
+		t.Errorf("MaxAge should follow the logger's clock, got %v", backups)
+	}
+}
+
+func TestFallbackWriter(t *testing.T) {
+	var fallback bytes.Buffer
+	testDir := fmt.Sprintf("./test_fallback_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	logger, err := New(&Log{Path: testDir, Fallback: &fallback})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	var writeErr error
+	logger.OnWrite(func(entry Entry, err error) {
+		writeErr = err
+	})
+
+	// * simulate a lost file descriptor
+	logger.File["error.log"].Close()
+	logger.Error(nil, "Disk is gone")
+
+	if writeErr == nil {
+		t.Error("OnWrite should receive the write failure")
+	}
+	if !strings.Contains(fallback.String(), "Disk is gone") {
+		t.Errorf("Entry should be written to fallback, got %q", fallback.String())
+	}
+}
+
+func TestOnInternalError(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	var errs []error
+	logger.OnInternalError(func(err error) {
+		errs = append(errs, err)
+	})
+
+	logger.File["debug.log"].Close()
+	if err := logger.Flush(); err == nil {
+		t.Error("Flush should fail on a closed file")
+	}
+	logger.Debug("Lost write")
+
+	if len(errs) != 2 {
+		t.Fatalf("Expected sync and write failures to be reported, got %v", errs)
+	}
+	if !strings.Contains(errs[1].Error(), "debug.log") {
+		t.Errorf("Write failure should name the file, got %v", errs[1])
+	}
+}
+
+func TestStats(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	logger.AddHook(func(entry *Entry) *Entry {
+		if entry.Message == "drop me" {
+			return nil
+		}
+		return entry
+	})
+	logger.SetLevel("INFO")
+
+	logger.Debug("Suppressed")
+	logger.Info("drop me")
+	logger.Info("Kept 1")
+	logger.Info("Kept 2")
+	logger.Error(nil, "Kept 3")
+	logger.Rotate("output.log")
+
+	stats := logger.Stats()
+	if stats.Entries["INFO"] != 2 || stats.Entries["ERROR"] != 1 {
+		t.Errorf("Unexpected entry counts %v", stats.Entries)
+	}
+	if stats.Bytes["output.log"] == 0 || stats.Bytes["debug.log"] != 0 {
+		t.Errorf("Unexpected byte counts %v", stats.Bytes)
+	}
+	if stats.Suppressed != 1 || stats.Dropped != 1 {
+		t.Errorf("Expected 1 suppressed and 1 dropped, got %d and %d", stats.Suppressed, stats.Dropped)
+	}
+	if stats.LastRotation.IsZero() {
+		t.Error("Last rotation time should be recorded")
+	}
+}
+
+func TestStatsQueueDepth(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := probe.Addr().String()
+	probe.Close()
+
+	sink := &NetworkSink{Addr: addr, Retry: &RetryPolicy{MaxAttempts: 1}, Interval: time.Hour}
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(sink))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.OnInternalError(func(error) {})
+
+	if depth := logger.Stats().QueueDepth; depth != 0 {
+		t.Errorf("Expected an empty queue, got %d", depth)
+	}
+	logger.Info("first")
+	logger.Info("second")
+	logger.Info("third")
+	if depth := logger.Stats().QueueDepth; depth != 3 {
+		t.Errorf("Expected 3 entries waiting for the sink, got %d", depth)
+	}
+	logger.Close()
+}
+
+func TestExpvarPublication(t *testing.T) {
+	testDir := fmt.Sprintf("./test_expvar_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	logger, err := New(&Log{Path: testDir, Expvar: "testLogger"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Error(nil, "Counted")
+	logger.Error(nil, "Counted")
+
+	if value := expvar.Get("testLogger.error.count"); value == nil || value.String() != "2" {
+		t.Errorf("Expected error count 2, got %v", value)
+	}
+	if value := expvar.Get("testLogger.error.bytes"); value == nil || value.String() == "0" {
+		t.Errorf("Expected error bytes to be published, got %v", value)
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	server := httptest.NewServer(http.StripPrefix("/admin", logger.AdminHandler()))
+	defer server.Close()
+
+	request := func(method, path string) (int, string) {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	logger.Info("Counted")
+	if status, body := request(http.MethodGet, "/admin/stats"); status != http.StatusOK || !strings.Contains(body, `"INFO":1`) {
+		t.Errorf("Stats endpoint returned %d %s", status, body)
+	}
+	if status, _ := request(http.MethodPut, "/admin/level?level=error"); status != http.StatusOK || logger.Config().Level != "ERROR" {
+		t.Errorf("Level endpoint should change level, got %d %s", status, logger.Config().Level)
+	}
+	if status, _ := request(http.MethodPut, "/admin/level?level=loud"); status != http.StatusBadRequest {
+		t.Errorf("Level endpoint should reject unknown level, got %d", status)
+	}
+	if status, body := request(http.MethodPut, "/admin/level?level=critical&duration=1m"); status != http.StatusBadRequest || !strings.Contains(body, "not more verbose") || logger.Config().Level != "ERROR" {
+		t.Errorf("Timed change to a quieter level should be refused, got %d %s", status, body)
+	}
+	if status, _ := request(http.MethodPut, "/admin/level?level=debug&duration=1m"); status != http.StatusOK || logger.Config().Level != "DEBUG" {
+		t.Errorf("Timed change to a more verbose level should apply, got %d %s", status, logger.Config().Level)
+	}
+	if status, _ := request(http.MethodPost, "/admin/rotate?file=output.log"); status != http.StatusOK {
+		t.Errorf("Rotate endpoint returned %d", status)
+	}
+	if status, _ := request(http.MethodPost, "/admin/flush?level=error"); status != http.StatusOK {
+		t.Errorf("Flush endpoint returned %d", status)
+	}
+	if backups, _ := filepath.Glob(filepath.Join(testDir, "output.log.*")); len(backups) != 1 {
+		t.Errorf("Rotate endpoint should produce a backup, got %v", backups)
+	}
+}
+
+func TestTailHandler(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	server := httptest.NewServer(logger.TailHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?level=error")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Expected event stream, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	logger.Info("Not streamed")
+	logger.Error(nil, "Streamed failure", "with detail")
+
+	buf := make([]byte, 4096)
+	var received string
+	for !strings.Contains(received, "with detail") {
+		n, err := resp.Body.Read(buf)
+		if err != nil {
+			t.Fatalf("Stream ended early: %v (%q)", err, received)
+		}
+		received += string(buf[:n])
+	}
+
+	if strings.Contains(received, "Not streamed") {
+		t.Error("Level filter should skip INFO entries")
+	}
+	if !strings.Contains(received, "event: ERROR\ndata: ") || !strings.Contains(received, "Streamed failure") {
+		t.Errorf("Unexpected event format: %q", received)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	logger, testDir := createTestLogger(t, "json")
+	defer os.RemoveAll(testDir)
+
+	warnings, cancelWarnings := logger.Subscribe("error", "warn")
+	all, _ := logger.Subscribe()
+
+	logger.Info("Everyone")
+	logger.Warn("Careful", "detail")
+
+	select {
+	case entry := <-warnings:
+		if entry.Level != "WARNING" || entry.Message != "Careful" || len(entry.Data) != 1 {
+			t.Errorf("Unexpected entry: %+v", entry)
+		}
+	default:
+		t.Error("Level subscriber should receive WARNING entry")
+	}
+	if len(all) != 2 {
+		t.Errorf("Unfiltered subscriber should receive 2 entries, got %d", len(all))
+	}
+
+	cancelWarnings()
+	cancelWarnings()
+	if _, ok := <-warnings; ok {
+		t.Error("Channel should be closed after cancel")
+	}
+
+	logger.Close()
+	<-all
+	<-all
+	if _, ok := <-all; ok {
+		t.Error("Channel should be closed after Close")
+	}
+
+	invalid, _ := logger.Subscribe("loud")
+	if _, ok := <-invalid; ok {
+		t.Error("Unknown level should yield closed channel")
+	}
+}
+
+func TestRecent(t *testing.T) {
+	testDir := fmt.Sprintf("./test_recent_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+
+	logger, err := New(&Log{Path: testDir, RecentSize: 3})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("first")
+	logger.Warn("second")
+	logger.Info("third")
+	logger.Warn("fourth")
+	logger.Info("fifth")
+
+	recent := logger.Recent(0, "")
+	if len(recent) != 3 || recent[0].Message != "third" || recent[2].Message != "fifth" {
+		t.Errorf("Expected last 3 entries oldest first, got %+v", recent)
+	}
+	if last := logger.Recent(1, ""); len(last) != 1 || last[0].Message != "fifth" {
+		t.Errorf("Expected newest entry, got %+v", last)
+	}
+	if warnings := logger.Recent(5, "warn"); len(warnings) != 1 || warnings[0].Message != "fourth" {
+		t.Errorf("Expected only buffered WARNING entry, got %+v", warnings)
+	}
+
+	disabled, disabledDir := createTestLogger(t, "text")
+	defer os.RemoveAll(disabledDir)
+	defer disabled.Close()
+	disabled.Info("not kept")
+	if len(disabled.Recent(10, "")) != 0 {
+		t.Error("Recent should be empty when RecentSize is 0")
+	}
+}
+
+func TestReader(t *testing.T) {
+	for _, logType := range []string{"text", "json"} {
+		t.Run(logType, func(t *testing.T) {
+			testDir := fmt.Sprintf("./test_reader_%s_%d", logType, time.Now().UnixNano())
+			defer os.RemoveAll(testDir)
+
+			logger, err := New(&Log{Path: testDir, Type: logType, Audit: true, HMACKey: "secret"})
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			logger.AddHook(func(entry *Entry) *Entry {
+				if entry.Level == "NOTICE" {
+					entry.Fields = append(entry.Fields, Field{Key: "user", Value: "alice"})
+				}
+				return entry
+			})
+
+			logger.Info("first", "detail")
+			logger.Rotate("output.log")
+			logger.Notice("second")
+			logger.Info("third", "a", "b")
+			logger.Close()
+
+			backups, _ := filepath.Glob(filepath.Join(testDir, "output.log.*"))
+			if len(backups) != 1 {
+				t.Fatalf("Expected 1 backup, got %v", backups)
+			}
+			raw, _ := os.ReadFile(backups[0])
+			var compressed bytes.Buffer
+			gz := gzip.NewWriter(&compressed)
+			gz.Write(raw)
+			gz.Close()
+			os.WriteFile(backups[0]+".gz", compressed.Bytes(), 0644)
+
+			reader := NewReader(backups[0]+".gz", filepath.Join(testDir, "output.log"))
+			defer reader.Close()
+
+			var entries []Entry
+			for reader.Next() {
+				entries = append(entries, reader.Entry())
+			}
+			if err := reader.Err(); err != nil {
+				t.Fatalf("Reader failed: %v", err)
+			}
+
+			if len(entries) != 3 {
+				t.Fatalf("Expected 3 entries, got %d: %+v", len(entries), entries)
+			}
+			if entries[0].Level != "INFO" || entries[0].Message != "first" || len(entries[0].Data) != 1 || entries[0].Data[0] != "detail" {
+				t.Errorf("Unexpected first entry: %+v", entries[0])
+			}
+			if entries[1].Level != "NOTICE" || entries[1].Message != "second" || entries[1].Time.IsZero() {
+				t.Errorf("Unexpected second entry: %+v", entries[1])
+			}
+			if entries[2].Level != "INFO" || len(entries[2].Data) != 2 || entries[2].Data[1] != "b" {
+				t.Errorf("Unexpected third entry: %+v", entries[2])
+			}
+			if logType == "json" && (len(entries[1].Fields) != 1 || entries[1].Fields[0].Value != "alice") {
+				t.Errorf("Expected user field, got %+v", entries[1].Fields)
+			}
+			if logType == "text" && (len(entries[1].Data) != 1 || entries[1].Data[0] != "user=alice") {
+				t.Errorf("Expected user branch, got %+v", entries[1].Data)
+			}
+		})
+	}
+}
+
+func TestRegisterDecompression(t *testing.T) {
+	testDir := t.TempDir()
+	plain := []byte("2025/06/01 12:00:00 [INFO] first\n2025/06/01 12:00:01 [INFO] second\n")
+
+	zstdPath := filepath.Join(testDir, "output.log.20250601_120000.zst")
+	os.WriteFile(zstdPath, append([]byte{0x28, 0xb5, 0x2f, 0xfd}, plain...), 0644)
+	reader := NewReader(zstdPath)
+	if reader.Next() || reader.Err() == nil || !strings.Contains(reader.Err().Error(), "RegisterDecompression") {
+		t.Errorf("Expected unregistered zstd to fail, got %v", reader.Err())
+	}
+	reader.Close()
+
+	// * a made-up format: four magic bytes then a flate stream
+	magic := []byte("GLZ1")
+	RegisterCompression("test-flate", func(w io.Writer) (io.WriteCloser, error) {
+		if _, err := w.Write(magic); err != nil {
+			return nil, err
+		}
+		return flate.NewWriter(w, flate.BestSpeed)
+	})
+	RegisterDecompression(magic, func(r io.Reader) (io.ReadCloser, error) {
+		head := make([]byte, len(magic))
+		if _, err := io.ReadFull(r, head); err != nil {
+			return nil, err
+		}
+		return flate.NewReader(r), nil
+	})
+
+	compressed, err := compress("test-flate", plain)
+	if err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	path := filepath.Join(testDir, "output.log.20250601_120000.glz")
+	os.WriteFile(path, compressed, 0644)
+
+	reader = NewReader(path)
+	defer reader.Close()
+	var messages []string
+	for reader.Next() {
+		messages = append(messages, reader.Entry().Message)
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("Reader failed: %v", err)
+	}
+	if !slices.Equal(messages, []string{"first", "second"}) {
+		t.Errorf("Expected both entries after decompression, got %v", messages)
+	}
+}
+
+func TestEncodeEntryRoundTrip(t *testing.T) {
+	entry := Entry{
+		Time:    time.Date(2025, 6, 1, 12, 0, 0, 123456000, time.Local),
+		Level:   "WARNING",
+		Message: "disk almost full",
+		Data:    []string{"92%"},
+		Fields:  []Field{{Key: "mount", Value: "/var"}},
+	}
+
+	for _, logType := range []string{"text", "json"} {
+		reader := NewStreamReader(bytes.NewReader(EncodeEntry(entry, logType)))
+		if !reader.Next() {
+			t.Fatalf("%s: expected entry, got error %v", logType, reader.Err())
+		}
+		decoded := reader.Entry()
+		if !decoded.Time.Equal(entry.Time) || decoded.Level != entry.Level || decoded.Message != entry.Message || decoded.Data[0] != "92%" {
+			t.Errorf("%s: round trip mismatch: %+v", logType, decoded)
+		}
+		if reader.Next() {
+			t.Errorf("%s: expected single entry", logType)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	logger, testDir := createTestLogger(t, "json")
+	defer os.RemoveAll(testDir)
+	defer logger.Close()
+
+	logger.Info("request served")
+	logger.Error(nil, "database timeout", "retrying")
+	logger.RotateAll()
+	start := time.Now()
+	logger.Warn("database slow")
+	logger.Error(nil, "payment failed")
+	logger.Debug("database query")
+
+	entries, err := logger.Query(QueryOptions{Level: "warn", Contains: "database"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "database timeout" || entries[1].Message != "database slow" {
+		t.Errorf("Expected WARNING+ database entries across backups, got %+v", entries)
+	}
+
+	entries, _ = logger.Query(QueryOptions{From: start})
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 entries after rotation, got %+v", entries)
+	}
+
+	entries, _ = Query(testDir, QueryOptions{Level: "ERROR", Limit: 1})
+	if len(entries) != 1 || entries[0].Message != "payment failed" {
+		t.Errorf("Limit should keep the newest entry, got %+v", entries)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	logger, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+
+	logger.AddHook(func(entry *Entry) *Entry {
+		entry.Fields = append(entry.Fields, Field{Key: "user", Value: "alice"})
+		return entry
+	})
+	logger.Warn("quota exceeded", "98%")
+	logger.RotateAll()
+	logger.Info("after rotation")
+	logger.Close()
+
+	before, _ := Query(testDir, QueryOptions{})
+	backups, _ := filepath.Glob(filepath.Join(testDir, "output.log.*"))
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, got %v", backups)
+	}
+	info, _ := os.Stat(backups[0])
+
+	if err := Migrate(testDir, "json"); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	content := readLogContent(t, backups[0])
+	if !strings.HasPrefix(content, "{") || !strings.Contains(content, `"user":"alice"`) || !strings.Contains(content, `"msg1":"98%"`) {
+		t.Errorf("Backup should be JSON with lifted fields, got %q", content)
+	}
+	if migrated, _ := os.Stat(backups[0]); !migrated.ModTime().Equal(info.ModTime()) {
+		t.Error("Migrate should keep modification time for rotation order")
+	}
+
+	after, _ := Query(testDir, QueryOptions{})
+	if len(after) != len(before) {
+		t.Fatalf("Expected %d entries after migration, got %d", len(before), len(after))
+	}
+	for i := range before {
+		if !after[i].Time.Equal(before[i].Time) || after[i].Level != before[i].Level || after[i].Message != before[i].Message {
+			t.Errorf("Entry %d changed: %+v -> %+v", i, before[i], after[i])
+		}
+	}
+
+	if err := Migrate(testDir, "text"); err != nil {
+		t.Fatalf("Migrate back failed: %v", err)
+	}
+	if content := readLogContent(t, backups[0]); !strings.Contains(content, "[WARNING] quota exceeded") || !strings.Contains(content, "└── user=alice") {
+		t.Errorf("Expected text output, got %q", content)
+	}
+}
+
+func TestNewFromFile(t *testing.T) {
+	testDir := fmt.Sprintf("./test_config_%d", time.Now().UnixNano())
+	os.MkdirAll(testDir, 0755)
+	defer os.RemoveAll(testDir)
+
+	yamlPath := filepath.Join(testDir, "logger.yaml")
+	os.WriteFile(yamlPath, []byte(`# logging for the api service
+path: `+testDir+`/logs
+type: json
+level: warn    # WARNING and above
+max_size: 1_048_576
+max_age: 72h
+mask_keys: [password, "token"]
+filters:
+  - prefix: healthcheck
+  - match: "^GET /metrics"
+    min_level: ERROR
+redact:
+- name: email
+sampling:
+  initial: 10
+  tick: 2s
+`), 0644)
+
+	config, err := LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML: %v", err)
+	}
+	if config.Type != "json" || config.Level != "warn" || config.MaxSize != 1048576 || config.MaxAge != 72*time.Hour {
+		t.Errorf("Unexpected scalar values: %+v", config)
+	}
+	if len(config.MaskKeys) != 2 || config.MaskKeys[1] != "token" {
+		t.Errorf("Unexpected mask keys: %v", config.MaskKeys)
+	}
+	if len(config.Filters) != 2 || config.Filters[0].Prefix != "healthcheck" || config.Filters[1].MinLevel != "ERROR" {
+		t.Errorf("Unexpected filters: %+v", config.Filters)
+	}
+	if len(config.Redact) != 1 || config.Redact[0].Name != "email" {
+		t.Errorf("Unexpected redact rules: %+v", config.Redact)
+	}
+	if config.Sampling == nil || config.Sampling.Initial != 10 || config.Sampling.Tick != 2*time.Second {
+		t.Errorf("Unexpected sampling: %+v", config.Sampling)
+	}
+
+	jsonPath := filepath.Join(testDir, "logger.json")
+	os.WriteFile(jsonPath, []byte(`{"path": "`+testDir+`/logs", "type": "json", "max_age": "1h", "recent_size": 5}`), 0644)
+	logger, err := NewFromFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to create logger from JSON: %v", err)
+	}
+	defer logger.Close()
+	if cfg := logger.Config(); cfg.Type != "json" || cfg.MaxAge != time.Hour || cfg.RecentSize != 5 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+
+	os.WriteFile(jsonPath, []byte(`{"max_sise": 10}`), 0644)
+	if _, err := LoadConfig(jsonPath); err == nil || !strings.Contains(err.Error(), "max_sise") {
+		t.Errorf("Unknown keys should be rejected, got %v", err)
+	}
+	// * a leading zero is decimal, octal and hex need 0o and 0x
+	for text, expected := range map[string]any{"010": int64(10), "0o17": int64(15), "0x1F": int64(31), "-8": int64(-8), "0x": "0x", "0o-1": "0o-1", "1.5": 1.5} {
+		if value, err := parseYAMLScalar(yamlLine{number: 1, content: text}); err != nil || value != expected {
+			t.Errorf("Expected %q to parse as %v, got %v %v", text, expected, value, err)
+		}
+	}
+	os.WriteFile(yamlPath, []byte("path: |\n  multi\n"), 0644)
+	if _, err := LoadConfig(yamlPath); err == nil {
+		t.Error("Unsupported YAML syntax should be rejected")
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	testDir := fmt.Sprintf("./test_env_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+
+	t.Setenv("GOLOGGER_PATH", testDir)
+	t.Setenv("GOLOGGER_TYPE", "json")
+	t.Setenv("GOLOGGER_LEVEL", "error")
+	t.Setenv("GOLOGGER_STDOUT", "false")
+	t.Setenv("GOLOGGER_MAX_AGE", "24h")
+	t.Setenv("GOLOGGER_MASK_KEYS", "password, token")
+
+	logger, err := New(&Log{Path: "./ignored", Type: "text", Stdout: true})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	config := logger.Config()
+	if config.Path != testDir || config.Type != "json" || config.Level != "ERROR" || config.Stdout || config.MaxAge != 24*time.Hour {
+		t.Errorf("Environment should override config, got %+v", config)
+	}
+	if len(config.MaskKeys) != 2 || config.MaskKeys[1] != "token" {
+		t.Errorf("Expected comma separated keys, got %v", config.MaskKeys)
+	}
+	if _, err := os.Stat("./ignored"); err == nil {
+		os.RemoveAll("./ignored")
+		t.Error("Programmatic path should not be created")
+	}
+
+	t.Setenv("GOLOGGER_MAX_SIZE", "big")
+	if _, err := New(&Log{}); err == nil || !strings.Contains(err.Error(), "GOLOGGER_MAX_SIZE") {
+		t.Errorf("Invalid value should name the variable, got %v", err)
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	testDir := fmt.Sprintf("./test_options_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+
+	logger, err := NewWithOptions(
+		WithPath(testDir),
+		WithJSON(),
+		WithLevel("info"),
+		WithMaxSize(2048),
+		WithMaskKeys("password"),
+		WithMaskKeys("token"),
+		WithConfig(func(c *Log) { c.RecentSize = 2 }),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	config := logger.Config()
+	if config.Path != testDir || config.Type != "json" || config.Level != "INFO" || config.MaxSize != 2048 || config.RecentSize != 2 {
+		t.Errorf("Options not applied: %+v", config)
+	}
+	if config.MaxBackup != 5 {
+		t.Errorf("Unset options should keep defaults, got MaxBackup %d", config.MaxBackup)
+	}
+	if len(config.MaskKeys) != 2 {
+		t.Errorf("Repeated list options should accumulate, got %v", config.MaskKeys)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	devDir := fmt.Sprintf("./test_dev_%d", time.Now().UnixNano())
+	defer os.RemoveAll(devDir)
+
+	dev, err := NewDevelopment(WithPath(devDir))
+	if err != nil {
+		t.Fatalf("Failed to create development logger: %v", err)
+	}
+	if config := dev.Config(); config.Type != "text" || !config.Stdout || !config.Color || !config.Caller || config.Level != "DEBUG" {
+		t.Errorf("Unexpected development config: %+v", config)
+	}
+	dev.Info("where am I")
+	dev.Close()
+	if content := readLogContent(t, filepath.Join(devDir, "output.log")); !strings.Contains(content, "└── caller=logger_test.go:") {
+		t.Errorf("Expected caller of the test, got %q", content)
+	}
+
+	prodDir := fmt.Sprintf("./test_prod_%d", time.Now().UnixNano())
+	defer os.RemoveAll(prodDir)
+
+	prod, err := NewProduction(WithPath(prodDir), WithSampling(2, 3, time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create production logger: %v", err)
+	}
+	if config := prod.Config(); config.Type != "json" || config.Stdout || config.Level != "INFO" {
+		t.Errorf("Unexpected production config: %+v", config)
+	}
+	for i := 0; i < 8; i++ {
+		prod.Info("repeated")
+	}
+	prod.Info("distinct")
+	prod.Close()
+
+	// * 2 initial, then the 3rd and 6th of the remaining 6
+	content := readLogContent(t, filepath.Join(prodDir, "output.log"))
+	if count := strings.Count(content, `"msg":"repeated"`); count != 4 {
+		t.Errorf("Expected 4 sampled entries, got %d", count)
+	}
+	if !strings.Contains(content, "distinct") || prod.Stats().Dropped != 4 {
+		t.Errorf("Unexpected sampling result: dropped %d", prod.Stats().Dropped)
+	}
+}
+
+func TestColorWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := &colorWriter{writer: &buf}
+
+	writer.Write([]byte("2025/06/01 12:00:00.000000 [ERROR] boom\n2025/06/01 12:00:00.000000 └── detail\n"))
+	if buf.String() != "\033[31m2025/06/01 12:00:00.000000 [ERROR] boom\033[0m\n\033[31m2025/06/01 12:00:00.000000 └── detail\033[0m\n" {
+		t.Errorf("Unexpected colored output: %q", buf.String())
+	}
+
+	buf.Reset()
+	writer.Write([]byte("2025/06/01 12:00:00.000000 plain info\n"))
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("INFO should stay uncolored, got %q", buf.String())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (&Log{}).Validate(); err != nil {
+		t.Errorf("Zero config should be valid, got %v", err)
+	}
+
+	config := &Log{
+		MaxSize:        -1,
+		Type:           "yaml",
+		Level:          "LOUD",
+		DeleteArchived: true,
+		Color:          true,
+	}
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, expected := range []string{"max_size must not be negative", `type must be "text" or "json", got "yaml"`, `level "LOUD" is unknown`, "delete_archived needs an Archiver", "color needs stdout"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in %v", expected, err)
+		}
+	}
+
+	testFile := fmt.Sprintf("./test_validate_%d", time.Now().UnixNano())
+	os.WriteFile(testFile, []byte("not a directory"), 0644)
+	defer os.Remove(testFile)
+	if _, err := New(&Log{Path: filepath.Join(testFile, "logs")}); err == nil || !strings.Contains(err.Error(), "is not a directory") {
+		t.Errorf("New should reject a path under a file, got %v", err)
+	}
+
+	// * validation starts no sink and writes nothing outside the configured path
+	fsys := NewMemFS()
+	fsys.MkdirAll("var", 0755)
+	sink := &NetworkSink{Addr: "127.0.0.1:1", Interval: time.Hour}
+	if err := (&Log{FS: fsys, Path: "var/logs", Sinks: []Sink{sink}}).Validate(); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+	if sink.batcher != nil {
+		t.Error("Validate should not start the sink")
+	}
+	if items, _ := fsys.ReadDir("var"); len(items) != 0 {
+		t.Errorf("Validate should not write into the parent, got %v", items)
+	}
+	if _, err := New(&Log{FS: fsys, Path: "var/logs", Sinks: []Sink{&NetworkSink{}}}); err == nil || !strings.Contains(err.Error(), "sink 0: Addr is required") {
+		t.Errorf("New should report the sink's settings, got %v", err)
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	testDir := fmt.Sprintf("./test_reconfigure_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+
+	logger, err := New(&Log{Path: testDir, RecentSize: 3})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+
+	err = logger.Reconfigure(&Log{
+		Path:       testDir,
+		Type:       "json",
+		Level:      "info",
+		RecentSize: 2,
+		Filters:    []FilterRule{{Prefix: "noise"}},
+	})
+	if err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+
+	logger.Debug("hidden")
+	logger.Info("noise from a library")
+	logger.Info("kept")
+
+	content := readLogContent(t, filepath.Join(testDir, "output.log"))
+	if !strings.Contains(content, `"msg":"Configuration reloaded"`) || !strings.Contains(content, `"msg":"kept"`) {
+		t.Errorf("Expected JSON entries after reload, got %q", content)
+	}
+	if strings.Contains(content, "noise") {
+		t.Error("New filters should apply")
+	}
+	if debug := readLogContent(t, filepath.Join(testDir, "debug.log")); strings.Contains(debug, "hidden") {
+		t.Error("New level should apply")
+	}
+	if recent := logger.Recent(0, ""); len(recent) != 2 || recent[1].Message != "kept" {
+		t.Errorf("Ring buffer should be resized keeping newest, got %+v", recent)
+	}
+
+	if err := logger.Reconfigure(&Log{Path: testDir + "_other", AppendOnly: true}); err == nil || !strings.Contains(err.Error(), "path, append_only") {
+		t.Errorf("Fixed options should be rejected, got %v", err)
+	}
+	if err := logger.Reconfigure(&Log{Path: testDir, Type: "xml"}); err == nil {
+		t.Error("Invalid config should be rejected")
+	}
+	if logger.Config().Type != "json" {
+		t.Error("Rejected config should leave the logger unchanged")
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	testDir := fmt.Sprintf("./test_watch_%d", time.Now().UnixNano())
+	defer os.RemoveAll(testDir)
+	os.MkdirAll(testDir, 0755)
+
+	configPath := filepath.Join(testDir, "logger.yaml")
+	os.WriteFile(configPath, []byte("path: "+testDir+"\nlevel: debug\n"), 0644)
+
+	logger, err := NewFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	stop, err := logger.WatchConfig(configPath, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	defer stop()
+
+	os.WriteFile(configPath, []byte("path: "+testDir+"\nlevel: warning\n"), 0644)
+	deadline := time.Now().Add(2 * time.Second)
+	for logger.Config().Level != "WARNING" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if logger.Config().Level != "WARNING" {
+		t.Errorf("Expected level reloaded from file, got %s", logger.Config().Level)
+	}
+}
+
+func TestReconfigureSinks(t *testing.T) {
+	var built []*captureSink
+	RegisterSink("capture-reload", func(options json.RawMessage) (Sink, error) {
+		sink := &captureSink{}
+		built = append(built, sink)
+		return sink, json.Unmarshal(options, sink)
+	})
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "logger.yaml")
+	write := func(level, prefix string) {
+		// * replaced in one step, the watch must not see a half-written file
+		os.WriteFile(configPath+".tmp", []byte("path: "+dir+"\nlevel: "+level+"\nsinks:\n  - name: capture-reload\n    options:\n      prefix: \""+prefix+"\"\n"), 0644)
+		os.Rename(configPath+".tmp", configPath)
+	}
+	write("debug", "a: ")
+
+	logger, err := NewFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	var failures []error
+	var failuresMutex sync.Mutex
+	logger.OnInternalError(func(err error) {
+		failuresMutex.Lock()
+		defer failuresMutex.Unlock()
+		failures = append(failures, err)
+	})
+	if _, err := logger.WatchConfig(configPath, 10*time.Millisecond); err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	logger.Debug("before")
+
+	// * level and sink change in one edit, both apply
+	time.Sleep(20 * time.Millisecond)
+	write("info", "b: ")
+	deadline := time.Now().Add(2 * time.Second)
+	for logger.Config().Level != "INFO" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if logger.Config().Level != "INFO" {
+		t.Fatalf("Expected the reload to apply with a changed sink, got level %s", logger.Config().Level)
+	}
+	logger.Debug("hidden")
+	logger.Info("after")
+	logger.Close()
+
+	if len(built) != 2 {
+		t.Fatalf("Expected the sink to be rebuilt once, got %d", len(built))
+	}
+	if !slices.Contains(built[0].entries, "a: before") || slices.Contains(built[0].entries, "a: after") || !built[0].closed {
+		t.Errorf("Expected the old sink to stop receiving and be closed, got %+v", built[0])
+	}
+	if !slices.Contains(built[1].entries, "b: after") || slices.Contains(built[1].entries, "b: hidden") || !built[1].closed {
+		t.Errorf("Expected the new sink to receive entries after the swap, got %+v", built[1])
+	}
+
+	// * the watch ends with the logger, a later edit is not applied to a closed logger
+	write("error", "c: ")
+	time.Sleep(100 * time.Millisecond)
+	failuresMutex.Lock()
+	defer failuresMutex.Unlock()
+	if len(failures) != 0 || len(built) != 2 {
+		t.Errorf("Expected WatchConfig to stop on Close, got %v", failures)
+	}
+}
+
+func TestNop(t *testing.T) {
+	var logger Interface = Nop()
+
+	logger.Debug("ignored")
+	logger.Info("ignored")
+	err := logger.Error(fmt.Errorf("cause"), "Failed to connect")
+	if err == nil || err.Error() != "Failed to connect cause" {
+		t.Errorf("Nop should return the same error as Logger, got %v", err)
+	}
+
+	real, testDir := createTestLogger(t, "text")
+	defer os.RemoveAll(testDir)
+	defer real.Close()
+
+	logger = real
+	if realErr := logger.Error(fmt.Errorf("cause"), "Failed to connect"); realErr.Error() != err.Error() {
+		t.Errorf("Expected %q, got %q", err, realErr)
+	}
+}
+
+func TestClock(t *testing.T) {
+	outputs := make([]string, 2)
+	for i := range outputs {
+		testDir := fmt.Sprintf("./test_clock_%d_%d", i, time.Now().UnixNano())
+		defer os.RemoveAll(testDir)
+
+		current := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+		clock := ClockFunc(func() time.Time {
+			current = current.Add(time.Second)
+			return current
+		})
+
+		logger, err := New(&Log{Path: testDir, Type: "json", Clock: clock})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		logger.Info("first")
+		logger.Rotate("output.log")
+		logger.Info("second")
+		logger.Close()
+
+		backups, _ := filepath.Glob(filepath.Join(testDir, "output.log.*"))
+		if len(backups) != 1 || filepath.Base(backups[0]) != "output.log.20250601_120003" {
+			t.Errorf("Backup name should follow the clock, got %v", backups)
+		}
+		outputs[i] = readLogContent(t, backups[0]) + readLogContent(t, filepath.Join(testDir, "output.log"))
+	}
+
+	if outputs[0] != outputs[1] {
+		t.Errorf("Output should be byte-identical:\n%s\n%s", outputs[0], outputs[1])
+	}
+	if !strings.Contains(outputs[0], `"time":"2025-06-01T12:00:02Z","level":"INFO","msg":"first"`) {
+		t.Errorf("Entry time should follow the clock, got %s", outputs[0])
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	for _, dated := range []bool{false, true} {
+		fsys := NewMemFS()
+		logger, err := New(&Log{Path: "mem/logs", FS: fsys, MaxSize: 1024, Checksum: true, Audit: true, DatedFiles: dated})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.Info("before rotation")
+		if err := logger.Rotate("output.log"); err != nil {
+			t.Fatalf("Rotate failed: %v", err)
+		}
+		logger.Info("after rotation")
+
+		entries, err := logger.Query(QueryOptions{})
+		if err != nil || len(entries) != 2 {
+			t.Errorf("Query should read the in-memory files, got %+v %v", entries, err)
+		}
+		logger.Close()
+
+		content, err := fsys.ReadFile("mem/logs/output.log")
+		if err != nil || !strings.Contains(string(content), "after rotation") || strings.Contains(string(content), "before rotation") {
+			t.Errorf("Expected live file in memory, got %q %v", content, err)
+		}
+		items, _ := fsys.ReadDir("mem/logs")
+		var sidecars int
+		for _, item := range items {
+			if strings.HasSuffix(item.Name(), checksumExt) {
+				sidecars++
+			}
+		}
+		if sidecars != 1 {
+			t.Errorf("Expected checksum sidecar in memory, got %d", sidecars)
+		}
+
+		resumed, _ := New(&Log{Path: "mem/logs", FS: fsys, Audit: true, DatedFiles: dated})
+		if resumed.chain["output.log"] == "" {
+			t.Error("Audit chain should resume from the in-memory file")
+		}
+		resumed.Close()
+	}
+
+	if _, err := os.Stat("mem"); !os.IsNotExist(err) {
+		os.RemoveAll("mem")
+		t.Error("MemFS should not touch the disk")
+	}
+}
+
+func TestNamed(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON(), WithLevel("INFO"), WithNamedLevel("http", "DEBUG"), WithNamedLevel("db", "ERROR"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	client := logger.Named("http").Named("client")
+	if client.Name() != "http.client" {
+		t.Errorf("Expected dotted name, got %q", client.Name())
+	}
+	client.Debug("client debug")
+	logger.Named("httpx").Debug("prefix must match whole segments")
+	logger.Debug("root debug")
+	logger.Named("db").Warn("db warning")
+	logger.Named("db").Error(nil, "db error")
+
+	debug, _ := fsys.ReadFile("logs/debug.log")
+	if !strings.Contains(string(debug), `"msg":"client debug","logger":"http.client"`) {
+		t.Errorf("Expected http.client debug with logger field, got %s", debug)
+	}
+	if strings.Contains(string(debug), "whole segments") || strings.Contains(string(debug), "root debug") {
+		t.Errorf("Unconfigured names should use the global level, got %s", debug)
+	}
+	output, _ := fsys.ReadFile("logs/output.log")
+	if strings.Contains(string(output), "db warning") {
+		t.Errorf("db should be limited to ERROR, got %s", output)
+	}
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	if !strings.Contains(string(errorLog), `"logger":"db"`) {
+		t.Errorf("Expected db error with logger field, got %s", errorLog)
+	}
+
+	restore := logger.Elevate("DEBUG", time.Minute)
+	logger.Named("db").Info("db elevated")
+	restore()
+	output, _ = fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(output), "db elevated") {
+		t.Errorf("Elevation should apply to named loggers, got %s", output)
+	}
+
+	if _, err := New(&Log{Path: "logs", FS: fsys, Levels: map[string]string{"http": "LOUD"}}); err == nil {
+		t.Error("Expected error for unknown named level")
+	}
+
+	t.Setenv("GOLOGGER_LEVELS", "http=ERROR, db=debug")
+	config := &Log{}
+	if err := applyEnv(config); err != nil || config.Levels["http"] != "ERROR" || config.Levels["db"] != "debug" {
+		t.Errorf("Expected levels from env, got %v %v", config.Levels, err)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithMaxSize(1024),
+		WithRoute(Route{Prefix: "db", File: "db", MaxSize: 64, MaxBackup: 1}),
+		WithRoute(Route{Prefix: "http", File: "http.log"}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Named("db.pool").Debug("pool debug")
+	logger.Named("db").Error(nil, "query failed")
+	logger.Named("http").Info("GET /users")
+	logger.Named("cache").Info("cache hit")
+	logger.Info("root entry")
+
+	db, _ := fsys.ReadFile("logs/db.log")
+	if !strings.Contains(string(db), "pool debug") || !strings.Contains(string(db), "query failed") {
+		t.Errorf("Expected every level of db.* in db.log, got %s", db)
+	}
+	httpLog, _ := fsys.ReadFile("logs/http.log")
+	if !strings.Contains(string(httpLog), "GET /users") {
+		t.Errorf("Expected http entries in http.log, got %s", httpLog)
+	}
+	output, _ := fsys.ReadFile("logs/output.log")
+	if strings.Contains(string(output), "GET /users") || !strings.Contains(string(output), "cache hit") || !strings.Contains(string(output), "root entry") {
+		t.Errorf("Only unrouted entries should reach output.log, got %s", output)
+	}
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	if strings.Contains(string(errorLog), "query failed") {
+		t.Errorf("Routed errors should stay in db.log, got %s", errorLog)
+	}
+
+	// * db.log rotates at its own 64 bytes and keeps one backup
+	for i := 0; i < 3; i++ {
+		logger.Named("db").Info(strings.Repeat("x", 80))
+		if err := logger.Rotate("db"); err != nil {
+			t.Fatalf("Rotate failed: %v", err)
+		}
+	}
+	logger.Mutex.Lock()
+	if limit := logger.maxSize("db.log"); limit != 64 {
+		t.Errorf("Expected route max size 64, got %d", limit)
+	}
+	logger.Mutex.Unlock()
+	items, _ := fsys.ReadDir("logs")
+	var backups int
+	for _, item := range items {
+		if strings.HasPrefix(item.Name(), "db.log.") {
+			backups++
+		}
+	}
+	if backups != 1 {
+		t.Errorf("Expected 1 db.log backup, got %d", backups)
+	}
+	if stats := logger.Stats(); stats.Bytes["http.log"] == 0 {
+		t.Errorf("Expected bytes counted for http.log, got %v", stats.Bytes)
+	}
+	logger.Close()
+
+	for _, routes := range [][]Route{
+		{{Prefix: "db", File: "output.log"}},
+		{{Prefix: "db", File: "../db.log"}},
+		{{File: "db.log"}},
+		{{Prefix: "db", File: "db.log"}, {Prefix: "sql", File: "db"}},
+	} {
+		if _, err := New(&Log{Path: "logs", FS: fsys, Routes: routes}); err == nil {
+			t.Errorf("Expected error for routes %+v", routes)
+		}
+	}
+}
+
+func TestTenants(t *testing.T) {
+	for _, dated := range []bool{false, true} {
+		fsys := NewMemFS()
+		logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON(), WithTenantField("tenant_id"),
+			WithMaxBackup(1), WithConfig(func(c *Log) { c.DatedFiles = dated }),
+			WithRoute(Route{Prefix: "db", File: "db.log"}))
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		var internal []error
+		logger.OnInternalError(func(err error) { internal = append(internal, err) })
+
+		logger.Tenant("acme").Info("acme entry")
+		logger.Tenant("globex").Named("db").Error(nil, "globex query")
+		logger.Tenant("../escape").Info("invalid tenant")
+		logger.Info("shared entry")
+
+		acme, _ := fsys.ReadFile("logs/acme/output.log")
+		if !strings.Contains(string(acme), `"msg":"acme entry","tenant_id":"acme"`) {
+			t.Errorf("Expected acme entry in its directory, got %s", acme)
+		}
+		globex, _ := fsys.ReadFile("logs/globex/db.log")
+		if !strings.Contains(string(globex), "globex query") {
+			t.Errorf("Expected routes to apply inside the tenant directory, got %s", globex)
+		}
+		shared, _ := fsys.ReadFile("logs/output.log")
+		if !strings.Contains(string(shared), "shared entry") || !strings.Contains(string(shared), "invalid tenant") || strings.Contains(string(shared), "acme entry") {
+			t.Errorf("Expected only untenanted entries in the shared files, got %s", shared)
+		}
+		if len(internal) != 1 {
+			t.Errorf("Expected invalid tenant to be reported, got %v", internal)
+		}
+		if tenants := logger.Tenants(); len(tenants) != 2 || tenants[0] != "acme" || tenants[1] != "globex" {
+			t.Errorf("Expected acme and globex, got %v", tenants)
+		}
+
+		// * tenant retention is independent of the shared files
+		for i := 0; i < 3; i++ {
+			logger.Tenant("acme").Info("rotation", i)
+			if err := logger.Rotate("acme/output"); err != nil {
+				t.Fatalf("Rotate failed: %v", err)
+			}
+		}
+		logger.Tenant("acme").Info("after rotation")
+		items, _ := fsys.ReadDir("logs/acme")
+		var backups int
+		for _, item := range items {
+			if item.Name() != "output.log" && strings.HasPrefix(item.Name(), "output") {
+				backups++
+			}
+		}
+		if !dated && backups != 1 {
+			t.Errorf("Expected 1 acme backup, got %d", backups)
+		}
+		acme, _ = fsys.ReadFile("logs/acme/output.log")
+		if !strings.Contains(string(acme), "after rotation") {
+			t.Errorf("Expected live acme file after rotation, got %s", acme)
+		}
+		logger.Close()
+	}
+}
+
+func TestTenantEviction(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithTenantField("tenant_id"), WithMaxTenants(2))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	var internal []error
+	logger.OnInternalError(func(err error) { internal = append(internal, err) })
+
+	logger.Tenant("acme").Info("acme 1")
+	logger.Tenant("globex").Info("globex 1")
+	logger.Tenant("acme").Info("acme 2")
+	logger.Tenant("initech").Info("initech 1")
+	if tenants := logger.Tenants(); !slices.Equal(tenants, []string{"acme", "initech"}) {
+		t.Errorf("Expected the idle globex to be closed, got %v", tenants)
+	}
+	if _, isOpen := logger.File["globex/output.log"]; isOpen {
+		t.Error("Evicted tenant should not keep its files open")
+	}
+
+	logger.Tenant("globex").Info("globex 2")
+	globex, _ := fsys.ReadFile("logs/globex/output.log")
+	if !strings.Contains(string(globex), "globex 1") || !strings.Contains(string(globex), "globex 2") {
+		t.Errorf("Expected the tenant's file to be reopened and appended, got %s", globex)
+	}
+	if tenants := logger.Tenants(); !slices.Equal(tenants, []string{"globex", "initech"}) {
+		t.Errorf("Expected acme to be closed next, got %v", tenants)
+	}
+
+	for range 3 {
+		logger.Tenant("../escape").Info("invalid tenant")
+	}
+	logger.Tenant("a b").Info("another invalid tenant")
+	if len(internal) != 2 {
+		t.Errorf("Expected each invalid tenant to be reported once, got %v", internal)
+	}
+}
+
+func TestClone(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithRoute(Route{Prefix: "billing", File: "billing.log"}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	messages := []any{"charged", "card=visa"}
+	logger.Clone("payments").Info(messages...)
+	logger.Clone("billing").Warn("invoice late")
+	if messages[0] != "charged" {
+		t.Errorf("Clone must not change the caller's messages, got %v", messages)
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(output), "[payments] charged") || !strings.Contains(string(output), "card=visa") {
+		t.Errorf("Expected text prefix, got %s", output)
+	}
+	billing, _ := fsys.ReadFile("logs/billing.log")
+	if !strings.Contains(string(billing), "[WARNING] [billing] invoice late") {
+		t.Errorf("Expected clone component to select its route, got %s", billing)
+	}
+
+	logger.SetType("json")
+	logger.Clone("payments").Clone("refunds").Error(nil, "refund failed")
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	if !strings.Contains(string(errorLog), `"msg":"refund failed","component":"refunds"`) {
+		t.Errorf("Expected component field in json, got %s", errorLog)
+	}
+}
+
+func TestWithGroup(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON(), WithMaskKeys("token"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	request := logger.With("service", "api").WithGroup("http").With("method", "GET")
+	request.WithGroup("headers").With("token", "secret", "accept", "json").Info("served", "extra")
+	request.With("status", 200).Info("done")
+	request.Info("method only")
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 entries, got %s", output)
+	}
+	if !strings.Contains(lines[0], `"service":"api","http":{"method":"GET","headers":{"token":"***","accept":"json"}}`) {
+		t.Errorf("Expected nested masked groups, got %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"http":{"method":"GET","status":200}`) {
+		t.Errorf("Expected fields merged into the open group, got %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `"http":{"method":"GET"}`) {
+		t.Errorf("Sibling children must not share fields, got %s", lines[2])
+	}
+
+	logger.SetType("text")
+	request.WithGroup("headers").With("accept", "json").Warn("served", "extra")
+	logger.With("odd").Info("bad key")
+	output, _ = fsys.ReadFile("logs/output.log")
+	text := string(output)
+	for _, want := range []string{
+		" ├── extra\n",
+		" ├── service=api\n",
+		" └─┬ http\n",
+		"     ├── method=GET\n",
+		"     └─┬ headers\n",
+		"         └── accept=json\n",
+		" └── !BADKEY=odd\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in text tree, got\n%s", want, text)
+		}
+	}
+
+	reader := NewStreamReader(strings.NewReader(text[strings.Index(text, "\n2")+1:]))
+	var entries []Entry
+	for reader.Next() {
+		entries = append(entries, reader.Entry())
+	}
+	if err := reader.Err(); err != nil || len(entries) != 2 {
+		t.Fatalf("Expected 2 text entries, got %d %v", len(entries), err)
+	}
+	if got := strings.Join(entries[0].Data, "|"); got != "extra|service=api|http.method=GET|http.headers.accept=json" {
+		t.Errorf("Expected grouped fields flattened by the reader, got %q", got)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if _, isFallback := Default().(*stderrLogger); !isFallback {
+		t.Fatalf("Expected stderr fallback before SetDefault, got %T", Default())
+	}
+
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	SetDefault(logger)
+	defer SetDefault(nil)
+
+	Info("through default", "detail")
+	returned := Error(io.EOF, "read failed")
+	if returned == nil || returned.Error() != "read failed EOF" {
+		t.Errorf("Expected composed error, got %v", returned)
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(output), "through default") {
+		t.Errorf("Expected package Info in the default logger, got %s", output)
+	}
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	if !strings.Contains(string(errorLog), "[ERROR] read failed") {
+		t.Errorf("Expected package Error in the default logger, got %s", errorLog)
+	}
+
+	SetDefault(Nop())
+	Info("discarded")
+	SetDefault(nil)
+	if _, isFallback := Default().(*stderrLogger); !isFallback {
+		t.Errorf("SetDefault(nil) should restore the fallback, got %T", Default())
+	}
+}
+
+func TestFormatted(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithLevel("INFO"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	var formatted int
+	logger.Debugf("hidden %v", stringerFunc(func() string { formatted++; return "1" }))
+	logger.Named("db").Tracef("hidden %v", stringerFunc(func() string { formatted++; return "2" }))
+	if formatted != 0 {
+		t.Errorf("Arguments below the level should not be formatted, ran %d times", formatted)
+	}
+	logger.Infof("user %s logged in from %s", "alice", "10.0.0.1")
+	logger.Named("db").Warnf("slow query: %dms", 1200)
+	returned := logger.Errorf("save order %d: %w", 42, io.ErrUnexpectedEOF)
+	if !errors.Is(returned, io.ErrUnexpectedEOF) || returned.Error() != "save order 42: unexpected EOF" {
+		t.Errorf("Expected wrapped error with message, got %v", returned)
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(output), "user alice logged in from 10.0.0.1\n") {
+		t.Errorf("Expected a single formatted message, got %s", output)
+	}
+	if !strings.Contains(string(output), "[WARNING] slow query: 1200ms") {
+		t.Errorf("Expected formatted warning, got %s", output)
+	}
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	if !strings.Contains(string(errorLog), "[ERROR] save order 42: unexpected EOF") {
+		t.Errorf("Expected formatted error, got %s", errorLog)
+	}
+	if !strings.Contains(string(errorLog), "└── unexpected EOF") {
+		t.Errorf("Expected the wrapped error as a cause, got %s", errorLog)
+	}
+	debug, _ := fsys.ReadFile("logs/debug.log")
+	if strings.Contains(string(debug), "hidden") {
+		t.Errorf("Level filtering should apply, got %s", debug)
+	}
+}
+
+type stringerFunc func() string
+
+func (f stringerFunc) String() string { return f() }
+
+func TestLazy(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithLevel("INFO"), WithSampling(1, 0, time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	var calls int
+	expensive := func() any {
+		calls++
+		return "dump"
+	}
+	messages := []any{"state", Lazy(expensive)}
+	logger.Debug(messages...)
+	if calls != 0 {
+		t.Errorf("Lazy message should not run below the level, ran %d times", calls)
+	}
+
+	logger.Info(messages...)
+	logger.Info(messages...)
+	if calls != 1 {
+		t.Errorf("Lazy message should run once, the sampled duplicate is dropped, ran %d times", calls)
+	}
+	if _, isLazy := messages[1].(Lazy); !isLazy {
+		t.Errorf("Caller's messages must not be replaced, got %T", messages[1])
+	}
+
+	logger.Info(func() any { return "plain func" })
+	returned := logger.Error(nil, "failed", Lazy(func() any { return 42 }))
+	if returned.Error() != "failed 42" {
+		t.Errorf("Expected lazy value in returned error, got %v", returned)
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(output), "└── dump") || !strings.Contains(string(output), "plain func") {
+		t.Errorf("Expected evaluated values, got %s", output)
+	}
+}
+
+func TestEnabledAndVerbosity(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithLevel("INFO"), WithNamedLevel("http", "DEBUG"), WithVerbosity(2))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	if logger.Enabled("DEBUG") || !logger.Enabled("warn") || logger.Enabled("LOUD") {
+		t.Error("Enabled should follow the minimum level")
+	}
+	if !logger.Named("http.client").Enabled("DEBUG") {
+		t.Error("Enabled should follow per-name levels")
+	}
+
+	logger.V(2).Infof("verbose %d", 2)
+	logger.V(3).Info("too verbose")
+	if logger.V(3).Enabled() || !logger.V(0).Enabled() {
+		t.Error("V(n) should be enabled up to the verbosity")
+	}
+	logger.SetVerbosity(3)
+	logger.Named("db").V(3).Info("raised")
+	if err := logger.SetVerbosity(-1); err == nil {
+		t.Error("Expected error for negative verbosity")
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(output), "verbose 2") || strings.Contains(string(output), "too verbose") || !strings.Contains(string(output), "raised") {
+		t.Errorf("Unexpected verbose output: %s", output)
+	}
+
+	logger.SetLevel("ERROR")
+	if logger.V(0).Enabled() {
+		t.Error("V(n) is INFO and should follow the minimum level")
+	}
+	logger.Close()
+	if logger.Enabled("CRITICAL") {
+		t.Error("A closed logger should report nothing enabled")
+	}
+}
+
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func TestErrorWrapping(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	cause := &codeError{code: 503}
+	for name, returned := range map[string]error{
+		"Error":     logger.Error(cause, "upstream failed"),
+		"Fatal":     logger.Fatal(cause, "upstream failed"),
+		"Critical":  logger.Critical(cause, "upstream failed"),
+		"WarnError": logger.WarnError(cause, "upstream failed"),
+		"Named":     logger.Named("api").Error(cause, "upstream failed"),
+		"HTTPError": logger.HTTPError(nil, 502, cause, "upstream failed"),
+		"Nop":       Nop().Error(cause, "upstream failed"),
+	} {
+		var target *codeError
+		if !errors.As(returned, &target) || target.code != 503 || !errors.Is(returned, cause) {
+			t.Errorf("%s should wrap the original error, got %v", name, returned)
+		}
+		if returned.Error() != "upstream failed code 503" {
+			t.Errorf("%s should keep the composed message, got %q", name, returned.Error())
+		}
+	}
+
+	if returned := logger.Error(cause); returned.Error() != "code 503" || !errors.Is(returned, cause) {
+		t.Errorf("Expected bare wrap without messages, got %v", returned)
+	}
+	if returned := logger.Error(nil, "no cause"); returned.Error() != "no cause" || errors.Unwrap(returned) != nil {
+		t.Errorf("Expected plain error without cause, got %v", returned)
+	}
+}
+
+func TestMultiError(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	primary := &codeError{code: 500}
+	returned := logger.Error(primary, "cleanup failed", io.ErrClosedPipe, os.ErrPermission)
+	for _, cause := range []error{primary, io.ErrClosedPipe, os.ErrPermission} {
+		if !errors.Is(returned, cause) {
+			t.Errorf("Expected %v to be inspectable, got %v", cause, returned)
+		}
+	}
+	if returned.Error() != "cleanup failed io: read/write on closed pipe permission denied code 500" {
+		t.Errorf("Expected composed message, got %q", returned.Error())
+	}
+	joined, isJoined := errors.Unwrap(returned).(interface{ Unwrap() []error })
+	if !isJoined || len(joined.Unwrap()) != 3 {
+		t.Errorf("Expected causes joined with errors.Join, got %T", errors.Unwrap(returned))
+	}
+
+	logger.Error(primary, "single cause")
+
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	lines := strings.Split(strings.TrimSpace(string(errorLog)), "\n")
+	if !strings.Contains(lines[0], `"errors":["io: read/write on closed pipe","permission denied","code 500"]`) {
+		t.Errorf("Expected errors array, got %s", lines[0])
+	}
+	if strings.Contains(lines[1], `"errors"`) {
+		t.Errorf("A single cause needs no errors array, got %s", lines[1])
+	}
+}
+
+func failLookup(logger *Logger, id int) {
+	logger.Error(nil, fmt.Sprintf("user %d not found in shard 0x%x", id, id*16))
+}
+
+func TestFingerprint(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON(), WithFingerprint())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	var prints []string
+	logger.OnWrite(func(entry Entry, err error) {
+		for _, field := range entry.Fields {
+			if field.Key == "fingerprint" {
+				prints = append(prints, field.Value.(string))
+			}
+		}
+	})
+
+	failLookup(logger, 42)
+	failLookup(logger, 7)
+	logger.Error(nil, "user 42 not found in shard 0x2a0")
+	logger.Critical(nil, `config "a.yaml" invalid`)
+	logger.Warn("user 42 not found")
+	logger.Close()
+
+	if len(prints) != 4 {
+		t.Fatalf("Expected fingerprints for ERROR and above only, got %v", prints)
+	}
+	if prints[0] != prints[1] {
+		t.Errorf("Same message shape and function should share a fingerprint, got %v", prints)
+	}
+	if prints[0] == prints[2] {
+		t.Errorf("A different calling function should change the fingerprint, got %v", prints)
+	}
+	if normalized := normalizeMessage(`order 5f1c2d3e-aaaa-bbbb-cccc-123456789abc "x" took 1.5s at deadbeef99`); normalized != "order <uuid> <str> took <n>s at <hex>" {
+		t.Errorf("Unexpected normalization %q", normalized)
+	}
+}
+
+func TestErrorCauses(t *testing.T) {
+	root := &codeError{code: 503}
+	err := fmt.Errorf("save order: %w", fmt.Errorf("dial db: %w", root))
+
+	fsys := NewMemFS()
+	logger, newErr := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON())
+	if newErr != nil {
+		t.Fatalf("Failed to create logger: %v", newErr)
+	}
+	logger.Error(err, "checkout failed")
+	logger.Error(errors.New("plain"), "no chain")
+	logger.Close()
+
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	lines := strings.Split(strings.TrimSpace(string(errorLog)), "\n")
+	if !strings.Contains(lines[0], `"causes":["dial db: code 503","code 503"]`) {
+		t.Errorf("Expected causes list, got %s", lines[0])
+	}
+	if strings.Contains(lines[1], `"causes"`) {
+		t.Errorf("An unwrapped error has no causes, got %s", lines[1])
+	}
+
+	fsys = NewMemFS()
+	logger, newErr = NewWithOptions(WithFS(fsys), WithPath("logs"))
+	if newErr != nil {
+		t.Fatalf("Failed to create logger: %v", newErr)
+	}
+	logger.Error(err, "checkout failed")
+	logger.Close()
+
+	errorLog, _ = fsys.ReadFile("logs/error.log")
+	text := string(errorLog)
+	for _, want := range []string{"└─┬ causes", "    ├── dial db: code 503", "    └── code 503"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in text output, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	current := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fired := make(chan Alert, 8)
+	posted := make(chan Alert, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("Failed to decode alert: %v", err)
+		}
+		posted <- alert
+	}))
+	defer server.Close()
+
+	logger, err := NewWithOptions(
+		WithFS(NewMemFS()), WithPath("logs"),
+		WithClock(ClockFunc(func() time.Time { return current })),
+		WithAlert(AlertRule{Name: "errors", Count: 3, Window: time.Minute, Cooldown: 5 * time.Minute, Callback: func(alert Alert) { fired <- alert }}),
+		WithAlert(AlertRule{Level: "CRITICAL", Match: "^disk", Count: 1, Webhook: server.URL}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	expectAlerts := func(want int) {
+		t.Helper()
+		for i := 0; i < want; i++ {
+			select {
+			case <-fired:
+			case <-time.After(time.Second):
+				t.Fatalf("Expected %d alerts, got %d", want, i)
+			}
+		}
+		select {
+		case alert := <-fired:
+			t.Fatalf("Unexpected alert %+v", alert)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	logger.Error(nil, "first")
+	logger.Error(nil, "second")
+	logger.Info("not counted")
+	current = current.Add(2 * time.Minute)
+	logger.Error(nil, "third")
+	logger.Error(nil, "fourth")
+	expectAlerts(0)
+
+	logger.Critical(nil, "fifth")
+	select {
+	case alert := <-fired:
+		if alert.Name != "errors" || alert.Count != 3 || alert.Message != "fifth" || alert.Level != "CRITICAL" {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an alert after three errors within the window")
+	}
+
+	// * cool-down swallows the next burst
+	logger.Error(nil, "sixth")
+	logger.Error(nil, "seventh")
+	logger.Error(nil, "eighth")
+	expectAlerts(0)
+
+	current = current.Add(6 * time.Minute)
+	logger.Error(nil, "ninth")
+	logger.Error(nil, "tenth")
+	logger.Error(nil, "eleventh")
+	expectAlerts(1)
+
+	logger.Critical(nil, "disk full")
+	logger.Close()
+	select {
+	case alert := <-posted:
+		if alert.Name != "alert 1" || alert.Message != "disk full" {
+			t.Errorf("Unexpected webhook alert %+v", alert)
+		}
+	default:
+		t.Error("Close should wait for the webhook")
+	}
+
+	if _, err := NewWithOptions(WithFS(NewMemFS()), WithAlert(AlertRule{Count: 0, Webhook: "ftp://x"})); err == nil {
+		t.Error("Expected invalid alert rules to be rejected")
+	}
+}
+
+// * accepts every command, enough for net/smtp without auth or STARTTLS
+func fakeSMTPServer(t *testing.T, mails chan<- string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				fmt.Fprint(conn, "220 localhost ESMTP\r\n")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch command := strings.ToUpper(strings.TrimSpace(line)); {
+					case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+						fmt.Fprint(conn, "250 localhost\r\n")
+					case command == "DATA":
+						fmt.Fprint(conn, "354 go ahead\r\n")
+						var data strings.Builder
+						for {
+							line, err := reader.ReadString('\n')
+							if err != nil || line == ".\r\n" {
+								break
+							}
+							data.WriteString(line)
+						}
+						mails <- data.String()
+						fmt.Fprint(conn, "250 queued\r\n")
+					case command == "QUIT":
+						fmt.Fprint(conn, "221 bye\r\n")
+						return
+					default:
+						fmt.Fprint(conn, "250 ok\r\n")
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestSMTPSink(t *testing.T) {
+	mails := make(chan string, 4)
+	sink := &SMTPSink{
+		Addr:    fakeSMTPServer(t, mails),
+		From:    "logger@example.com",
+		To:      []string{"ops@example.com"},
+		Subject: "{{.Count}} x {{.Level}}",
+	}
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(sink))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Error(nil, "not mailed")
+	logger.Fatal(nil, "database unreachable")
+	logger.Critical(nil, "disk full")
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	select {
+	case mail := <-mails:
+		for _, want := range []string{"Subject: 2 x CRITICAL", "To: ops@example.com", "[FATAL] database unreachable", "[CRITICAL] disk full"} {
+			if !strings.Contains(mail, want) {
+				t.Errorf("Expected %q in mail:\n%s", want, mail)
+			}
+		}
+		if strings.Contains(mail, "not mailed") {
+			t.Errorf("ERROR is below the default level:\n%s", mail)
+		}
+	default:
+		t.Fatal("Flush should send the pending batch")
+	}
+
+	logger.Critical(nil, "after flush")
+	logger.Close()
+	select {
+	case mail := <-mails:
+		if !strings.Contains(mail, "after flush") {
+			t.Errorf("Close should send the rest, got:\n%s", mail)
+		}
+	default:
+		t.Fatal("Close should send the pending batch")
+	}
+
+	if _, err := NewWithOptions(WithFS(NewMemFS()), WithSink(&SMTPSink{Addr: "localhost:25"})); err == nil {
+		t.Error("Expected a sink without sender and recipients to be rejected")
+	}
+}
+
+func TestDatadogSink(t *testing.T) {
+	requests := make(chan []map[string]any, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DD-API-KEY") != "secret" || r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("Failed to decompress: %v", err)
+			return
+		}
+		var logs []map[string]any
+		if err := json.NewDecoder(reader).Decode(&logs); err != nil {
+			t.Errorf("Failed to decode: %v", err)
+		}
+		requests <- logs
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink := &DatadogSink{
+		APIKey:   "secret",
+		Endpoint: server.URL,
+		Service:  "api",
+		Tags:     []string{"env:test", "team:core"},
+		MinLevel: "INFO",
+		Compress: true,
+	}
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(sink))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Debug("skipped")
+	logger.Named("db").Critical(nil, "pool exhausted", "retrying")
+	logger.Close()
+
+	select {
+	case logs := <-requests:
+		if len(logs) != 1 {
+			t.Fatalf("Expected one entry, got %v", logs)
+		}
+		entry := logs[0]
+		for key, want := range map[string]any{
+			"message":  "pool exhausted",
+			"status":   "critical",
+			"service":  "api",
+			"ddsource": "go",
+			"ddtags":   "env:test,team:core",
+			"logger":   "db",
+			"msg1":     "retrying",
+		} {
+			if entry[key] != want {
+				t.Errorf("Expected %s=%v, got %v", key, want, entry[key])
+			}
+		}
+	default:
+		t.Fatal("Close should send the pending batch")
+	}
+
+	if _, err := NewWithOptions(WithFS(NewMemFS()), WithSink(&DatadogSink{})); err == nil {
+		t.Error("Expected a sink without API key to be rejected")
+	}
+}
+
+func TestGCPSink(t *testing.T) {
+	requests := make(chan map[string]any, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/project/project-id":
+			fmt.Fprint(w, "demo-project")
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			fmt.Fprint(w, `{"access_token":"token-1","expires_in":3600,"token_type":"Bearer"}`)
+		case "/v2/entries:write":
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				t.Errorf("Unexpected authorization %q", r.Header.Get("Authorization"))
+			}
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			requests <- body
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+	sink := &GCPSink{
+		LogName:  "api",
+		Resource: "cloud_run_revision",
+		Labels:   map[string]string{"service_name": "api"},
+		Endpoint: server.URL + "/v2/entries:write",
+	}
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(sink))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Notice("deployed")
+	logger.Fatal(nil, "migration failed")
+	logger.Critical(nil, "data loss")
+	logger.Close()
+
+	select {
+	case body := <-requests:
+		if body["logName"] != "projects/demo-project/logs/api" {
+			t.Errorf("Unexpected log name %v", body["logName"])
+		}
+		resource, _ := body["resource"].(map[string]any)
+		if resource["type"] != "cloud_run_revision" {
+			t.Errorf("Unexpected resource %v", resource)
+		}
+		entries, _ := body["entries"].([]any)
+		if len(entries) != 3 {
+			t.Fatalf("Expected 3 entries, got %v", entries)
+		}
+		for i, want := range []string{"NOTICE", "CRITICAL", "ALERT"} {
+			entry := entries[i].(map[string]any)
+			if entry["severity"] != want {
+				t.Errorf("Expected severity %s, got %v", want, entry["severity"])
+			}
+		}
+		payload := entries[1].(map[string]any)["jsonPayload"].(map[string]any)
+		if payload["message"] != "migration failed" {
+			t.Errorf("Unexpected payload %v", payload)
+		}
+	default:
+		t.Fatal("Close should send the pending batch")
+	}
+}
+
+func TestAzureSink(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("workspace-key"))
+	requests := make(chan []map[string]any, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		text := fmt.Sprintf("POST\n%d\napplication/json\nx-ms-date:%s\n/api/logs", len(body), r.Header.Get("x-ms-date"))
+		mac := hmac.New(sha256.New, []byte("workspace-key"))
+		mac.Write([]byte(text))
+		want := "SharedKey ws-1:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		if r.Header.Get("Authorization") != want || r.Header.Get("Log-Type") != "AppLogs" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		if r.URL.Query().Get("api-version") != "2016-04-01" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		var records []map[string]any
+		json.Unmarshal(body, &records)
+		requests <- records
+	}))
+	defer server.Close()
+
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(&AzureSink{
+		WorkspaceID: "ws-1",
+		SharedKey:   key,
+		LogType:     "AppLogs",
+		Endpoint:    server.URL,
+		MinLevel:    "WARNING",
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("skipped")
+	logger.Warn("slow query", "820ms")
+	logger.Close()
+
+	select {
+	case records := <-requests:
+		if len(records) != 1 || records[0]["msg"] != "slow query" || records[0]["level"] != "WARNING" || records[0]["TimeGenerated"] == nil {
+			t.Errorf("Unexpected records %v", records)
+		}
+	default:
+		t.Fatal("Close should send the pending batch")
+	}
+
+	if _, err := NewWithOptions(WithFS(NewMemFS()), WithSink(&AzureSink{WorkspaceID: "ws-1", SharedKey: key, LogType: "bad-type"})); err == nil {
+		t.Error("Expected an invalid log type to be rejected")
+	}
+}
+
+func TestFluentdSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	messages := make(chan []any, 4)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					message, err := readMsgpack(reader)
+					if err != nil {
+						return
+					}
+					items := message.([]any)
+					option := items[2].(map[string]any)
+					conn.Write(appendMsgpack(nil, map[string]any{"ack": option["chunk"]}))
+					messages <- items
+				}
+			}(conn)
+		}
+	}()
+
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(&FluentdSink{
+		Addr:       listener.Addr().String(),
+		Tag:        "api.access",
+		RequireAck: true,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("request", "GET /users")
+	logger.Flush()
+	logger.Error(nil, "second batch")
+	logger.Close()
+
+	for i, want := range []string{"request", "second batch"} {
+		select {
+		case message := <-messages:
+			if message[0] != "api.access" {
+				t.Errorf("Unexpected tag %v", message[0])
+			}
+			event := message[1].([]any)[0].([]any)
+			if _, ok := event[0].(eventTime); !ok {
+				t.Errorf("Expected EventTime, got %T", event[0])
+			}
+			if record := event[1].(map[string]any); record["msg"] != want {
+				t.Errorf("Unexpected record %v", record)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected batch %d", i)
+		}
+	}
+}
+
+func TestLogstashSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	lines := make(chan string, 8)
+	accept := func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}
+	go accept()
+
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(&LogstashSink{Addr: listener.Addr().String()}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Notice("first")
+	logger.Warn("second")
+	logger.Flush()
+	for _, want := range []string{`"msg":"first"`, `"msg":"second"`} {
+		select {
+		case line := <-lines:
+			if !strings.Contains(line, want) || !json.Valid([]byte(line)) {
+				t.Errorf("Expected JSON line with %s, got %s", want, line)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected line with %s", want)
+		}
+	}
+
+	// * the collector restarts, the sink reconnects
+	go accept()
+	sink := logger.Config().Sinks[0].(*LogstashSink)
+	sink.conn.conn.Close()
+	logger.Error(nil, "after reconnect")
+	logger.Flush()
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"msg":"after reconnect"`) {
+			t.Errorf("Unexpected line %s", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the sink to reconnect")
+	}
+}
+
+// * just enough of the NATS server protocol, PUB with a reply subject is acked like JetStream
+func fakeNATSServer(t *testing.T, published chan<- string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+				sid, seq := "", 0
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					fields := strings.Fields(line)
+					switch fields[0] {
+					case "SUB":
+						sid = fields[2]
+					case "PING":
+						fmt.Fprint(conn, "PONG\r\n")
+					case "PUB":
+						size, _ := strconv.Atoi(fields[len(fields)-1])
+						payload := make([]byte, size+2)
+						io.ReadFull(reader, payload)
+						published <- fields[1] + " " + string(payload[:size])
+						if len(fields) == 4 {
+							seq++
+							ack := fmt.Sprintf(`{"stream":"LOGS","seq":%d}`, seq)
+							fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", fields[2], sid, len(ack), ack)
+						}
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestNATSSink(t *testing.T) {
+	for _, jetStream := range []bool{false, true} {
+		published := make(chan string, 8)
+		logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(&NATSSink{
+			Addr:      fakeNATSServer(t, published),
+			Subject:   "logs.{{lower .Level}}",
+			JetStream: jetStream,
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		var failures []error
+		logger.OnInternalError(func(err error) { failures = append(failures, err) })
+
+		logger.Info("started")
+		logger.Error(nil, "failed")
+		logger.Close()
+
+		for _, want := range []string{`logs.info {"time"`, `logs.error {"time"`} {
+			select {
+			case message := <-published:
+				if !strings.HasPrefix(message, want) {
+					t.Errorf("Expected %s..., got %s", want, message)
+				}
+			default:
+				t.Fatalf("Expected a message on %s (jetstream %v)", want, jetStream)
+			}
+		}
+		if len(failures) > 0 {
+			t.Errorf("Unexpected failures %v", failures)
+		}
+	}
+}
+
+func TestMQTTSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	type publish struct {
+		topic   string
+		payload string
+	}
+	published := make(chan publish, 8)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			header, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			length, shift := 0, 0
+			for {
+				b, _ := reader.ReadByte()
+				length |= int(b&0x7f) << shift
+				shift += 7
+				if b&0x80 == 0 {
+					break
+				}
+			}
+			body := make([]byte, length)
+			io.ReadFull(reader, body)
+
+			switch header & 0xf0 {
+			case 0x10:
+				if string(body[2:6]) != "MQTT" || body[6] != 4 || body[7]&0x80 == 0 {
+					t.Errorf("Unexpected CONNECT %q", body)
+				}
+				conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+			case 0x30:
+				size := int(body[0])<<8 | int(body[1])
+				topic, rest := string(body[2:2+size]), body[2+size:]
+				if header&0x06 != 0x02 {
+					t.Errorf("Expected QoS 1, got header 0x%02x", header)
+				}
+				conn.Write([]byte{0x40, 0x02, rest[0], rest[1]})
+				published <- publish{topic, string(rest[2:])}
+			}
+		}
+	}()
+
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(&MQTTSink{
+		Addr:     listener.Addr().String(),
+		Topic:    "devices/42/logs/{{lower .Level}}",
+		QoS:      1,
+		Username: "device-42",
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("below the default level")
+	logger.Warn("battery low")
+	logger.Critical(nil, "overheating")
+	logger.Close()
+
+	for _, want := range []publish{{"devices/42/logs/warning", "battery low"}, {"devices/42/logs/critical", "overheating"}} {
+		select {
+		case got := <-published:
+			if got.topic != want.topic || !strings.Contains(got.payload, `"msg":"`+want.payload+`"`) {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		default:
+			t.Fatalf("Expected a publish on %s", want.topic)
+		}
+	}
+	if len(published) > 0 {
+		t.Errorf("INFO is below the default level, got %v", <-published)
+	}
+
+	if _, err := NewWithOptions(WithFS(NewMemFS()), WithSink(&MQTTSink{Addr: "broker:1883", QoS: 2})); err == nil {
+		t.Error("Expected QoS 2 to be rejected")
+	}
+}
+
+func TestJournaldSink(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("journald is only available on Linux")
+	}
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithJSON(), WithSink(&JournaldSink{Identifier: "api", Socket: socket}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Named("db").Critical(nil, "replica lost", "line one\nline two")
+	logger.Close()
+
+	buf := make([]byte, 64*1024)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("Expected a datagram: %v", err)
+	}
+	datagram := string(buf[:n])
+	for _, want := range []string{"MESSAGE=replica lost\n", "PRIORITY=1\n", "SYSLOG_IDENTIFIER=api\n", "GOLOGGER_LEVEL=CRITICAL\n", "LOGGER=db\n", "MSG1\n\x11\x00\x00\x00\x00\x00\x00\x00line one\nline two\n"} {
+		if !strings.Contains(datagram, want) {
+			t.Errorf("Expected %q in %q", want, datagram)
+		}
+	}
+
+	if _, err := NewWithOptions(WithFS(NewMemFS()), WithSink(&JournaldSink{Socket: filepath.Join(t.TempDir(), "missing.sock")})); err == nil {
+		t.Error("Expected a missing socket to be rejected")
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.sock")
+	received := make(chan Entry, 8)
+	listener, err := ListenUnix(path, func(entry Entry) { received <- entry })
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	if _, err := ListenUnix(path, func(Entry) {}); err == nil {
+		t.Error("Expected a socket in use to be rejected")
+	}
+
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(&UnixSink{Path: path}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.WithGroup("req").With("id", 7).Warn("slow", "900ms")
+	logger.Error(fmt.Errorf("save: %w", io.ErrUnexpectedEOF), "failed")
+	logger.Close()
+
+	select {
+	case entry := <-received:
+		if entry.Level != "WARNING" || entry.Message != "slow" || len(entry.Data) != 1 || entry.Data[0] != "900ms" {
+			t.Errorf("Unexpected entry %+v", entry)
+		}
+		group, isGroup := entry.Fields[0].Value.([]Field)
+		if entry.Fields[0].Key != "req" || !isGroup || group[0].Key != "id" || group[0].Value != float64(7) {
+			t.Errorf("Expected the group to survive, got %+v", entry.Fields)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an entry")
+	}
+	select {
+	case entry := <-received:
+		causes, isCauses := entry.Fields[0].Value.(causeList)
+		if !isCauses || causes[0] != "unexpected EOF" {
+			t.Errorf("Expected the causes to survive, got %+v", entry.Fields)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an entry")
+	}
+}
+
+func TestNetworkSink(t *testing.T) {
+	// * reserve a port, the collector starts only after the first attempt fails
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := probe.Addr().String()
+	probe.Close()
+
+	sink := &NetworkSink{Addr: addr, Retry: &RetryPolicy{MaxBackoff: 20 * time.Millisecond}, Interval: time.Hour}
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(sink))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	var failures []error
+	logger.OnInternalError(func(err error) { failures = append(failures, err) })
+
+	logger.Info("first")
+	logger.Info("second")
+	logger.Flush()
+	if len(failures) == 0 {
+		t.Fatal("Expected the send to fail while the collector is down")
+	}
+
+	collector, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("Port was taken meanwhile: %v", err)
+	}
+	defer collector.Close()
+	lines := make(chan string, 8)
+	go func() {
+		conn, err := collector.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	logger.Info("third")
+	time.Sleep(30 * time.Millisecond)
+	logger.Flush()
+	for _, want := range []string{"first", "second", "third"} {
+		select {
+		case line := <-lines:
+			if !strings.Contains(line, `"msg":"`+want+`"`) {
+				t.Errorf("Expected %q in order, got %s", want, line)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %q to be delivered after reconnecting", want)
+		}
+	}
+
+	frames := make(chan Entry, 1)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := serveFrames(listener, func(entry Entry) { frames <- entry })
+	defer server.Close()
+	framed := &NetworkSink{Addr: listener.Addr().String(), Framing: "length"}
+	if err := framed.init(); err != nil {
+		t.Fatalf("Failed to init: %v", err)
+	}
+	framed.Write(Entry{Time: time.Now(), Level: "ERROR", Message: "framed"})
+	framed.Close()
+	select {
+	case entry := <-frames:
+		if entry.Level != "ERROR" || entry.Message != "framed" {
+			t.Errorf("Unexpected entry %+v", entry)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a length-prefixed frame")
+	}
+
+	packet, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer packet.Close()
+	udp := &NetworkSink{Network: "udp", Addr: packet.LocalAddr().String()}
+	udp.init()
+	udp.Write(Entry{Time: time.Now(), Level: "INFO", Message: "one"})
+	udp.Write(Entry{Time: time.Now(), Level: "INFO", Message: "two"})
+	udp.Close()
+	buf := make([]byte, 1024)
+	for _, want := range []string{"one", "two"} {
+		packet.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := packet.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Expected a datagram: %v", err)
+		}
+		if !strings.Contains(string(buf[:n]), `"msg":"`+want+`"`) {
+			t.Errorf("Expected one entry per datagram, got %s", buf[:n])
+		}
+	}
+
+	if err := (&NetworkSink{Addr: addr, Framing: "csv"}).init(); err == nil {
+		t.Error("Expected an unknown framing to be rejected")
+	}
+}
+
+// * writes ca.pem plus server and client key pairs signed by it
+func writeTestCertificates(t *testing.T) string {
+	dir := t.TempDir()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	writePEM := func(name, kind string, der []byte) {
+		data := pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der})
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	writePEM("ca.pem", "CERTIFICATE", caDER)
+
+	for i, name := range []string{"server", "client"} {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 2)),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("Failed to create %s certificate: %v", name, err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		writePEM(name+".pem", "CERTIFICATE", der)
+		writePEM(name+".key", "EC PRIVATE KEY", keyDER)
+	}
+	return dir
+}
+
+func TestTLSFiles(t *testing.T) {
+	dir := writeTestCertificates(t)
+	server, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"))
+	if err != nil {
+		t.Fatalf("Failed to load server certificate: %v", err)
+	}
+	serverConfig, err := TLSFiles{CAFile: filepath.Join(dir, "ca.pem")}.Config()
+	if err != nil {
+		t.Fatalf("Failed to load CA: %v", err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{server},
+		ClientCAs:    serverConfig.RootCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	lines := make(chan string, 8)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}(conn)
+		}
+	}()
+
+	config, err := TLSFiles{
+		CAFile:   filepath.Join(dir, "ca.pem"),
+		CertFile: filepath.Join(dir, "client.pem"),
+		KeyFile:  filepath.Join(dir, "client.key"),
+	}.Config()
+	if err != nil {
+		t.Fatalf("Failed to load client certificate: %v", err)
+	}
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"),
+		WithSink(&NetworkSink{Addr: listener.Addr().String(), TLS: config}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	var failures []error
+	logger.OnInternalError(func(err error) { failures = append(failures, err) })
+	logger.Info("mutual")
+	logger.Close()
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"msg":"mutual"`) {
+			t.Errorf("Unexpected line %s", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected an entry over mTLS, failures %v", failures)
+	}
+
+	// * without a client certificate the server rejects the handshake
+	anonymous, _ := TLSFiles{CAFile: filepath.Join(dir, "ca.pem")}.Config()
+	sink := &NetworkSink{Addr: listener.Addr().String(), TLS: anonymous}
+	if err := sink.Write(Entry{Time: time.Now(), Level: "INFO", Message: "anonymous"}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	sink.Close()
+	select {
+	case line := <-lines:
+		t.Errorf("Unexpected line without a client certificate %s", line)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if _, err := (TLSFiles{CertFile: filepath.Join(dir, "client.pem")}).Config(); err == nil {
+		t.Error("Expected CertFile without KeyFile to be rejected")
+	}
+	if _, err := (TLSFiles{CAFile: filepath.Join(dir, "client.key")}).Config(); err == nil {
+		t.Error("Expected a CA file without certificates to be rejected")
+	}
+	if err := (&NetworkSink{Network: "udp", Addr: "127.0.0.1:1", TLS: config}).init(); err == nil {
+		t.Error("Expected TLS over UDP to be rejected")
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var mutex sync.Mutex
+	var sent, dead []string
+	failures := 2
+	send := func(entries []Entry) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if failures != 0 {
+			failures--
+			return errors.New("collector unavailable")
+		}
+		for _, entry := range entries {
+			sent = append(sent, entry.Message)
+		}
+		return nil
+	}
+	retry := &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  4 * time.Millisecond,
+		DeadLetter: func(entries []Entry, err error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, entry := range entries {
+				dead = append(dead, entry.Message+": "+err.Error())
+			}
+		},
+	}
+	flushUntil := func(b *batcher, done func() bool) {
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+			time.Sleep(5 * time.Millisecond)
+			b.Flush()
+			mutex.Lock()
+			finished := done()
+			mutex.Unlock()
+			if finished {
+				return
+			}
+		}
+	}
+
+	b, _ := newBatcher(2, time.Hour, retry, nil, nil, send)
+	b.add(Entry{Message: "a"})
+	b.add(Entry{Message: "b"})
+	b.add(Entry{Message: "c"})
+	flushUntil(b, func() bool { return len(sent) == 3 })
+	if strings.Join(sent, ",") != "a,b,c" || len(dead) != 0 {
+		t.Errorf("Expected a,b,c after two failures in order, got %v dead %v", sent, dead)
+	}
+
+	// * the first batch exhausts its attempts, the second starts over and succeeds
+	mutex.Lock()
+	sent, failures = nil, 3
+	mutex.Unlock()
+	b.add(Entry{Message: "d"})
+	b.add(Entry{Message: "e"})
+	b.add(Entry{Message: "f"})
+	flushUntil(b, func() bool { return len(sent) == 1 })
+	if strings.Join(sent, ",") != "f" || strings.Join(dead, ",") != "d: collector unavailable,e: collector unavailable" {
+		t.Errorf("Expected d and e to be dead-lettered, got sent %v dead %v", sent, dead)
+	}
+
+	// * what still fails on close is dead-lettered as well
+	mutex.Lock()
+	dead, failures = nil, -1
+	mutex.Unlock()
+	b.add(Entry{Message: "g"})
+	b.Close()
+	if len(dead) != 1 || !strings.HasPrefix(dead[0], "g:") {
+		t.Errorf("Expected g to be dead-lettered on close, got %v", dead)
+	}
+
+	jittered := &batcher{retry: &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}}
+	for attempts, want := range []time.Duration{100, 100, 200, 400, 800, 1000, 1000} {
+		jittered.attempts = attempts
+		want *= time.Millisecond
+		if delay := jittered.backoff(); delay > want || delay < want/2 {
+			t.Errorf("Expected a delay between %v and %v after %d attempts, got %v", want/2, want, attempts, delay)
+		}
+	}
+}
+
+func TestFlushDuringBackoff(t *testing.T) {
+	var mutex sync.Mutex
+	var sent []string
+	failures := 1
+	send := func(entries []Entry) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if failures != 0 {
+			failures--
+			return errors.New("collector unavailable")
+		}
+		for _, entry := range entries {
+			sent = append(sent, entry.Message)
+		}
+		return nil
+	}
+
+	b, _ := newBatcher(10, time.Hour, &RetryPolicy{MinBackoff: 50 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}, nil, nil, send)
+	b.add(Entry{Message: "a"})
+	b.Flush()
+	// * the first attempt failed, this one waits out the backoff and delivers
+	start := time.Now()
+	b.Flush()
+	mutex.Lock()
+	delivered := strings.Join(sent, ",")
+	mutex.Unlock()
+	if delivered != "a" {
+		t.Errorf("Expected Flush to deliver the held batch after the backoff, got %q", delivered)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Flush to wait no longer than the backoff, took %s", elapsed)
+	}
+
+	// * Close doesn't wait for a backoff a Flush is sitting in
+	mutex.Lock()
+	failures = 1
+	mutex.Unlock()
+	b, _ = newBatcher(10, time.Hour, &RetryPolicy{MinBackoff: time.Hour, MaxBackoff: time.Hour}, nil, nil, send)
+	b.add(Entry{Message: "b"})
+	b.Flush()
+	go b.Flush()
+	time.Sleep(20 * time.Millisecond)
+	start = time.Now()
+	b.Close()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Close to cut the backoff short, took %s", elapsed)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if strings.Join(sent, ",") != "a,b" {
+		t.Errorf("Expected the held batch to be sent on close, got %v", sent)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var mutex sync.Mutex
+	var sent []string
+	var reports []string
+	down := true
+	send := func(entries []Entry) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if down {
+			return errors.New("collector unavailable")
+		}
+		for _, entry := range entries {
+			sent = append(sent, entry.Message)
+		}
+		return nil
+	}
+	b, _ := newBatcher(1, time.Hour, nil, &CircuitBreaker{Threshold: 2, Cooldown: 50 * time.Millisecond}, nil, send)
+	b.setReport(func(err error) { reports = append(reports, err.Error()) })
+
+	// * queued directly so that no kick ships them one by one
+	b.mutex.Lock()
+	b.pending = []Entry{{Message: "a"}, {Message: "b"}, {Message: "c"}}
+	b.mutex.Unlock()
+	b.Flush()
+	if len(reports) != 3 || !strings.Contains(reports[2], "circuit open") {
+		t.Fatalf("Expected two failures then the circuit to open, got %v", reports)
+	}
+
+	// * while open nothing is attempted and new entries are dropped
+	b.add(Entry{Message: "d"})
+	b.Flush()
+	if len(reports) != 3 {
+		t.Errorf("Expected no attempts while open, got %v", reports)
+	}
+
+	mutex.Lock()
+	down = false
+	mutex.Unlock()
+	time.Sleep(60 * time.Millisecond)
+	b.add(Entry{Message: "e"})
+	b.Flush()
+	if strings.Join(sent, ",") != "c,e" {
+		t.Errorf("Expected the queued entry and a new one after the cooldown, got %v", sent)
+	}
+	if last := reports[len(reports)-1]; !strings.Contains(last, "dropped 1 entries while the circuit was open") {
+		t.Errorf("Expected the dropped entries to be reported on recovery, got %v", reports)
+	}
+
+	// * Close does not wait on an open circuit
+	mutex.Lock()
+	down = true
+	mutex.Unlock()
+	b.add(Entry{Message: "f"})
+	b.add(Entry{Message: "g"})
+	b.Flush()
+	b.add(Entry{Message: "h"})
+	b.Close()
+	if last := reports[len(reports)-1]; !strings.Contains(last, "dropped 1 entries") {
+		t.Errorf("Expected the rejected entry to be reported on close, got %v", reports)
+	}
+}
+
+func TestDiskQueue(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "queue")
+	var mutex sync.Mutex
+	var sent []string
+	down := true
+	send := func(entries []Entry) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if down {
+			return errors.New("collector unavailable")
+		}
+		for _, entry := range entries {
+			sent = append(sent, entry.Message)
+		}
+		return nil
+	}
+	queue := &DiskQueue{Dir: dir}
+
+	b, err := newBatcher(2, time.Hour, nil, nil, queue, send)
+	if err != nil {
+		t.Fatalf("Failed to open queue: %v", err)
+	}
+	for _, message := range []string{"a", "b", "c"} {
+		b.add(Entry{Time: time.Now(), Level: "INFO", Message: message})
+	}
+	b.Flush()
+	b.Close()
+	if len(sent) != 0 {
+		t.Fatalf("Expected nothing sent while down, got %v", sent)
+	}
+
+	// * a torn frame from a crash is ignored on the next start
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	file, _ := os.OpenFile(segments[len(segments)-1], os.O_WRONLY|os.O_APPEND, 0644)
+	file.Write([]byte{0, 0, 1, 0, '{'})
+	file.Close()
+
+	mutex.Lock()
+	down = false
+	mutex.Unlock()
+	b, err = newBatcher(2, time.Hour, nil, nil, queue, send)
+	if err != nil {
+		t.Fatalf("Failed to reopen queue: %v", err)
+	}
+	b.add(Entry{Time: time.Now(), Level: "INFO", Message: "d"})
+	b.Flush()
+	b.Close()
+	if strings.Join(sent, ",") != "a,b,c,d" {
+		t.Errorf("Expected the queued entries replayed in order, got %v", sent)
+	}
+
+	sent = nil
+	b, err = newBatcher(2, time.Hour, nil, nil, queue, send)
+	if err != nil {
+		t.Fatalf("Failed to reopen queue: %v", err)
+	}
+	b.Flush()
+	b.Close()
+	if len(sent) != 0 {
+		t.Errorf("Expected sent entries not to be replayed, got %v", sent)
+	}
+	if segments, _ := filepath.Glob(filepath.Join(dir, "*.wal")); len(segments) > 2 {
+		t.Errorf("Expected sent segments to be removed, got %v", segments)
+	}
+
+	if err := (&UnixSink{Path: "logs.sock", Queue: &DiskQueue{}}).init(); err == nil {
+		t.Error("Expected a queue without Dir to be rejected")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	RegisterCompression("x-reverse", func(w io.Writer) (io.WriteCloser, error) {
+		return &reverseWriter{w: w}, nil
+	})
+	encodings := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.Header.Get("Content-Encoding") {
+		case "deflate":
+			reader, err := zlib.NewReader(bytes.NewReader(body))
+			if err != nil {
+				t.Errorf("Failed to inflate: %v", err)
+				return
+			}
+			body, _ = io.ReadAll(reader)
+		case "x-reverse":
+			slices.Reverse(body)
+		}
+		if !json.Valid(body) {
+			t.Errorf("Expected a JSON body, got %q", body)
+		}
+		encodings <- r.Header.Get("Content-Encoding")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	for _, compression := range []string{"deflate", "x-reverse"} {
+		sink := &DatadogSink{APIKey: "secret", Endpoint: server.URL, Compression: compression}
+		if err := sink.Write(Entry{Time: time.Now(), Level: "INFO", Message: "compressed"}); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		sink.Close()
+		if encoding := <-encodings; encoding != compression {
+			t.Errorf("Expected Content-Encoding %s, got %s", compression, encoding)
+		}
+	}
+	if err := (&GCPSink{Compression: "zstd"}).init(); err == nil {
+		t.Error("Expected an unregistered compression to be rejected")
+	}
+
+	// * fluentd CompressedPackedForward carries the gzipped event stream as bin
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	events := make(chan []any, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		message, err := readMsgpack(bufio.NewReader(conn))
+		if err != nil {
+			t.Errorf("Failed to read: %v", err)
+			return
+		}
+		items := message.([]any)
+		if option := items[2].(map[string]any); option["compressed"] != "gzip" || option["size"] != int64(2) {
+			t.Errorf("Unexpected option %v", option)
+		}
+		reader, err := gzip.NewReader(strings.NewReader(items[1].(string)))
+		if err != nil {
+			t.Errorf("Failed to decompress: %v", err)
+			return
+		}
+		stream := bufio.NewReader(reader)
+		var decoded []any
+		for {
+			event, err := readMsgpack(stream)
+			if err != nil {
+				break
+			}
+			decoded = append(decoded, event)
+		}
+		events <- decoded
+	}()
+	fluentd := &FluentdSink{Addr: listener.Addr().String(), Compress: true}
+	fluentd.Write(Entry{Time: time.Now(), Level: "INFO", Message: "one"})
+	fluentd.Write(Entry{Time: time.Now(), Level: "INFO", Message: "two"})
+	fluentd.Close()
+	select {
+	case decoded := <-events:
+		if len(decoded) != 2 || decoded[1].([]any)[1].(map[string]any)["msg"] != "two" {
+			t.Errorf("Unexpected events %v", decoded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a compressed batch")
+	}
+}
+
+type reverseWriter struct {
+	w    io.Writer
+	data []byte
+}
+
+func (r *reverseWriter) Write(p []byte) (int, error) {
+	r.data = append(r.data, p...)
+	return len(p), nil
+}
+
+func (r *reverseWriter) Close() error {
+	slices.Reverse(r.data)
+	_, err := r.w.Write(r.data)
+	return err
+}
+
+func TestGRPCHandler(t *testing.T) {
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithLevel("INFO"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	server := httptest.NewUnstartedServer(logger.GRPCHandler())
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	call := func(method string, message []byte) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/gologger.v1.LogService/"+method, bytes.NewReader(grpcMessage(message)))
+		req.Header.Set("Content-Type", "application/grpc")
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to call %s: %v", method, err)
+		}
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("Expected HTTP/2, got %s", resp.Proto)
+		}
+		return resp
+	}
+
+	resp := call("Stream", appendProtoString(nil, 1, "ERROR"))
+	defer resp.Body.Close()
+	// * headers arrive once the subscription is in place
+	logger.Info("skipped")
+	logger.Error(nil, "failed", "retrying")
+
+	message, err := readGRPCMessage(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read entry: %v", err)
+	}
+	fields := map[int][]string{}
+	var unixNano uint64
+	readProto(message, func(field int, value uint64, data []byte) {
+		if field == 1 {
+			unixNano = value
+		}
+		fields[field] = append(fields[field], string(data))
+	})
+	if fields[2][0] != "ERROR" || fields[3][0] != "failed" || fields[4][0] != "retrying" || !json.Valid([]byte(fields[5][0])) {
+		t.Errorf("Unexpected entry %v", fields)
+	}
+	if time.Since(time.Unix(0, int64(unixNano))) > time.Minute {
+		t.Errorf("Unexpected time %d", unixNano)
+	}
+
+	// * temporary elevation for a debugging session
+	request := appendProtoString(nil, 1, "DEBUG")
+	request = binary.AppendUvarint(request, 2<<3)
+	request = binary.AppendUvarint(request, 60000)
+	setLevel := call("SetLevel", request)
+	reply, err := readGRPCMessage(setLevel.Body)
+	io.Copy(io.Discard, setLevel.Body)
+	setLevel.Body.Close()
+	if err != nil || !bytes.Equal(reply, appendProtoString(nil, 1, "DEBUG")) || setLevel.Trailer.Get("Grpc-Status") != "0" {
+		t.Errorf("Unexpected SetLevel reply %q %v trailer %v", reply, err, setLevel.Trailer)
+	}
+	if logger.Config().Level != "DEBUG" {
+		t.Errorf("Expected level DEBUG, got %s", logger.Config().Level)
+	}
+	setLevel = call("SetLevel", appendProtoString(nil, 1, "WARNING"))
+	io.Copy(io.Discard, setLevel.Body)
+	setLevel.Body.Close()
+	if logger.Config().Level != "WARNING" {
+		t.Errorf("Expected level WARNING, got %s", logger.Config().Level)
+	}
+	// * a temporary change can only lower the level, raising it is refused
+	request = appendProtoString(nil, 1, "ERROR")
+	request = binary.AppendUvarint(request, 2<<3)
+	request = binary.AppendUvarint(request, 60000)
+	quieter := call("SetLevel", request)
+	io.Copy(io.Discard, quieter.Body)
+	quieter.Body.Close()
+	if status := quieter.Trailer.Get("Grpc-Status"); status != "3" || logger.Config().Level != "WARNING" {
+		t.Errorf("Expected INVALID_ARGUMENT and level WARNING, got %q %s", status, logger.Config().Level)
+	}
+
+	invalid := call("SetLevel", appendProtoString(nil, 1, "LOUD"))
+	io.Copy(io.Discard, invalid.Body)
+	invalid.Body.Close()
+	if status := invalid.Trailer.Get("Grpc-Status"); status != "3" {
+		t.Errorf("Expected INVALID_ARGUMENT, got %q", status)
+	}
+	unknown := call("Tail", nil)
+	io.Copy(io.Discard, unknown.Body)
+	unknown.Body.Close()
+	if status := unknown.Trailer.Get("Grpc-Status"); status != "12" {
+		t.Errorf("Expected UNIMPLEMENTED, got %q", status)
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	fs := NewMemFS()
+	aggregator, err := NewWithOptions(WithFS(fs), WithPath("logs"), WithJSON())
+	if err != nil {
+		t.Fatalf("Failed to create aggregator: %v", err)
+	}
+	defer aggregator.Close()
+
+	path := filepath.Join(t.TempDir(), "logs.sock")
+	unix, err := ListenUnix(path, aggregator.Ingest)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer unix.Close()
+	tcp, err := ListenTCP("127.0.0.1:0", nil, aggregator.Ingest)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer tcp.Close()
+
+	for i, sink := range []Sink{&UnixSink{Path: path}, &NetworkSink{Addr: tcp.Addr().String(), Framing: "length"}} {
+		sibling, err := NewWithOptions(WithFS(DiscardFS()), WithPath("logs"), WithSink(sink))
+		if err != nil {
+			t.Fatalf("Failed to create sibling: %v", err)
+		}
+		sibling.Named("worker").Info("started", i)
+		sibling.Error(io.ErrUnexpectedEOF, "failed", i)
+		sibling.Close()
+	}
+
+	then := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	aggregator.Ingest(Entry{Time: then, Level: "notice", Message: "replayed"})
+	aggregator.Ingest(Entry{Time: then, Level: "LOUD", Message: "ignored"})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		output, _ := fs.ReadFile("logs/output.log")
+		errorLog, _ := fs.ReadFile("logs/error.log")
+		if strings.Count(string(output), "\n") >= 3 && strings.Count(string(errorLog), "\n") >= 2 {
+			for _, want := range []string{`"msg":"started","msg1":"0","logger":"worker"`, `"msg":"started","msg1":"1","logger":"worker"`, `"2020-01-02`, `"msg":"replayed"`} {
+				if !strings.Contains(string(output), want) {
+					t.Errorf("Expected %q in output.log, got %s", want, output)
+				}
+			}
+			if strings.Contains(string(output), "ignored") {
+				t.Error("Expected an unknown level to be rejected")
+			}
+			if !strings.Contains(string(errorLog), `"msg":"failed"`) {
+				t.Errorf("Expected errors in error.log, got %s", errorLog)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the sibling entries to arrive, got %s and %s", output, errorLog)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	requests := make(chan map[string]any, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/push" || r.Header.Get("X-Scope-OrgID") != "team-a" {
+			t.Errorf("Unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	fs := NewMemFS()
+	var stdout, mirror bytes.Buffer
+	logger, err := NewWithOptions(WithFS(fs), WithPath("logs"), WithJSON(),
+		WithSink(&WriterSink{Writer: &stdout, Format: "text", MinLevel: "INFO"}),
+		WithSink(&WriterSink{Writer: &mirror}),
+		WithSink(&LokiSink{URL: server.URL + "/", Labels: map[string]string{"app": "api"}, TenantID: "team-a", MinLevel: "WARNING"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Debug("cache miss")
+	logger.Info("started")
+	logger.Warn("slow", "900ms")
+	logger.Close()
+
+	output, _ := fs.ReadFile("logs/output.log")
+	debug, _ := fs.ReadFile("logs/debug.log")
+	if got := mirror.String(); got != string(debug)+string(output) {
+		t.Errorf("Expected the sink to share the file encoding, got %s", got)
+	}
+	if text := stdout.String(); strings.Contains(text, "cache miss") || !strings.Contains(text, "started\n") || !strings.Contains(text, "[WARNING] slow\n") || strings.Contains(text, `"msg"`) {
+		t.Errorf("Expected INFO and above as text, got %s", text)
+	}
+
+	select {
+	case body := <-requests:
+		streams, _ := body["streams"].([]any)
+		if len(streams) != 1 {
+			t.Fatalf("Expected one stream, got %v", body)
+		}
+		stream := streams[0].(map[string]any)
+		labels := stream["stream"].(map[string]any)
+		values := stream["values"].([]any)
+		if labels["app"] != "api" || labels["level"] != "warning" || len(values) != 1 {
+			t.Fatalf("Unexpected stream %v", stream)
+		}
+		value := values[0].([]any)
+		if _, err := strconv.ParseInt(value[0].(string), 10, 64); err != nil || !strings.Contains(value[1].(string), `"msg":"slow"`) {
+			t.Errorf("Unexpected value %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a push to Loki")
+	}
+
+	for _, sink := range []Sink{&WriterSink{}, &WriterSink{Writer: &stdout, Format: "xml"}, &LokiSink{}} {
+		if _, err := NewWithOptions(WithFS(NewMemFS()), WithSink(sink)); err == nil {
+			t.Errorf("Expected %+v to be rejected", sink)
+		}
+	}
+}
+
+type captureSink struct {
+	Prefix  string `json:"prefix"`
+	entries []string
+	closed  bool
+}
+
+func (c *captureSink) Write(entry Entry) error {
+	c.entries = append(c.entries, c.Prefix+entry.Message)
+	return nil
+}
+
+func (c *captureSink) Flush() {}
+
+func (c *captureSink) Close() { c.closed = true }
+
+func TestRegisterSink(t *testing.T) {
+	var captured *captureSink
+	RegisterSink("capture", func(options json.RawMessage) (Sink, error) {
+		captured = &captureSink{}
+		return captured, json.Unmarshal(options, captured)
+	})
+	requests := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- string(body)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "logger.yaml")
+	os.WriteFile(path, []byte(`
+path: logs
+sinks:
+  - name: capture
+    options:
+      prefix: "app: "
+  - name: loki
+    options:
+      url: `+server.URL+`
+      min_level: ERROR
+      interval: 1h
+      retry:
+        max_attempts: 2
+        min_backoff: 10ms
+`), 0644)
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	config.FS = NewMemFS()
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	loki := logger.sinks[1].(*LokiSink)
+	if loki.Interval != time.Hour || loki.Retry == nil || loki.Retry.MaxAttempts != 2 || loki.Retry.MinBackoff != 10*time.Millisecond {
+		t.Errorf("Expected the options to be decoded, got %+v", loki)
+	}
+	if current := logger.Config(); len(current.Sinks) != 0 || len(current.SinkConfigs) != 2 {
+		t.Errorf("Expected Config to keep the sinks by name, got %v and %v", current.Sinks, current.SinkConfigs)
+	}
+	logger.Info("started")
+	logger.Error(nil, "failed")
+	logger.Close()
+
+	if !slices.Equal(captured.entries, []string{"app: started", "app: failed"}) || !captured.closed {
+		t.Errorf("Expected the registered sink to receive entries and be closed, got %+v", captured)
+	}
+	select {
+	case body := <-requests:
+		if !strings.Contains(body, `\"msg\":\"failed\"`) || strings.Contains(body, "started") {
+			t.Errorf("Expected only the error in Loki, got %s", body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a push to Loki")
+	}
+
+	for _, sinks := range [][]SinkConfig{
+		{{Name: "missing"}},
+		{{Name: "loki", Options: json.RawMessage(`{"url": "http://loki", "colour": true}`)}},
+		{{Name: "loki", Options: json.RawMessage(`{"url": "http://loki", "interval": "soon"}`)}},
+		{{Name: "stdout", Options: json.RawMessage(`{"format": "xml"}`)}},
+	} {
+		if _, err := New(&Log{FS: NewMemFS(), Path: "logs", SinkConfigs: sinks}); err == nil {
+			t.Errorf("Expected %s to be rejected", sinks[0].Options)
+		}
+	}
+}
+
+func TestCloseContext(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	logger, err := NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithSink(&LokiSink{URL: slow.URL, Interval: time.Hour}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.OnInternalError(func(error) {})
+	for i := 0; i < 3; i++ {
+		logger.Error(nil, "failed", i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	dropped, err := logger.CloseContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || dropped != 3 {
+		t.Errorf("Expected 3 entries abandoned at the deadline, got %d and %v", dropped, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected CloseContext to return at the deadline, took %s", elapsed)
+	}
+	if dropped, err := logger.CloseContext(context.Background()); dropped != 0 || err != nil {
+		t.Errorf("Expected a second close to be a no-op, got %d and %v", dropped, err)
+	}
+
+	var deadLetters int
+	logger, err = NewWithOptions(WithFS(NewMemFS()), WithPath("logs"),
+		WithSink(&LokiSink{URL: failing.URL, Interval: time.Hour, Retry: &RetryPolicy{
+			MaxAttempts: 2,
+			DeadLetter:  func(entries []Entry, err error) { deadLetters += len(entries) },
+		}}),
+		WithSink(&WriterSink{Writer: io.Discard}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.OnInternalError(func(error) {})
+	logger.Warn("slow")
+	logger.Error(nil, "failed")
+	if dropped, err := logger.CloseContext(context.Background()); dropped != 2 || err != nil || deadLetters != 2 {
+		t.Errorf("Expected 2 dead-lettered entries counted, got %d, %d and %v", dropped, deadLetters, err)
+	}
+
+	// * a dead letter that logs runs while Close drains, it must not wait on the lock
+	logger, err = NewWithOptions(WithFS(NewMemFS()), WithPath("logs"),
+		WithSink(&LokiSink{URL: failing.URL, Interval: time.Hour, Retry: &RetryPolicy{
+			MaxAttempts: 1,
+			DeadLetter:  func(entries []Entry, err error) { logger.Warn("dead-lettered") },
+		}}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.OnInternalError(func(error) {})
+	logger.Info("started")
+	closed := make(chan struct{})
+	go func() {
+		logger.CloseContext(context.Background())
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("CloseContext deadlocked on a dead letter that logs")
+	}
+
+	logger, err = NewWithOptions(WithFS(NewMemFS()), WithPath("logs"), WithCloseTimeout(20*time.Millisecond), WithSink(&LokiSink{URL: slow.URL, Interval: time.Hour}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.OnInternalError(func(error) {})
+	logger.Info("started")
+	if err := logger.Close(); !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "1 entries undelivered") {
+		t.Errorf("Expected Close to give up after CloseTimeout, got %v", err)
+	}
+}
+
+func TestFlushContext(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	fs := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fs), WithPath("logs"), WithCloseTimeout(10*time.Millisecond), WithSink(&LokiSink{URL: slow.URL, Interval: time.Hour}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.OnInternalError(func(error) {})
+	if err := logger.FlushContext(context.Background()); err != nil {
+		t.Errorf("Expected nothing to flush, got %v", err)
+	}
+
+	logger.Info("started")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := logger.FlushContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to end the flush, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected FlushContext to return at the deadline, took %s", elapsed)
+	}
+	if output, _ := fs.ReadFile("logs/output.log"); !strings.Contains(string(output), "started") {
+		t.Errorf("Expected the file to be written, got %s", output)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := logger.FlushContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled context to be reported, got %v", err)
+	}
+	logger.Close()
+	if err := logger.FlushContext(context.Background()); err == nil {
+		t.Error("Expected flushing a closed logger to fail")
+	}
+}
+
+type syncCountFS struct {
+	*MemFS
+	mutex sync.Mutex
+	syncs int
+	// * returned by every Sync when set
+	err error
+}
+
+type syncCountFile struct {
+	File
+	fs *syncCountFS
+}
+
+func (s *syncCountFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	file, err := s.MemFS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &syncCountFile{File: file, fs: s}, nil
+}
+
+func (s *syncCountFS) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.syncs
+}
+
+func (f *syncCountFile) Sync() error {
+	f.fs.mutex.Lock()
+	f.fs.syncs++
+	err := f.fs.err
+	f.fs.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return f.File.Sync()
+}
+
+func TestFlushInterval(t *testing.T) {
+	fs := &syncCountFS{MemFS: NewMemFS()}
+	logger, err := NewWithOptions(WithFS(fs), WithPath("logs"), WithFlushInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("started")
+
+	deadline := time.Now().Add(time.Second)
+	for fs.count() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected periodic syncs, got %d", fs.count())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	logger.Close()
+	synced := fs.count()
+	time.Sleep(30 * time.Millisecond)
+	if fs.count() != synced {
+		t.Errorf("Expected syncing to stop on Close, got %d after %d", fs.count(), synced)
+	}
+
+	if _, err := NewWithOptions(WithFS(NewMemFS()), WithFlushInterval(-time.Second)); err == nil {
+		t.Error("Expected a negative interval to be rejected")
+	}
+	logger, _ = NewWithOptions(WithFS(NewMemFS()), WithPath("logs"))
+	defer logger.Close()
+	config := logger.Config()
+	config.FlushInterval = time.Second
+	if err := logger.Reconfigure(&config); err == nil || !strings.Contains(err.Error(), "flush_interval") {
+		t.Errorf("Expected flush_interval to need a new logger, got %v", err)
+	}
+}
+
+func TestSyncPolicy(t *testing.T) {
+	fs := &syncCountFS{MemFS: NewMemFS()}
+	logger, err := NewWithOptions(WithFS(fs), WithPath("logs"), WithSyncPolicy("error"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("started")
+	logger.Warn("slow")
+	if fs.count() != 0 {
+		t.Errorf("Expected no sync below ERROR, got %d", fs.count())
+	}
+	logger.Error(nil, "failed")
+	logger.Critical(nil, "down")
+	if fs.count() != 2 {
+		t.Errorf("Expected a sync per error entry, got %d", fs.count())
+	}
+
+	config := logger.Config()
+	config.SyncPolicy = "always"
+	if err := logger.Reconfigure(&config); err != nil {
+		t.Fatalf("Failed to reconfigure: %v", err)
+	}
+	synced := fs.count()
+	logger.Debug("cache miss")
+	if fs.count() != synced+1 {
+		t.Errorf("Expected every write to sync, got %d after %d", fs.count(), synced)
+	}
+	logger.Close()
+
+	for _, config := range []*Log{
+		{SyncPolicy: "sometimes"},
+		{SyncPolicy: "interval"},
+		{SyncPolicy: "never", FlushInterval: time.Second},
+	} {
+		config.FS = NewMemFS()
+		if _, err := New(config); err == nil || !strings.Contains(err.Error(), "sync_policy") {
+			t.Errorf("Expected %q to be rejected, got %v", config.SyncPolicy, err)
+		}
+	}
+	if _, err := New(&Log{FS: NewMemFS(), SyncPolicy: "interval", FlushInterval: time.Second}); err != nil {
+		t.Errorf("Expected interval with flush_interval to be accepted, got %v", err)
+	}
+}
+
+func TestFileModes(t *testing.T) {
+	fsys := NewMemFS()
+	fsys.MkdirAll("logs", 0755)
+	writeFile(fsys, "logs/output.log", []byte("old\n"), 0644)
+
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithDirMode(0700), WithFileMode(0600), WithChecksum(), WithAppendOnly())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	modes := map[string]os.FileMode{"logs": os.ModeDir | 0700, "logs/output.log": 0600, "logs/error.log": 0600}
+	for path, expected := range modes {
+		if info, err := fsys.Stat(path); err != nil {
+			t.Errorf("Failed to stat %s: %v", path, err)
+		} else if info.Mode() != expected {
+			t.Errorf("Expected %s to have mode %v, got %v", path, expected, info.Mode())
+		}
+	}
+
+	logger.Info("before rotation")
+	if err := logger.Rotate("output"); err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	if info, err := fsys.Stat("logs/output.log"); err != nil {
+		t.Errorf("Failed to stat output.log: %v", err)
+	} else if info.Mode() != 0600 {
+		t.Errorf("Expected the new output.log to be 0600, got %v", info.Mode())
+	}
+	var checksums []string
+	entries, _ := fsys.ReadDir("logs")
+	for _, entry := range entries {
+		info, _ := entry.Info()
+		switch {
+		case strings.HasSuffix(entry.Name(), checksumExt):
+			if info.Mode() != 0600 {
+				t.Errorf("Expected checksum %s to follow the file mode, got %v", entry.Name(), info.Mode())
+			}
+			checksums = append(checksums, entry.Name())
+		case strings.HasPrefix(entry.Name(), "output.log."):
+			if info.Mode() != 0400 {
+				t.Errorf("Expected sealed backup %s to be 0400, got %v", entry.Name(), info.Mode())
+			}
+		}
+	}
+	if len(checksums) == 0 {
+		t.Errorf("Expected a rotated backup with checksum")
+	}
+
+	for _, config := range []*Log{
+		{FS: NewMemFS(), DirMode: 0600},
+		{FS: NewMemFS(), FileMode: 0444},
+		{FS: NewMemFS(), FileMode: os.ModeSetuid | 0600},
+	} {
+		if _, err := New(config); err == nil {
+			t.Errorf("Expected dir_mode %v / file_mode %v to be rejected", config.DirMode, config.FileMode)
+		}
+	}
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "logger.json")
+	os.WriteFile(configPath, []byte(`{"dir_mode": "0750", "file_mode": "0640"}`), 0644)
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.DirMode != 0750 || config.FileMode != 0640 {
+		t.Errorf("Expected octal modes, got %v and %v", config.DirMode, config.FileMode)
+	}
+}
+
+type chownFS struct {
+	*MemFS
+	mutex  sync.Mutex
+	owners map[string][2]int
+}
+
+func (c *chownFS) Chown(name string, uid, gid int) error {
+	if _, err := c.Stat(name); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.owners[filepath.Clean(name)] = [2]int{uid, gid}
+	return nil
+}
+
+func (c *chownFS) Rename(oldpath, newpath string) error {
+	if err := c.MemFS.Rename(oldpath, newpath); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if owner, isExist := c.owners[filepath.Clean(oldpath)]; isExist {
+		delete(c.owners, filepath.Clean(oldpath))
+		c.owners[filepath.Clean(newpath)] = owner
+	}
+	return nil
+}
+
+func (c *chownFS) snapshot() map[string][2]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return maps.Clone(c.owners)
+}
+
+func TestOwnership(t *testing.T) {
+	fsys := &chownFS{MemFS: NewMemFS(), owners: map[string][2]int{}}
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithOwner("1234", "5678"), WithChecksum(), WithTenantField("tenant"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Tenant("acme").Info("hello")
+	if err := logger.Rotate("output"); err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	// * rotation refills the standby file in the background, Close waits for it
+	logger.Close()
+	owners := fsys.snapshot()
+	for _, path := range []string{"logs", "logs/output.log", "logs/error.log", "logs/" + metaFileName, "logs/acme", "logs/acme/output.log"} {
+		if owner := owners[filepath.Clean(path)]; owner != [2]int{1234, 5678} {
+			t.Errorf("Expected %s to be owned by 1234:5678, got %v", path, owner)
+		}
+	}
+	checksums := 0
+	for path, owner := range owners {
+		if strings.HasSuffix(path, checksumExt) && owner == [2]int{1234, 5678} {
+			checksums++
+		}
+	}
+	if checksums != 1 {
+		t.Errorf("Expected the rotated checksum to be owned, got %d", checksums)
+	}
+
+	if _, err := New(&Log{FS: NewMemFS(), Owner: "1234"}); err == nil || !strings.Contains(err.Error(), "does not support Chown") {
+		t.Errorf("Expected an FS without Chown to be rejected, got %v", err)
+	}
+	if _, err := New(&Log{FS: NewMemFS(), Owner: "no-such-user-for-go-logger"}); err == nil {
+		t.Errorf("Expected an unknown owner to be rejected")
+	}
+
+	// * changing to the current owner needs no privileges
+	dir := t.TempDir()
+	own, err := New(&Log{Path: dir, Owner: strconv.Itoa(os.Getuid()), Group: strconv.Itoa(os.Getgid())})
+	if err != nil {
+		t.Fatalf("Failed to create logger on disk: %v", err)
+	}
+	own.Info("on disk")
+	if err := own.Close(); err != nil {
+		t.Errorf("Failed to close: %v", err)
+	}
+}
+
+func TestSharedPath(t *testing.T) {
+	// * child process of the multi-process case below
+	if dir := os.Getenv("GOLOGGER_SHARED_CHILD"); dir != "" {
+		logger, err := New(&Log{Path: dir, SharedPath: true, BackupFormat: "sequence", MaxBackup: 1000})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for i := range 200 {
+			logger.Info(fmt.Sprintf("child %d entry %d", os.Getpid(), i))
+			if i%10 == 9 {
+				if err := logger.Rotate("output"); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+			}
+		}
+		logger.Close()
+		os.Exit(0)
+	}
+
+	fsys := NewMemFS()
+	first, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithSharedPath())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer first.Close()
+	second, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithSharedPath())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer second.Close()
+
+	first.Info("first before")
+	second.Info("second before")
+	if err := first.Rotate("output"); err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	// * the file second writes to is already a backup, it only reopens
+	if err := second.Rotate("output"); err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	first.Info("first after")
+	second.Info("second after")
+
+	entries, _ := fsys.ReadDir("logs")
+	var backups []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "output.log.") {
+			backups = append(backups, entry.Name())
+		}
+		if strings.HasSuffix(entry.Name(), ".next") {
+			t.Errorf("Expected no standby file with SharedPath, got %s", entry.Name())
+		}
+	}
+	if len(backups) != 1 {
+		t.Fatalf("Expected a single backup, got %v", backups)
+	}
+	backup, _ := fsys.ReadFile("logs/" + backups[0])
+	live, _ := fsys.ReadFile("logs/output.log")
+	if !strings.Contains(string(backup), "first before") || !strings.Contains(string(backup), "second before") {
+		t.Errorf("Expected both earlier entries in the backup, got %q", backup)
+	}
+	if !strings.Contains(string(live), "first after") || !strings.Contains(string(live), "second after") {
+		t.Errorf("Expected both later entries in the live file, got %q", live)
+	}
+
+	if _, err := New(&Log{FS: &chownFS{MemFS: NewMemFS()}, SharedPath: true}); err != nil {
+		t.Errorf("Expected a MemFS wrapper to keep Lock, got %v", err)
+	}
+	if _, err := New(&Log{FS: struct{ FS }{NewMemFS()}, SharedPath: true}); err == nil || !strings.Contains(err.Error(), "does not support Lock") {
+		t.Errorf("Expected an FS without Lock to be rejected, got %v", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	dir := t.TempDir()
+	var children []*exec.Cmd
+	for range 4 {
+		child := exec.Command(os.Args[0], "-test.run=^TestSharedPath$")
+		child.Env = append(os.Environ(), "GOLOGGER_SHARED_CHILD="+dir)
+		child.Stderr = os.Stderr
+		if err := child.Start(); err != nil {
+			t.Fatalf("Failed to start child: %v", err)
+		}
+		children = append(children, child)
+	}
+	for _, child := range children {
+		if err := child.Wait(); err != nil {
+			t.Fatalf("Child failed: %v", err)
+		}
+	}
+
+	// * a clobbered backup would lose the entries renamed over
+	files, _ := filepath.Glob(filepath.Join(dir, "output.log*"))
+	count := 0
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		count += strings.Count(string(data), " entry ")
+	}
+	if count != 4*200 {
+		t.Errorf("Expected %d entries across %d files, got %d", 4*200, len(files), count)
+	}
+}
+
+func TestWatchInterval(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithWatchInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	var mutex sync.Mutex
+	var internal []string
+	logger.OnInternalError(func(err error) {
+		mutex.Lock()
+		internal = append(internal, err.Error())
+		mutex.Unlock()
+	})
+	waitFor := func(text string) bool {
+		for range 200 {
+			mutex.Lock()
+			found := slices.ContainsFunc(internal, func(message string) bool { return strings.Contains(message, text) })
+			mutex.Unlock()
+			if found {
+				return true
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return false
+	}
+
+	logger.Info("before removal")
+	fsys.Remove("logs/output.log")
+	if !waitFor("output.log was removed or replaced externally") {
+		t.Fatalf("Expected the removal to be reported, got %v", internal)
+	}
+	logger.Info("after removal")
+	data, err := fsys.ReadFile("logs/output.log")
+	if err != nil || !strings.Contains(string(data), "after removal") || strings.Contains(string(data), "before removal") {
+		t.Errorf("Expected output.log to be recreated with later entries, got %q (%v)", data, err)
+	}
+
+	// * let a check record the size before truncating
+	time.Sleep(20 * time.Millisecond)
+	file, _ := fsys.OpenFile("logs/output.log", os.O_WRONLY|os.O_TRUNC, 0)
+	file.Close()
+	if !waitFor("output.log was truncated externally") {
+		t.Fatalf("Expected the truncation to be reported, got %v", internal)
+	}
+	logger.Info("after truncation")
+	if data, _ := fsys.ReadFile("logs/output.log"); !strings.Contains(string(data), "after truncation") {
+		t.Errorf("Expected writing to continue after truncation, got %q", data)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	mutex.Lock()
+	reported := len(internal)
+	mutex.Unlock()
+	if err := logger.RotateAll(); err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(internal) != reported {
+		t.Errorf("Expected rotation not to look like an external change, got %v", internal[reported:])
+	}
+}
+
+func TestInstance(t *testing.T) {
+	fsys := NewMemFS()
+	var loggers []*Logger
+	for _, instance := range []string{"web-1", "web-2"} {
+		logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithInstance(instance), WithMaxBackup(1),
+			WithRoute(Route{Prefix: "db", File: "db.log"}))
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		defer logger.Close()
+		loggers = append(loggers, logger)
+	}
+
+	for i, logger := range loggers {
+		logger.Info(fmt.Sprintf("entry from %d", i))
+		logger.Named("db").Info(fmt.Sprintf("query from %d", i))
+	}
+	for i, name := range []string{"output.web-1.log", "output.web-2.log", "db.web-1.log", "db.web-2.log"} {
+		data, err := fsys.ReadFile("logs/" + name)
+		if err != nil || !strings.Contains(string(data), fmt.Sprintf(" from %d", i%2)) {
+			t.Errorf("Expected %s to hold its own entries, got %q (%v)", name, data, err)
+		}
+	}
+	if _, err := fsys.Stat("logs/output.log"); err == nil {
+		t.Errorf("Expected no output.log without the instance")
+	}
+
+	// * backups of one instance don't count against the other
+	for range 3 {
+		loggers[0].Info("rotating")
+		if err := loggers[0].Rotate("output"); err != nil {
+			t.Fatalf("Failed to rotate: %v", err)
+		}
+	}
+	loggers[1].Rotate("output")
+	entries, _ := fsys.ReadDir("logs")
+	backups := map[string]int{}
+	for _, entry := range entries {
+		for _, instance := range []string{"web-1", "web-2"} {
+			if strings.HasPrefix(entry.Name(), "output."+instance+".log.") {
+				backups[instance]++
+			}
+		}
+	}
+	if backups["web-1"] != 1 || backups["web-2"] != 1 {
+		t.Errorf("Expected one backup per instance, got %v", backups)
+	}
+
+	dated, err := NewWithOptions(WithFS(fsys), WithPath("dated"), WithInstance("pid"), WithDatedFiles())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer dated.Close()
+	dated.Info("dated entry")
+	pid := strconv.Itoa(os.Getpid())
+	link, err := fsys.Lstat("dated/output." + pid + ".log")
+	if err != nil || link.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected output.%s.log to link the dated file, got %v", pid, err)
+	}
+	if data, _ := fsys.ReadFile("dated/output." + pid + "-" + time.Now().Format(datedLayout) + ".log"); !strings.Contains(string(data), "dated entry") {
+		t.Errorf("Expected the dated file to carry the instance, got %q", data)
+	}
+
+	if _, err := New(&Log{FS: NewMemFS(), Instance: "web.1"}); err == nil {
+		t.Errorf("Expected an instance with a dot to be rejected")
+	}
+}
+
+func TestScoped(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithLevel("INFO"), WithCaller())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	success := logger.Scoped()
+	success.Debug("success detail")
+	success.Info("success summary")
+	success.End()
+
+	failure := logger.Named("http").Scoped()
+	failure.Debug("loaded user")
+	failure.Trace("query plan")
+	failure.Warn("slow query")
+	failure.Error(errors.New("timeout"), "request failed")
+	failure.Debug("after failure")
+
+	debug, _ := fsys.ReadFile("logs/debug.log")
+	output, _ := fsys.ReadFile("logs/output.log")
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	if strings.Contains(string(debug), "success detail") {
+		t.Errorf("Expected the successful request's debug entries to be discarded, got %q", debug)
+	}
+	if !strings.Contains(string(output), "success summary") {
+		t.Errorf("Expected INFO to be written right away, got %q", output)
+	}
+	for _, text := range []string{"loaded user", "query plan", "after failure", "logger=http"} {
+		if !strings.Contains(string(debug), text) {
+			t.Errorf("Expected %q in debug.log after the failure, got %q", text, debug)
+		}
+	}
+	if !strings.Contains(string(debug), "logger_test.go:") {
+		t.Errorf("Expected buffered entries to keep their call site, got %q", debug)
+	}
+	if strings.Index(string(debug), "loaded user") > strings.Index(string(debug), "query plan") {
+		t.Errorf("Expected buffered entries in order, got %q", debug)
+	}
+	if !strings.Contains(string(errorLog), "request failed") {
+		t.Errorf("Expected the error in error.log, got %q", errorLog)
+	}
+
+	overflow := logger.Scoped()
+	for i := range maxScopedEntries + 5 {
+		overflow.Debug(fmt.Sprintf("step %d", i))
+	}
+	overflow.Critical(errors.New("crash"))
+	debug, _ = fsys.ReadFile("logs/debug.log")
+	if !strings.Contains(string(debug), "5 earlier entries dropped") || regexp.MustCompile(`step 4\b`).Match(debug) || !strings.Contains(string(debug), "step 5") {
+		t.Errorf("Expected the oldest entries to be dropped and reported")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	func() {
+		defer logger.Recover("worker stopped")
+		var values map[string]int
+		values["x"] = 1
+	}()
+
+	repanicked := func() (value any) {
+		defer func() { value = recover() }()
+		defer logger.Named("jobs").RecoverRepanic()
+		panic(fmt.Errorf("job failed: %w", io.ErrUnexpectedEOF))
+	}()
+	if repanicked == nil {
+		t.Error("Expected RecoverRepanic to panic again")
+	}
+
+	errorLog, _ := fsys.ReadFile("logs/error.log")
+	lines := strings.Split(strings.TrimSpace(string(errorLog)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 entries, got %q", errorLog)
+	}
+	var first, second map[string]any
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[1]), &second)
+	if first["level"] != "CRITICAL" || first["msg"] != "worker stopped" || !strings.Contains(fmt.Sprint(first["panic"]), "nil map") {
+		t.Errorf("Unexpected entry %v", first)
+	}
+	stack, _ := first["stack"].([]any)
+	if len(stack) == 0 || !strings.Contains(fmt.Sprint(stack[0]), "TestRecover.func1") {
+		t.Errorf("Expected the stack to start at the panic, got %v", first["stack"])
+	}
+	if second["msg"] != "panic: job failed: unexpected EOF" || second["logger"] != "jobs" || second["causes"] == nil {
+		t.Errorf("Unexpected entry %v", second)
+	}
+
+	handler := logger.RecoverHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), false)
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+	errorLog, _ = fsys.ReadFile("logs/error.log")
+	if !strings.Contains(string(errorLog), `"path":"/orders"`) || !strings.Contains(string(errorLog), `"panic":"boom"`) {
+		t.Errorf("Expected the request in the entry, got %q", errorLog)
+	}
+
+	aborted := func() (value any) {
+		defer func() { value = recover() }()
+		logger.RecoverHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}), false).ServeHTTP(httptest.NewRecorder(), r)
+		return nil
+	}()
+	after, _ := fsys.ReadFile("logs/error.log")
+	if aborted != http.ErrAbortHandler || len(after) != len(errorLog) {
+		t.Error("Expected http.ErrAbortHandler to pass through unlogged")
+	}
+}
+
+func TestCrashDump(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithRecentSize(10), WithCrashDump(), WithMaskKeys("password"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("loading config")
+	logger.With("password", "hunter2").Fatal(errors.New("config missing"), "startup failed")
+	logger.Error(errors.New("not a crash"))
+
+	crashes := func() []string {
+		var names []string
+		items, _ := fsys.ReadDir("logs")
+		for _, item := range items {
+			if strings.HasPrefix(item.Name(), "crash-") {
+				names = append(names, item.Name())
+			}
+		}
+		return names
+	}
+	names := crashes()
+	if len(names) != 1 || !strings.HasSuffix(names[0], ".txt") {
+		t.Fatalf("Expected one crash report, got %v", names)
+	}
+	report, _ := fsys.ReadFile("logs/" + names[0])
+	for _, text := range []string{"[FATAL] startup failed", "config missing", "goroutine ", "TestCrashDump", "recent entries (2)", "loading config"} {
+		if !strings.Contains(string(report), text) {
+			t.Errorf("Expected %q in the crash report, got %q", text, report)
+		}
+	}
+	if strings.Contains(string(report), "hunter2") {
+		t.Error("Expected the crash report to be redacted like the log")
+	}
+
+	time.Sleep(time.Millisecond)
+	func() {
+		defer logger.Recover()
+		panic("boom")
+	}()
+	if names = crashes(); len(names) != 2 {
+		t.Fatalf("Expected a crash report for the panic, got %v", names)
+	}
+	report, _ = fsys.ReadFile("logs/" + names[1])
+	if !strings.Contains(string(report), "panic: boom") {
+		t.Errorf("Expected the panic in the crash report, got %q", report)
+	}
+
+	entries, err := logger.Query(QueryOptions{Contains: "goroutine"})
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected crash reports to stay out of Query, got %v %v", entries, err)
+	}
+
+	// * crashes within one clock tick keep separate reports
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	fixed, err := NewWithOptions(WithFS(fsys), WithPath("fixed"), WithCrashDump(), WithClock(ClockFunc(func() time.Time { return at })))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer fixed.Close()
+	fixed.Fatal(nil, "first crash")
+	func() {
+		defer fixed.Recover()
+		panic("second crash")
+	}()
+	first, _ := fsys.ReadFile("fixed/crash-20250601_120000.000000.txt")
+	second, _ := fsys.ReadFile("fixed/crash-20250601_120000.000000-1.txt")
+	if !strings.Contains(string(first), "first crash") || !strings.Contains(string(second), "panic: second crash") {
+		t.Errorf("Expected both crash reports, got %q and %q", first, second)
+	}
+}
+
+func TestStartup(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON(), WithBuildFields(), WithInstance("api-1"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Startup()
+	logger.Info("ready")
+
+	output, _ := fsys.ReadFile("logs/output.api-1.log")
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 entries, got %q", output)
+	}
+	var startup, ready map[string]any
+	json.Unmarshal([]byte(lines[0]), &startup)
+	json.Unmarshal([]byte(lines[1]), &ready)
+
+	b := currentBuild()
+	info, _ := startup["build"].(map[string]any)
+	config, _ := startup["config"].(map[string]any)
+	if startup["level"] != "NOTICE" || !strings.HasPrefix(fmt.Sprint(startup["msg"]), "Starting ") {
+		t.Errorf("Unexpected startup entry %v", startup)
+	}
+	if info["go"] != runtime.Version() || info["module"] != b.module || info["version"] != b.version {
+		t.Errorf("Expected the build info, got %v", info)
+	}
+	if config["path"] != "logs" || config["type"] != "json" || config["instance"] != "api-1" {
+		t.Errorf("Expected the key config values, got %v", config)
+	}
+	for _, field := range buildFields() {
+		if ready[field.Key] != field.Value {
+			t.Errorf("Expected %s=%v on every entry, got %v", field.Key, field.Value, ready)
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON(), WithSequence())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.AddHook(func(e *Entry) *Entry {
+		if e.Message == "dropped" {
+			return nil
+		}
+		return e
+	})
+	logger.Debug("first")
+	logger.Info("second")
+	logger.Info("dropped")
+	logger.Error(errors.New("third"))
+	// * numbered here like any other entry, not by the process that sent it
+	logger.Ingest(Entry{Time: time.Now(), Level: "INFO", Message: "ingested", Fields: []Field{{Key: "seq", Value: 99}}})
+
+	seq := map[string]float64{}
+	for _, name := range []string{"debug.log", "output.log", "error.log"} {
+		data, _ := fsys.ReadFile("logs/" + name)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var entry map[string]any
+			json.Unmarshal([]byte(line), &entry)
+			value, _ := entry["seq"].(float64)
+			seq[fmt.Sprint(entry["msg"])] = value
+		}
+	}
+	if seq["first"] != 1 || seq["second"] != 2 || seq["third"] != 4 || seq["ingested"] != 5 {
+		t.Errorf("Expected numbers across files with a gap for the dropped entry, got %v", seq)
+	}
+}
+
+func TestEntryID(t *testing.T) {
+	fsys := NewMemFS()
+	logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithJSON(), WithEntryID(), WithRecentSize(10))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	for i := range 3 {
+		logger.Info("request", i)
+	}
+
+	output, _ := fsys.ReadFile("logs/output.log")
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	recent := logger.Recent(0, "")
+	seen := map[string]bool{}
+	for i, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		var entry map[string]any
+		json.Unmarshal([]byte(line), &entry)
+		id, _ := entry["entry_id"].(string)
+		if !pattern.MatchString(id) || seen[id] {
+			t.Errorf("Expected a unique UUID, got %q", id)
+		}
+		seen[id] = true
+		if len(recent) != 3 || !slices.Contains(recent[i].Fields, Field{Key: "entry_id", Value: id}) {
+			t.Errorf("Expected subscribers and sinks to get the same id %q", id)
+		}
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected 3 entries, got %q", output)
+	}
+
+	// * notices the logger writes itself get one too, an ingested entry keeps its own
+	logger.SetLevel("INFO")
+	restore := logger.Elevate("DEBUG", time.Hour)
+	restore()
+	logger.Ingest(Entry{Time: time.Now(), Level: "INFO", Message: "ingested", Fields: []Field{{Key: "entry_id", Value: "remote-id"}}})
+	recent = logger.Recent(0, "")
+	if len(recent) != 6 {
+		t.Fatalf("Expected the two notices and the ingested entry, got %v", recent)
+	}
+	for _, entry := range recent[3:] {
+		id := ""
+		for _, field := range entry.Fields {
+			if field.Key == "entry_id" {
+				id, _ = field.Value.(string)
+			}
+		}
+		if entry.Message == "ingested" && id != "remote-id" || entry.Message != "ingested" && !pattern.MatchString(id) {
+			t.Errorf("Expected an entry_id on %q, got %q", entry.Message, id)
+		}
+	}
+}
+
+func TestTimePrecision(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 30, 45, 123456789, time.UTC).Local()
+	cases := []struct {
+		precision string
+		fraction  string
+	}{
+		{"second", ""},
+		{"millisecond", ".123"},
+		{"microsecond", ".123456"},
+		{"nanosecond", ".123456789"},
+	}
+	for _, c := range cases {
+		for _, logType := range []string{"text", "json"} {
+			fsys := NewMemFS()
+			logger, err := NewWithOptions(WithFS(fsys), WithPath("logs"), WithConfig(func(l *Log) { l.Type = logType }),
+				WithTimePrecision(c.precision), WithClock(ClockFunc(func() time.Time { return at })))
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			logger.Info("hello", "world")
+			output, _ := fsys.ReadFile("logs/output.log")
+			entries, queryErr := logger.Query(QueryOptions{})
+			logger.Close()
+
+			expected := at.Format("2006/01/02 15:04:05") + c.fraction + " "
+			if logType == "json" {
+				expected = `"time":"` + at.Format("2006-01-02T15:04:05") + c.fraction + at.Format("Z07:00") + `"`
+			}
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("%s %s: expected %q in %q", c.precision, logType, expected, output)
+			}
+			if logType == "text" && strings.Count(string(output), expected) != 2 {
+				t.Errorf("%s: expected branches to share the header's timestamp, got %q", c.precision, output)
+			}
+			if queryErr != nil || len(entries) != 1 || !entries[0].Time.Equal(at.Truncate(timePrecisions[c.precision].unit)) {
+				t.Errorf("%s %s: expected the entry to read back at its precision, got %v %v", c.precision, logType, entries, queryErr)
+			}
+		}
+	}
+
+	if _, err := NewWithOptions(WithFS(NewMemFS()), WithTimePrecision("minute")); err == nil {
+		t.Error("Expected an unknown precision to be rejected")
+	}
+}
@@ -0,0 +1,144 @@
+package loggertest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	goLogger "github.com/pardnchiu/go-logger"
+)
+
+type TestLogger struct {
+	*goLogger.Logger
+	FS      *goLogger.MemFS
+	t       testing.TB
+	mutex   sync.Mutex
+	entries []goLogger.Entry
+}
+
+// * files live in memory, see FS to inspect them, entries are captured as written
+func NewTestLogger(t testing.TB, options ...goLogger.Option) *TestLogger {
+	t.Helper()
+
+	fsys := goLogger.NewMemFS()
+	options = append([]goLogger.Option{goLogger.WithPath("logs"), goLogger.WithFS(fsys)}, options...)
+	logger, err := goLogger.NewWithOptions(options...)
+	if err != nil {
+		t.Fatalf("Failed to create test logger: %v", err)
+	}
+
+	tl := &TestLogger{Logger: logger, FS: fsys, t: t}
+	logger.OnWrite(func(entry goLogger.Entry, err error) {
+		tl.mutex.Lock()
+		defer tl.mutex.Unlock()
+
+		tl.entries = append(tl.entries, entry)
+	})
+	t.Cleanup(func() { logger.Close() })
+	return tl
+}
+
+func (tl *TestLogger) Entries() []goLogger.Entry {
+	tl.mutex.Lock()
+	defer tl.mutex.Unlock()
+
+	return append([]goLogger.Entry(nil), tl.entries...)
+}
+
+func (tl *TestLogger) Reset() {
+	tl.mutex.Lock()
+	defer tl.mutex.Unlock()
+
+	tl.entries = nil
+}
+
+// * substring of the message, an extra message or a field value
+func (tl *TestLogger) Find(level string, substring string) []goLogger.Entry {
+	level = normalize(level)
+
+	var found []goLogger.Entry
+	for _, entry := range tl.Entries() {
+		if level != "" && entry.Level != level {
+			continue
+		}
+		if contains(entry, substring) {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+func (tl *TestLogger) AssertLogged(level string, substring string) {
+	tl.t.Helper()
+
+	if len(tl.Find(level, substring)) == 0 {
+		tl.t.Errorf("Expected %s entry containing %q, got:\n%s", describe(level), substring, tl.dump())
+	}
+}
+
+func (tl *TestLogger) AssertNotLogged(level string, substring string) {
+	tl.t.Helper()
+
+	if found := tl.Find(level, substring); len(found) > 0 {
+		tl.t.Errorf("Expected no %s entry containing %q, got %d:\n%s", describe(level), substring, len(found), tl.dump())
+	}
+}
+
+func (tl *TestLogger) AssertCount(level string, count int) {
+	tl.t.Helper()
+
+	if found := tl.Find(level, ""); len(found) != count {
+		tl.t.Errorf("Expected %d %s entries, got %d:\n%s", count, describe(level), len(found), tl.dump())
+	}
+}
+
+func (tl *TestLogger) dump() string {
+	var builder strings.Builder
+	for _, entry := range tl.Entries() {
+		fmt.Fprintf(&builder, "  [%s] %s", entry.Level, entry.Message)
+		for _, data := range entry.Data {
+			fmt.Fprintf(&builder, " | %s", data)
+		}
+		for _, field := range entry.Fields {
+			fmt.Fprintf(&builder, " | %s=%v", field.Key, field.Value)
+		}
+		builder.WriteByte('\n')
+	}
+	if builder.Len() == 0 {
+		return "  (no entries)\n"
+	}
+	return builder.String()
+}
+
+func contains(entry goLogger.Entry, substring string) bool {
+	if strings.Contains(entry.Message, substring) {
+		return true
+	}
+	for _, data := range entry.Data {
+		if strings.Contains(data, substring) {
+			return true
+		}
+	}
+	for _, field := range entry.Fields {
+		if strings.Contains(fmt.Sprint(field.Value), substring) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(level string) string {
+	level = strings.ToUpper(level)
+	if level == "WARN" {
+		return "WARNING"
+	}
+	return level
+}
+
+func describe(level string) string {
+	if level == "" {
+		return "any"
+	}
+	return normalize(level)
+}
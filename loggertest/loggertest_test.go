@@ -0,0 +1,51 @@
+package loggertest
+
+import (
+	"errors"
+	"testing"
+
+	goLogger "github.com/pardnchiu/go-logger"
+)
+
+func TestCapture(t *testing.T) {
+	logger := NewTestLogger(t, goLogger.WithLevel("INFO"))
+
+	logger.Debug("suppressed")
+	logger.Info("user signed in", "alice")
+	logger.Error(errors.New("timeout"), "Failed to charge card")
+
+	logger.AssertLogged("info", "alice")
+	logger.AssertLogged("ERROR", "timeout")
+	logger.AssertNotLogged("", "suppressed")
+	logger.AssertCount("", 2)
+
+	if entries := logger.Entries(); len(entries) != 2 || entries[0].Message != "user signed in" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+
+	logger.Reset()
+	logger.AssertCount("", 0)
+}
+
+func TestAssertionFailure(t *testing.T) {
+	recorder := &failureRecorder{TB: t}
+	logger := NewTestLogger(t)
+	logger.t = recorder
+
+	logger.Warn("disk almost full")
+	logger.AssertLogged("ERROR", "disk")
+	if !recorder.failed {
+		t.Error("AssertLogged should fail for a different level")
+	}
+}
+
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *failureRecorder) Helper() {}
+
+func (r *failureRecorder) Errorf(format string, args ...any) {
+	r.failed = true
+}
@@ -0,0 +1,110 @@
+package goLogger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 以換行分隔的 JSON 推送至 Logstash tcp 輸入（codec => json_lines）
+type LogstashSink struct {
+	Addr      string          // Logstash 位址，如 "logstash:5000"
+	TLS       *tls.Config     // 設定後以 TLS 連線，可由 TLSFiles 載入憑證
+	MinLevel  string          // 送出的最低層級，預設全部
+	BatchSize int             // 單次寫入最多筆數，預設 100
+	Interval  time.Duration   // 批次送出的間隔，預設 5 秒
+	Retry     *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker   *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue     *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	Timeout   time.Duration   // 寫入逾時，預設 10 秒
+	once      sync.Once
+	batcher   *batcher
+	conn      netConn
+	minLevel  string
+	initErr   error
+}
+
+func (s *LogstashSink) init() error {
+	s.once.Do(func() {
+		if s.Addr == "" {
+			s.initErr = fmt.Errorf("Addr is required")
+			return
+		}
+		if s.MinLevel != "" {
+			if s.minLevel, s.initErr = parseLevel(s.MinLevel); s.initErr != nil {
+				return
+			}
+		}
+		s.conn = netConn{network: "tcp", addr: s.Addr, tls: s.TLS}
+		s.batcher, s.initErr = newBatcher(s.BatchSize, s.Interval, s.Retry, s.Breaker, s.Queue, s.send)
+	})
+	return s.initErr
+}
+
+func (s *LogstashSink) reportErrors(report func(error)) {
+	if s.init() == nil {
+		s.batcher.setReport(report)
+	}
+}
+
+func (s *LogstashSink) Write(entry Entry) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+	if s.minLevel != "" && levelRank[entry.Level] < levelRank[s.minLevel] {
+		return nil
+	}
+	s.batcher.add(entry)
+	return nil
+}
+
+func (s *LogstashSink) Flush() {
+	if s.init() == nil {
+		s.batcher.Flush()
+	}
+}
+
+func (s *LogstashSink) Close() {
+	if s.init() == nil {
+		s.batcher.Close()
+		s.conn.close()
+	}
+}
+
+func (s *LogstashSink) undelivered() int {
+	if s.init() != nil {
+		return 0
+	}
+	return s.batcher.undelivered()
+}
+
+func (s *LogstashSink) queued() int {
+	if s.init() != nil {
+		return 0
+	}
+	return s.batcher.queued()
+}
+
+func (s *LogstashSink) send(entries []Entry) error {
+	var buf bytes.Buffer
+	for i := range entries {
+		// * same lines as the JSON files, level names included
+		buf.Write(encodeJSON(&entries[i]))
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	// * an idle connection may have been dropped by the peer, retry once on a fresh one
+	err := s.conn.write(buf.Bytes(), timeout)
+	if err != nil {
+		err = s.conn.write(buf.Bytes(), timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to send to Logstash %s: %w", s.Addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package goLogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 將日誌批次推送至 Grafana Loki，每個層級為一條 stream
+type LokiSink struct {
+	URL         string            // Loki 位址，如 "http://loki:3100"
+	Labels      map[string]string // stream 標籤，如 {"app": "api"}，另自動附加 level
+	Format      string            // 日誌行格式，"json" 或 "text"，預設 "json"
+	TenantID    string            // 多租戶時的 X-Scope-OrgID
+	Username    string            // Basic 驗證帳號
+	Password    string            // Basic 驗證密碼
+	MinLevel    string            // 送出的最低層級，預設全部
+	Compression string            // 請求內容的壓縮方式，"gzip"、"deflate" 或以 RegisterCompression 註冊的名稱
+	BatchSize   int               // 單次請求最多筆數，預設 100
+	Interval    time.Duration     // 批次送出的間隔，預設 5 秒
+	Retry       *RetryPolicy      // 失敗時的重試策略，預設不重試
+	Breaker     *CircuitBreaker   // 連續失敗時暫停嘗試，預設不啟用
+	Queue       *DiskQueue        // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	Client      *http.Client      // 預設逾時 30 秒的 http.Client
+	once        sync.Once
+	batcher     *batcher
+	minLevel    string
+	initErr     error
+}
+
+func (k *LokiSink) init() error {
+	k.once.Do(func() {
+		if k.URL == "" {
+			k.initErr = fmt.Errorf("URL is required")
+			return
+		}
+		if k.initErr = checkFormat(k.Format); k.initErr != nil {
+			return
+		}
+		if k.MinLevel != "" {
+			if k.minLevel, k.initErr = parseLevel(k.MinLevel); k.initErr != nil {
+				return
+			}
+		}
+		if k.initErr = checkCompression(k.Compression); k.initErr != nil {
+			return
+		}
+		k.batcher, k.initErr = newBatcher(k.BatchSize, k.Interval, k.Retry, k.Breaker, k.Queue, k.send)
+	})
+	return k.initErr
+}
+
+func (k *LokiSink) reportErrors(report func(error)) {
+	if k.init() == nil {
+		k.batcher.setReport(report)
+	}
+}
+
+func (k *LokiSink) Write(entry Entry) error {
+	if err := k.init(); err != nil {
+		return err
+	}
+	if k.minLevel != "" && levelRank[entry.Level] < levelRank[k.minLevel] {
+		return nil
+	}
+	k.batcher.add(entry)
+	return nil
+}
+
+func (k *LokiSink) Flush() {
+	if k.init() == nil {
+		k.batcher.Flush()
+	}
+}
+
+func (k *LokiSink) Close() {
+	if k.init() == nil {
+		k.batcher.Close()
+	}
+}
+
+func (k *LokiSink) undelivered() int {
+	if k.init() != nil {
+		return 0
+	}
+	return k.batcher.undelivered()
+}
+
+func (k *LokiSink) queued() int {
+	if k.init() != nil {
+		return 0
+	}
+	return k.batcher.queued()
+}
+
+func (k *LokiSink) send(entries []Entry) error {
+	format := k.Format
+	if format == "" {
+		format = "json"
+	}
+	// * streams in order of first appearance, values keep the batch order
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	var streams []*stream
+	byLevel := map[string]*stream{}
+	for i := range entries {
+		s, ok := byLevel[entries[i].Level]
+		if !ok {
+			labels := maps.Clone(k.Labels)
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels["level"] = strings.ToLower(entries[i].Level)
+			s = &stream{Stream: labels}
+			byLevel[entries[i].Level] = s
+			streams = append(streams, s)
+		}
+		line := bytes.TrimSuffix(encodeLine(&entries[i], format), []byte("\n"))
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(entries[i].Time.UnixNano(), 10), string(line)})
+	}
+	body, err := json.Marshal(map[string]any{"streams": streams})
+	if err != nil {
+		return fmt.Errorf("Failed to encode Loki batch: %w", err)
+	}
+
+	headers := map[string]string{}
+	if k.TenantID != "" {
+		headers["X-Scope-OrgID"] = k.TenantID
+	}
+	if k.Username != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(k.Username+":"+k.Password))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	target := strings.TrimSuffix(k.URL, "/") + "/loki/api/v1/push"
+	if err := postBatch(ctx, k.Client, target, body, k.Compression, headers); err != nil {
+		return fmt.Errorf("Failed to send to Loki: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package goLogger
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// * in-memory FS for tests and environments without a writable disk,
+// * open files keep writing to their node after rename or remove like POSIX
+type MemFS struct {
+	mutex sync.Mutex
+	nodes map[string]*memNode
+	locks map[string]chan struct{}
+}
+
+type memNode struct {
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+	target  string
+}
+
+type memFile struct {
+	fs       *MemFS
+	node     *memNode
+	name     string
+	offset   int64
+	append   bool
+	readable bool
+	writable bool
+	closed   bool
+}
+
+type memInfo struct {
+	name   string
+	node   memNode
+	source *memNode
+}
+
+func NewMemFS() *MemFS {
+	return &MemFS{
+		nodes: map[string]*memNode{
+			".": {mode: os.ModeDir | 0755, modTime: time.Now()},
+			"/": {mode: os.ModeDir | 0755, modTime: time.Now()},
+		},
+		locks: map[string]chan struct{}{},
+	}
+}
+
+// * convenience for assertions, follows symlinks
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, err := m.resolve(name, "open")
+	if err != nil {
+		return nil, err
+	}
+	if node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	return append([]byte(nil), node.data...), nil
+}
+
+func (m *MemFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, err := m.resolve(name, "open")
+	switch {
+	case err == nil && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case err == nil && node.mode.IsDir() && flag&(os.O_WRONLY|os.O_RDWR) != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	case err != nil && flag&os.O_CREATE == 0:
+		return nil, err
+	case err != nil:
+		path := m.target(name)
+		if parent, isExist := m.nodes[filepath.Dir(path)]; !isExist || !parent.mode.IsDir() {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		node = &memNode{mode: perm.Perm(), modTime: time.Now()}
+		m.nodes[path] = node
+	}
+
+	if flag&os.O_TRUNC != 0 && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		node.data = nil
+		node.modTime = time.Now()
+	}
+	return &memFile{
+		fs:       m,
+		node:     node,
+		name:     name,
+		append:   flag&os.O_APPEND != 0,
+		readable: flag&os.O_WRONLY == 0,
+		writable: flag&(os.O_WRONLY|os.O_RDWR) != 0,
+	}, nil
+}
+
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, err := m.resolve(name, "stat")
+	if err != nil {
+		return nil, err
+	}
+	return memInfo{name: filepath.Base(name), node: *node, source: node}, nil
+}
+
+func (m *MemFS) Lstat(name string) (os.FileInfo, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, isExist := m.nodes[filepath.Clean(name)]
+	if !isExist {
+		return nil, &fs.PathError{Op: "lstat", Path: name, Err: fs.ErrNotExist}
+	}
+	return memInfo{name: filepath.Base(name), node: *node, source: node}, nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]os.DirEntry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	dir, err := m.resolve(name, "readdir")
+	if err != nil {
+		return nil, err
+	}
+	if !dir.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	path := m.target(name)
+	var entries []os.DirEntry
+	for key, node := range m.nodes {
+		if key != path && filepath.Dir(key) == path {
+			entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: filepath.Base(key), node: *node, source: node}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	path = filepath.Clean(path)
+	var parts []string
+	for p := path; p != "." && p != "/"; p = filepath.Dir(p) {
+		parts = append(parts, p)
+	}
+	for i := len(parts) - 1; i >= 0; i-- {
+		node, isExist := m.nodes[parts[i]]
+		if !isExist {
+			m.nodes[parts[i]] = &memNode{mode: os.ModeDir | perm.Perm(), modTime: time.Now()}
+			continue
+		}
+		if !node.mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: parts[i], Err: fs.ErrExist}
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	node, isExist := m.nodes[oldpath]
+	if !isExist {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if parent, isExist := m.nodes[filepath.Dir(newpath)]; !isExist || !parent.mode.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+
+	delete(m.nodes, oldpath)
+	m.nodes[newpath] = node
+	if node.mode.IsDir() {
+		prefix := oldpath + string(filepath.Separator)
+		for key, child := range m.nodes {
+			if strings.HasPrefix(key, prefix) {
+				delete(m.nodes, key)
+				m.nodes[filepath.Join(newpath, strings.TrimPrefix(key, prefix))] = child
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	path := filepath.Clean(name)
+	node, isExist := m.nodes[path]
+	if !isExist {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if node.mode.IsDir() {
+		for key := range m.nodes {
+			if key != path && filepath.Dir(key) == path {
+				return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrExist}
+			}
+		}
+	}
+	delete(m.nodes, path)
+	return nil
+}
+
+func (m *MemFS) Chmod(name string, mode os.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, err := m.resolve(name, "chmod")
+	if err != nil {
+		return err
+	}
+	node.mode = node.mode&^os.ModePerm | mode.Perm()
+	return nil
+}
+
+func (m *MemFS) Symlink(oldname, newname string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	path := filepath.Clean(newname)
+	if _, isExist := m.nodes[path]; isExist {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+	m.nodes[path] = &memNode{mode: os.ModeSymlink | 0777, modTime: time.Now(), target: oldname}
+	return nil
+}
+
+// * exclusive like flock, loggers sharing a MemFS behave like processes sharing a directory
+func (m *MemFS) Lock(name string) (func() error, error) {
+	m.mutex.Lock()
+	lock, isExist := m.locks[filepath.Clean(name)]
+	if !isExist {
+		lock = make(chan struct{}, 1)
+		m.locks[filepath.Clean(name)] = lock
+	}
+	m.mutex.Unlock()
+
+	lock <- struct{}{}
+	return func() error {
+		<-lock
+		return nil
+	}, nil
+}
+
+// * called under lock, follows symlinks like the OS does for open and stat
+func (m *MemFS) resolve(name string, op string) (*memNode, error) {
+	path := filepath.Clean(name)
+	for range 40 {
+		node, isExist := m.nodes[path]
+		if !isExist {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		if node.mode&os.ModeSymlink == 0 {
+			return node, nil
+		}
+		path = m.linkTarget(path, node)
+	}
+	return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+}
+
+// * path a new file would be created at, the final symlink is followed
+func (m *MemFS) target(name string) string {
+	path := filepath.Clean(name)
+	for range 40 {
+		node, isExist := m.nodes[path]
+		if !isExist || node.mode&os.ModeSymlink == 0 {
+			return path
+		}
+		path = m.linkTarget(path, node)
+	}
+	return path
+}
+
+func (m *MemFS) linkTarget(path string, node *memNode) string {
+	if filepath.IsAbs(node.target) {
+		return filepath.Clean(node.target)
+	}
+	return filepath.Join(filepath.Dir(path), node.target)
+}
+
+func (f *memFile) Name() string {
+	return f.name
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if f.closed || !f.readable {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	if f.offset >= int64(len(f.node.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.node.data[f.offset:])
+	f.offset += int64(n)
+	return n, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if f.closed {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrClosed}
+	}
+	if !f.writable {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrPermission}
+	}
+	if f.append {
+		f.offset = int64(len(f.node.data))
+	}
+	if end := f.offset + int64(len(p)); end > int64(len(f.node.data)) {
+		f.node.data = append(f.node.data, make([]byte, end-int64(len(f.node.data)))...)
+	}
+	copy(f.node.data[f.offset:], p)
+	f.offset += int64(len(p))
+	f.node.modTime = time.Now()
+	return len(p), nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += int64(len(f.node.data))
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *memFile) Stat() (os.FileInfo, error) {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	return memInfo{name: filepath.Base(f.name), node: *f.node, source: f.node}, nil
+}
+
+func (f *memFile) Sync() error {
+	return nil
+}
+
+func (f *memFile) Close() error {
+	f.fs.mutex.Lock()
+	defer f.fs.mutex.Unlock()
+
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return int64(len(i.node.data)) }
+func (i memInfo) Mode() os.FileMode  { return i.node.mode }
+func (i memInfo) ModTime() time.Time { return i.node.modTime }
+func (i memInfo) IsDir() bool        { return i.node.mode.IsDir() }
+func (i memInfo) Sys() any           { return i.source }
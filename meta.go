@@ -0,0 +1,153 @@
+package goLogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	metaFileName  = "logger-meta.json"
+	schemaVersion = 1
+)
+
+type meta struct {
+	SchemaVersion int               `json:"schema_version"`
+	Format        string            `json:"format"`
+	TimeLayout    string            `json:"time_layout"`
+	Fields        map[string]string `json:"fields"`
+	Files         map[string]any    `json:"files"`
+	Rotation      map[string]any    `json:"rotation"`
+	Integrity     map[string]bool   `json:"integrity"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// * describe the directory layout so shippers can configure parsing without the source
+func (l *Logger) writeMeta() error {
+	m := meta{
+		SchemaVersion: schemaVersion,
+		Format:        l.config.Type,
+		Files: map[string]any{
+			defaultDebugName:  []string{logDebug, logTrace},
+			defaultOutputName: []string{logInfo, logNotice, logWarning},
+			defaultErrorName:  []string{logWarning, logError, logFatal, logCritical},
+		},
+		Rotation: map[string]any{
+			"max_size":       l.config.MaxSize,
+			"max_backup":     l.config.MaxBackup,
+			"check_interval": "1h",
+			"backup_pattern": l.backupPattern("<file>").String(),
+			"max_age":        l.config.MaxAge.String(),
+			"append_only":    l.config.AppendOnly,
+		},
+		Integrity: map[string]bool{
+			"hmac":  l.config.HMACKey != "",
+			"audit": l.config.Audit,
+		},
+		UpdatedAt: l.now(),
+	}
+
+	for _, route := range l.routes {
+		m.Files[route.File] = map[string]any{
+			"logger":     route.Prefix,
+			"max_size":   l.maxSize(route.File),
+			"max_backup": l.maxBackup(route.File),
+		}
+	}
+
+	if l.config.TenantField != "" {
+		m.Files["<"+l.config.TenantField+">/"] = "the files above, repeated in one directory per value of the field"
+	}
+
+	if l.config.Type == "json" {
+		m.TimeLayout = time.RFC3339Nano
+		if p, ok := timePrecisions[l.config.TimePrecision]; ok {
+			m.TimeLayout = p.json
+		}
+		m.Fields = map[string]string{
+			"time":   "entry timestamp",
+			"level":  "DEBUG, INFO, WARN or ERROR from slog, overridden by TRACE, NOTICE, FATAL or CRITICAL",
+			"msg":    "first message",
+			"msgN":   "additional messages in order, starting at msg1",
+			"hash":   "audit chain hash, present when integrity.audit is true",
+			"hmac":   "entry signature, present when integrity.hmac is true",
+			"logger": "dotted name of the Named logger, absent for the root logger",
+			"errors": "text of every error argument, present when an entry has two or more",
+			"causes": "text of each error unwrapped from err, outermost first, present when err wraps another",
+		}
+		if l.config.Caller {
+			m.Fields["caller"] = "file.go:line of the call site"
+		}
+		if l.config.Fingerprint {
+			m.Fields["fingerprint"] = "hash of the normalized message and calling function, ERROR and above"
+		}
+		if l.config.BuildFields {
+			m.Fields["version"] = "main module version of the binary, absent when unknown"
+			m.Fields["revision"] = "VCS revision the binary was built from, absent when unknown"
+		}
+		if l.config.Sequence {
+			m.Fields["seq"] = "number incremented by one per entry of the process, a gap means entries were dropped or lost"
+		}
+		if l.config.EntryID {
+			m.Fields["entry_id"] = "random UUID of the entry, the same in files and sinks"
+		}
+	} else {
+		m.TimeLayout = textTimeLayout
+		if p, ok := timePrecisions[l.config.TimePrecision]; ok {
+			m.TimeLayout = p.text
+		}
+		m.Fields = map[string]string{
+			"line":   "<time> [<LEVEL>] <message>, INFO has no level prefix",
+			"branch": "<time> ├── <message> or <time> └── <message> for additional messages and key=value fields",
+			"group":  "<time> ├─┬ <name> or <time> └─┬ <name> opens a field group, its members are indented by four columns",
+			"hash":   "trailing [hash:<hex>] on the last line, present when integrity.audit is true",
+			"hmac":   "trailing [hmac:<hex>] on the last line, present when integrity.hmac is true",
+			"logger": "logger=<name> branch from a Named logger, absent for the root logger",
+			"causes": "causes group with one branch per error unwrapped from err, present when err wraps another",
+		}
+		if l.config.Caller {
+			m.Fields["caller"] = "caller=file.go:line branch with the call site"
+		}
+		if l.config.Fingerprint {
+			m.Fields["fingerprint"] = "fingerprint=<hex> branch, hash of the normalized message and calling function, ERROR and above"
+		}
+		if l.config.BuildFields {
+			m.Fields["version"] = "version=<version> branch, main module version of the binary, absent when unknown"
+			m.Fields["revision"] = "revision=<hash> branch, VCS revision the binary was built from, absent when unknown"
+		}
+		if l.config.Sequence {
+			m.Fields["seq"] = "seq=<n> branch, incremented by one per entry of the process, a gap means entries were dropped or lost"
+		}
+		if l.config.EntryID {
+			m.Fields["entry_id"] = "entry_id=<uuid> branch, random UUID of the entry, the same in files and sinks"
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode meta: %w", err)
+	}
+
+	path := filepath.Join(l.config.Path, metaFileName)
+	tmp := path + ".tmp"
+	switch {
+	case l.instance != "":
+		// * replicas on a shared volume write meta on start, possibly with the same pid
+		tmp = fmt.Sprintf("%s.%s.tmp", path, l.instance)
+	case l.config.SharedPath:
+		// * every process writes meta on start
+		tmp = fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	}
+	if err := writeFile(l.fs(), tmp, data, fileMode(l.config)); err != nil {
+		return fmt.Errorf("Failed to write meta: %w", err)
+	}
+	if err := l.applyOwnership(tmp); err != nil {
+		return fmt.Errorf("Failed to write meta: %w", err)
+	}
+	if err := l.fs().Rename(tmp, path); err != nil {
+		return fmt.Errorf("Failed to write meta: %w", err)
+	}
+	return nil
+}
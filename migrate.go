@@ -0,0 +1,115 @@
+package goLogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var textField = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.-]*)=(.*)$`)
+
+// * rewrite every log file of a directory into logType, run it while no logger writes there;
+// * hmac and hash suffixes can't survive re-encoding and are dropped
+func Migrate(dir string, logType string) error {
+	if logType != "json" && logType != "text" {
+		return fmt.Errorf("Failed to migrate: unknown type %q", logType)
+	}
+
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %w", dir, err)
+	}
+
+	for _, item := range items {
+		if !item.Type().IsRegular() || !isQueryFile(item.Name()) {
+			continue
+		}
+		if err := migrateFile(filepath.Join(dir, item.Name()), logType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateFile(path string, logType string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("Failed to stat %s: %w", path, err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %w", path, err)
+	}
+
+	// * only gzip is written back, other compressions have no encoder to restore them
+	if !bytes.HasPrefix(raw, gzipMagic) && (bytes.HasPrefix(raw, zstdMagic) || findDecompression(raw) != nil) {
+		return fmt.Errorf("Failed to migrate %s: only gzip backups can be rewritten", path)
+	}
+
+	var buf bytes.Buffer
+	reader := NewReader(path)
+	for reader.Next() {
+		entry := reader.Entry()
+		if logType == "json" {
+			entry = liftFields(entry)
+		}
+		buf.Write(EncodeEntry(entry, logType))
+	}
+	reader.Close()
+	if err := reader.Err(); err != nil {
+		return fmt.Errorf("Failed to migrate: %w", err)
+	}
+
+	data := buf.Bytes()
+	if bytes.HasPrefix(raw, gzipMagic) {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		gz.Write(data)
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("Failed to compress %s: %w", path, err)
+		}
+		data = compressed.Bytes()
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, info.Mode().Perm()|0200); err != nil {
+		return fmt.Errorf("Failed to write %s: %w", tmp, err)
+	}
+	// * keep read-only backups of AppendOnly mode read-only
+	os.Chmod(tmp, info.Mode().Perm())
+	os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Failed to replace %s: %w", path, err)
+	}
+
+	if _, err := os.Stat(path + checksumExt); err == nil {
+		return writeChecksum(osFS{}, path)
+	}
+	return nil
+}
+
+// * text output stores fields as key=value branches, turn them back into fields
+func liftFields(entry Entry) Entry {
+	var data []string
+	for _, item := range entry.Data {
+		if match := textField.FindStringSubmatch(item); match != nil && !reservedKey(match[1]) {
+			entry.Fields = append(entry.Fields, Field{Key: match[1], Value: match[2]})
+			continue
+		}
+		data = append(data, item)
+	}
+	entry.Data = data
+	return entry
+}
+
+func reservedKey(key string) bool {
+	switch key {
+	case "time", "level", "msg", "hash", "hmac":
+		return true
+	}
+	return jsonDataKey.MatchString(key)
+}
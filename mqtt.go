@@ -0,0 +1,290 @@
+package goLogger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	mqttConnect = 0x10
+	mqttConnack = 0x20
+	mqttPublish = 0x30
+	mqttPuback  = 0x40
+)
+
+// 以 MQTT 3.1.1 發佈日誌至 broker，適合頻寬受限的嵌入式或 IoT 裝置
+type MQTTSink struct {
+	Addr      string          // broker 位址，如 "broker:1883"
+	TLS       *tls.Config     // 設定後以 TLS 連線（通常為 8883 埠），可由 TLSFiles 載入憑證
+	Topic     string          // topic text/template，可用 Entry 欄位與 lower，如 "devices/42/logs/{{lower .Level}}"，預設 "logs"
+	QoS       byte            // 0 或 1，1 時等待 PUBACK，預設 0
+	Retain    bool            // 設定 retain 旗標
+	ClientID  string          // 用戶端 ID，預設隨機產生
+	Username  string          // 帳號驗證
+	Password  string          // 密碼驗證
+	MinLevel  string          // 送出的最低層級，預設 "WARNING"
+	BatchSize int             // 單次送出最多筆數，預設 100
+	Interval  time.Duration   // 批次送出的間隔，預設 5 秒
+	Retry     *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker   *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue     *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	Timeout   time.Duration   // 等待 broker 回應的時間，預設 10 秒
+	once      sync.Once
+	batcher   *batcher
+	conn      netConn
+	topic     *template.Template
+	packetID  uint16
+	minLevel  string
+	initErr   error
+}
+
+func (m *MQTTSink) init() error {
+	m.once.Do(func() {
+		if m.Addr == "" {
+			m.initErr = fmt.Errorf("Addr is required")
+			return
+		}
+		if m.QoS > 1 {
+			m.initErr = fmt.Errorf("QoS %d is not supported, use 0 or 1", m.QoS)
+			return
+		}
+		topic := m.Topic
+		if topic == "" {
+			topic = "logs"
+		}
+		funcs := template.FuncMap{"lower": strings.ToLower}
+		if m.topic, m.initErr = template.New("topic").Funcs(funcs).Parse(topic); m.initErr != nil {
+			m.initErr = fmt.Errorf("Failed to parse topic: %w", m.initErr)
+			return
+		}
+		m.minLevel = logWarning
+		if m.MinLevel != "" {
+			if m.minLevel, m.initErr = parseLevel(m.MinLevel); m.initErr != nil {
+				return
+			}
+		}
+		if m.ClientID == "" {
+			id := make([]byte, 8)
+			rand.Read(id)
+			m.ClientID = "goLogger-" + hex.EncodeToString(id)
+		}
+		m.conn = netConn{network: "tcp", addr: m.Addr, tls: m.TLS}
+		m.batcher, m.initErr = newBatcher(m.BatchSize, m.Interval, m.Retry, m.Breaker, m.Queue, m.send)
+	})
+	return m.initErr
+}
+
+func (m *MQTTSink) reportErrors(report func(error)) {
+	if m.init() == nil {
+		m.batcher.setReport(report)
+	}
+}
+
+func (m *MQTTSink) Write(entry Entry) error {
+	if err := m.init(); err != nil {
+		return err
+	}
+	if levelRank[entry.Level] < levelRank[m.minLevel] {
+		return nil
+	}
+	m.batcher.add(entry)
+	return nil
+}
+
+func (m *MQTTSink) Flush() {
+	if m.init() == nil {
+		m.batcher.Flush()
+	}
+}
+
+func (m *MQTTSink) Close() {
+	if m.init() == nil {
+		m.batcher.Close()
+		if m.conn.conn != nil {
+			// * DISCONNECT
+			m.conn.write([]byte{0xe0, 0x00}, m.timeout())
+		}
+		m.conn.close()
+	}
+}
+
+func (m *MQTTSink) undelivered() int {
+	if m.init() != nil {
+		return 0
+	}
+	return m.batcher.undelivered()
+}
+
+func (m *MQTTSink) queued() int {
+	if m.init() != nil {
+		return 0
+	}
+	return m.batcher.queued()
+}
+
+func (m *MQTTSink) timeout() time.Duration {
+	if m.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return m.Timeout
+}
+
+func (m *MQTTSink) send(entries []Entry) error {
+	reused := m.conn.conn != nil
+	err := m.deliver(entries)
+	if err != nil && reused && m.conn.conn == nil {
+		// * brokers drop idle clients, a fresh connection gets one more try
+		err = m.deliver(entries)
+	}
+	if err != nil {
+		m.conn.close()
+		return fmt.Errorf("Failed to publish to MQTT %s: %w", m.Addr, err)
+	}
+	return nil
+}
+
+func (m *MQTTSink) deliver(entries []Entry) error {
+	if m.conn.conn == nil {
+		if err := m.connect(); err != nil {
+			m.conn.close()
+			return err
+		}
+	}
+
+	for i := range entries {
+		var topic strings.Builder
+		if err := m.topic.Execute(&topic, entries[i]); err != nil {
+			return fmt.Errorf("topic: %w", err)
+		}
+		if topic.Len() == 0 || strings.ContainsAny(topic.String(), "+#\x00") {
+			return fmt.Errorf("invalid topic %q", topic.String())
+		}
+
+		header := byte(mqttPublish) | m.QoS<<1
+		if m.Retain {
+			header |= 0x01
+		}
+		var body []byte
+		body = appendMQTTString(body, topic.String())
+		if m.QoS == 1 {
+			m.packetID++
+			if m.packetID == 0 {
+				m.packetID = 1
+			}
+			body = binary.BigEndian.AppendUint16(body, m.packetID)
+		}
+		body = append(body, bytes.TrimSuffix(encodeJSON(&entries[i]), []byte("\n"))...)
+		if err := m.conn.write(mqttPacket(header, body), m.timeout()); err != nil {
+			return err
+		}
+
+		if m.QoS == 1 {
+			kind, ack, err := m.readPacket()
+			if err != nil {
+				return err
+			}
+			if kind&0xf0 != mqttPuback || len(ack) < 2 || binary.BigEndian.Uint16(ack) != m.packetID {
+				return fmt.Errorf("unexpected packet 0x%02x waiting for PUBACK", kind)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MQTTSink) connect() error {
+	if err := m.conn.dial(); err != nil {
+		return err
+	}
+
+	// * protocol name, level 4 (3.1.1), flags, keep-alive off
+	body := appendMQTTString(nil, "MQTT")
+	flags := byte(0x02)
+	if m.Username != "" {
+		flags |= 0x80
+		if m.Password != "" {
+			flags |= 0x40
+		}
+	}
+	body = append(body, 0x04, flags, 0x00, 0x00)
+	body = appendMQTTString(body, m.ClientID)
+	if m.Username != "" {
+		body = appendMQTTString(body, m.Username)
+		if m.Password != "" {
+			body = appendMQTTString(body, m.Password)
+		}
+	}
+	if err := m.conn.write(mqttPacket(mqttConnect, body), m.timeout()); err != nil {
+		return err
+	}
+
+	kind, ack, err := m.readPacket()
+	if err != nil {
+		return err
+	}
+	if kind != mqttConnack || len(ack) < 2 {
+		return fmt.Errorf("unexpected packet 0x%02x waiting for CONNACK", kind)
+	}
+	if ack[1] != 0 {
+		return fmt.Errorf("connection refused with code %d", ack[1])
+	}
+	return nil
+}
+
+func (m *MQTTSink) readPacket() (byte, []byte, error) {
+	m.conn.conn.SetReadDeadline(time.Now().Add(m.timeout()))
+	kind, err := m.conn.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	// * remaining length, up to four 7-bit groups
+	length, shift := 0, 0
+	for i := 0; ; i++ {
+		b, err := m.conn.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		if shift += 7; i == 3 {
+			return 0, nil, fmt.Errorf("malformed remaining length")
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(m.conn.reader, body); err != nil {
+		return 0, nil, err
+	}
+	return kind, body, nil
+}
+
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		b := byte(length & 0x7f)
+		length >>= 7
+		if length > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+func appendMQTTString(buf []byte, value string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(value)))
+	return append(buf, value...)
+}
@@ -0,0 +1,247 @@
+package goLogger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// * the subset of MessagePack used by the forward protocol,
+// * values decoded from entryAttributes plus event time
+
+// * a corrupt length must not allocate gigabytes
+const maxMsgpackLength = 16 << 20
+
+// fluentd EventTime（ext type 0），保留奈秒精度
+type eventTime time.Time
+
+func appendMsgpack(buf []byte, value any) []byte {
+	switch value := value.(type) {
+	case nil:
+		return append(buf, 0xc0)
+	case bool:
+		if value {
+			return append(buf, 0xc3)
+		}
+		return append(buf, 0xc2)
+	case int:
+		return appendMsgpackInt(buf, int64(value))
+	case int64:
+		return appendMsgpackInt(buf, value)
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+			return appendMsgpackInt(buf, int64(value))
+		}
+		buf = append(buf, 0xcb)
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(value))
+	case string:
+		return appendMsgpackString(buf, value)
+	case []byte:
+		buf = appendMsgpackLength(buf, len(value), 0, 0xc4, 0xc5, 0xc6)
+		return append(buf, value...)
+	case eventTime:
+		t := time.Time(value)
+		buf = append(buf, 0xd7, 0x00)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(t.Unix()))
+		return binary.BigEndian.AppendUint32(buf, uint32(t.Nanosecond()))
+	case []any:
+		buf = appendMsgpackLength(buf, len(value), 0x90, 0, 0xdc, 0xdd)
+		for _, item := range value {
+			buf = appendMsgpack(buf, item)
+		}
+		return buf
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf = appendMsgpackLength(buf, len(keys), 0x80, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			buf = appendMsgpackString(buf, key)
+			buf = appendMsgpack(buf, value[key])
+		}
+		return buf
+	default:
+		return appendMsgpackString(buf, fmt.Sprint(value))
+	}
+}
+
+func appendMsgpackInt(buf []byte, value int64) []byte {
+	switch {
+	case value >= 0 && value < 128:
+		return append(buf, byte(value))
+	case value < 0 && value >= -32:
+		return append(buf, byte(value))
+	default:
+		buf = append(buf, 0xd3)
+		return binary.BigEndian.AppendUint64(buf, uint64(value))
+	}
+}
+
+func appendMsgpackString(buf []byte, value string) []byte {
+	buf = appendMsgpackLength(buf, len(value), 0xa0, 0xd9, 0xda, 0xdb)
+	return append(buf, value...)
+}
+
+// * fixed is the fix-type prefix for lengths below 16 (32 for strings), zero when absent
+func appendMsgpackLength(buf []byte, n int, fixed, byte8, byte16, byte32 byte) []byte {
+	fixedMax := 16
+	if fixed == 0xa0 {
+		fixedMax = 32
+	}
+	switch {
+	case fixed != 0 && n < fixedMax:
+		return append(buf, fixed|byte(n))
+	case byte8 != 0 && n < 256:
+		return append(buf, byte8, byte(n))
+	case n < 65536:
+		buf = append(buf, byte16)
+		return binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, byte32)
+		return binary.BigEndian.AppendUint32(buf, uint32(n))
+	}
+}
+
+func readMsgpack(r *bufio.Reader) (any, error) {
+	kind, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case kind < 0x80:
+		return int64(kind), nil
+	case kind >= 0xe0:
+		return int64(int8(kind)), nil
+	case kind&0xf0 == 0x80:
+		return readMsgpackMap(r, int(kind&0x0f))
+	case kind&0xf0 == 0x90:
+		return readMsgpackArray(r, int(kind&0x0f))
+	case kind&0xe0 == 0xa0:
+		return readMsgpackString(r, int(kind&0x1f))
+	}
+
+	switch kind {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		n, err := readMsgpackUint(r, 1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xc5, 0xda:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xc6, 0xdb:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xca:
+		n, err := readMsgpackUint(r, 4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := readMsgpackUint(r, 8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readMsgpackUint(r, 1<<(kind-0xcc))
+		return int64(n), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (kind - 0xd0)
+		n, err := readMsgpackUint(r, size)
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, err
+	case 0xd7:
+		// * fixext 8: type, seconds, nanoseconds
+		var data [9]byte
+		if _, err := io.ReadFull(r, data[:]); err != nil {
+			return nil, err
+		}
+		if data[0] != 0x00 {
+			return nil, fmt.Errorf("unsupported msgpack ext type %d", int8(data[0]))
+		}
+		sec := binary.BigEndian.Uint32(data[1:5])
+		nsec := binary.BigEndian.Uint32(data[5:9])
+		return eventTime(time.Unix(int64(sec), int64(nsec))), nil
+	case 0xdc, 0xdd:
+		n, err := readMsgpackUint(r, 2<<(kind-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, int(n))
+	case 0xde, 0xdf:
+		n, err := readMsgpackUint(r, 2<<(kind-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, int(n))
+	}
+	return nil, fmt.Errorf("unsupported msgpack type 0x%02x", kind)
+}
+
+func readMsgpackUint(r *bufio.Reader, size int) (uint64, error) {
+	var data [8]byte
+	if _, err := io.ReadFull(r, data[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(data[:]), nil
+}
+
+func readMsgpackString(r *bufio.Reader, n int) (string, error) {
+	if n > maxMsgpackLength {
+		return "", fmt.Errorf("msgpack length %d exceeds the limit", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func readMsgpackArray(r *bufio.Reader, n int) ([]any, error) {
+	if n > maxMsgpackLength {
+		return nil, fmt.Errorf("msgpack length %d exceeds the limit", n)
+	}
+	items := make([]any, n)
+	for i := range items {
+		item, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func readMsgpackMap(r *bufio.Reader, n int) (map[string]any, error) {
+	if n > maxMsgpackLength {
+		return nil, fmt.Errorf("msgpack length %d exceeds the limit", n)
+	}
+	items := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		key, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		items[fmt.Sprint(key)] = value
+	}
+	return items, nil
+}
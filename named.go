@@ -0,0 +1,105 @@
+package goLogger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// * lightweight view of a Logger, shares its files, rotation and lock
+type Child struct {
+	logger    *Logger
+	name      string
+	fields    []Field
+	groups    []string
+	component string
+}
+
+var _ Interface = (*Child)(nil)
+
+func (l *Logger) Named(name string) *Child {
+	return &Child{logger: l, name: name}
+}
+
+// * names nest with dots, http → http.client
+func (c *Child) Named(name string) *Child {
+	if c.name != "" && name != "" {
+		name = c.name + "." + name
+	} else if name == "" {
+		name = c.name
+	}
+	child := *c
+	child.name = name
+	return &child
+}
+
+func (c *Child) Name() string {
+	return c.name
+}
+
+func compileLevels(levels map[string]string) (map[string]string, error) {
+	compiled := make(map[string]string, len(levels))
+	for name, level := range levels {
+		parsed, err := parseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to compile level of %q: %w", name, err)
+		}
+		compiled[strings.Trim(name, ".")] = parsed
+	}
+	return compiled, nil
+}
+
+// * called under lock, the longest configured prefix wins: http.client → http.client, http, global
+func (l *Logger) levelOf(name string) string {
+	level := l.config.Level
+	for _, prefix := range namePrefixes(name) {
+		if named, isExist := l.levels[prefix]; isExist {
+			level = named
+			if l.elevation != nil && levelRank[l.config.Level] < levelRank[level] {
+				// * a running elevation also applies to named loggers
+				level = l.config.Level
+			}
+			break
+		}
+	}
+	return level
+}
+
+func (c *Child) write(level string, filename string, messages ...any) error {
+	return c.logger.writeScoped(c, filename, level, nil, messages...)
+}
+
+func (c *Child) Debug(messages ...any) {
+	c.write(logDebug, defaultDebugName, messages...)
+}
+
+func (c *Child) Trace(messages ...any) {
+	c.write(logTrace, defaultDebugName, messages...)
+}
+
+func (c *Child) Info(messages ...any) {
+	c.write(logInfo, defaultOutputName, messages...)
+}
+
+func (c *Child) Notice(messages ...any) {
+	c.write(logNotice, defaultOutputName, messages...)
+}
+
+func (c *Child) Warn(messages ...any) {
+	c.write(logWarning, defaultOutputName, messages...)
+}
+
+func (c *Child) WarnError(err error, messages ...any) error {
+	return c.logger.writeError(c, logWarning, err, messages...)
+}
+
+func (c *Child) Error(err error, messages ...any) error {
+	return c.logger.writeError(c, logError, err, messages...)
+}
+
+func (c *Child) Fatal(err error, messages ...any) error {
+	return c.logger.writeError(c, logFatal, err, messages...)
+}
+
+func (c *Child) Critical(err error, messages ...any) error {
+	return c.logger.writeError(c, logCritical, err, messages...)
+}
@@ -0,0 +1,253 @@
+package goLogger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// * NATS caps payloads at 64MB even when max_payload is raised
+const maxNATSPayload = 64 << 20
+
+// 發佈日誌至 NATS subject，可選 JetStream 由 stream 確認持久化
+type NATSSink struct {
+	Addr      string          // NATS 位址，如 "nats:4222"
+	TLS       *tls.Config     // 設定後於 INFO 之後升級為 TLS，可由 TLSFiles 載入憑證
+	Subject   string          // subject text/template，可用 Entry 欄位與 lower，如 "logs.{{lower .Level}}"，預設 "logs"
+	JetStream bool            // 等待 JetStream 的發佈確認，subject 需屬於某個 stream
+	Token     string          // auth_token 驗證
+	Username  string          // 帳號驗證
+	Password  string          // 密碼驗證
+	MinLevel  string          // 送出的最低層級，預設全部
+	BatchSize int             // 單次送出最多筆數，預設 100
+	Interval  time.Duration   // 批次送出的間隔，預設 5 秒
+	Retry     *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker   *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue     *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	Timeout   time.Duration   // 等待伺服器回應的時間，預設 10 秒
+	once      sync.Once
+	batcher   *batcher
+	conn      netConn
+	subject   *template.Template
+	inbox     string
+	minLevel  string
+	initErr   error
+}
+
+func (n *NATSSink) init() error {
+	n.once.Do(func() {
+		if n.Addr == "" {
+			n.initErr = fmt.Errorf("Addr is required")
+			return
+		}
+		subject := n.Subject
+		if subject == "" {
+			subject = "logs"
+		}
+		funcs := template.FuncMap{"lower": strings.ToLower}
+		if n.subject, n.initErr = template.New("subject").Funcs(funcs).Parse(subject); n.initErr != nil {
+			n.initErr = fmt.Errorf("Failed to parse subject: %w", n.initErr)
+			return
+		}
+		if n.MinLevel != "" {
+			if n.minLevel, n.initErr = parseLevel(n.MinLevel); n.initErr != nil {
+				return
+			}
+		}
+		n.conn = netConn{network: "tcp", addr: n.Addr}
+		n.batcher, n.initErr = newBatcher(n.BatchSize, n.Interval, n.Retry, n.Breaker, n.Queue, n.send)
+	})
+	return n.initErr
+}
+
+func (n *NATSSink) reportErrors(report func(error)) {
+	if n.init() == nil {
+		n.batcher.setReport(report)
+	}
+}
+
+func (n *NATSSink) Write(entry Entry) error {
+	if err := n.init(); err != nil {
+		return err
+	}
+	if n.minLevel != "" && levelRank[entry.Level] < levelRank[n.minLevel] {
+		return nil
+	}
+	n.batcher.add(entry)
+	return nil
+}
+
+func (n *NATSSink) Flush() {
+	if n.init() == nil {
+		n.batcher.Flush()
+	}
+}
+
+func (n *NATSSink) Close() {
+	if n.init() == nil {
+		n.batcher.Close()
+		n.conn.close()
+	}
+}
+
+func (n *NATSSink) undelivered() int {
+	if n.init() != nil {
+		return 0
+	}
+	return n.batcher.undelivered()
+}
+
+func (n *NATSSink) queued() int {
+	if n.init() != nil {
+		return 0
+	}
+	return n.batcher.queued()
+}
+
+func (n *NATSSink) timeout() time.Duration {
+	if n.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return n.Timeout
+}
+
+func (n *NATSSink) send(entries []Entry) error {
+	if err := n.deliver(entries); err != nil {
+		n.conn.close()
+		return fmt.Errorf("Failed to publish to NATS %s: %w", n.Addr, err)
+	}
+	return nil
+}
+
+// * publishes the batch followed by PING, the PONG confirms the server has
+// * processed it; JetStream acks arrive on the inbox subscription
+func (n *NATSSink) deliver(entries []Entry) error {
+	if n.conn.conn == nil {
+		if err := n.connect(); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	for i := range entries {
+		var subject strings.Builder
+		if err := n.subject.Execute(&subject, entries[i]); err != nil {
+			return fmt.Errorf("subject: %w", err)
+		}
+		if strings.ContainsAny(subject.String(), " \t\r\n") || subject.Len() == 0 {
+			return fmt.Errorf("invalid subject %q", subject.String())
+		}
+		payload := bytes.TrimSuffix(encodeJSON(&entries[i]), []byte("\n"))
+		if n.JetStream {
+			fmt.Fprintf(&buf, "PUB %s %s.%d %d\r\n", subject.String(), n.inbox, i, len(payload))
+		} else {
+			fmt.Fprintf(&buf, "PUB %s %d\r\n", subject.String(), len(payload))
+		}
+		buf.Write(payload)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("PING\r\n")
+	if err := n.conn.write(buf.Bytes(), n.timeout()); err != nil {
+		return err
+	}
+
+	acked, ponged := 0, false
+	for !ponged || (n.JetStream && acked < len(entries)) {
+		line, err := n.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			ponged = true
+		case line == "PING":
+			if err := n.conn.write([]byte("PONG\r\n"), n.timeout()); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case strings.HasPrefix(line, "MSG "):
+			ack, err := n.readMessage(line)
+			if err != nil {
+				return err
+			}
+			var reply struct {
+				Error *struct {
+					Description string `json:"description"`
+				} `json:"error"`
+			}
+			if json.Unmarshal(ack, &reply) == nil && reply.Error != nil {
+				return fmt.Errorf("jetstream: %s", reply.Error.Description)
+			}
+			acked++
+		}
+	}
+	return nil
+}
+
+func (n *NATSSink) connect() error {
+	if err := n.conn.dial(); err != nil {
+		return err
+	}
+	line, err := n.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+	if n.TLS != nil {
+		if err := n.conn.startTLS(n.TLS, n.timeout()); err != nil {
+			return err
+		}
+	}
+
+	options := map[string]any{"verbose": false, "pedantic": false, "lang": "go", "name": "goLogger"}
+	if n.Token != "" {
+		options["auth_token"] = n.Token
+	}
+	if n.Username != "" {
+		options["user"], options["pass"] = n.Username, n.Password
+	}
+	connect, _ := json.Marshal(options)
+	command := "CONNECT " + string(connect) + "\r\n"
+	if n.JetStream {
+		id := make([]byte, 8)
+		rand.Read(id)
+		n.inbox = "_INBOX." + hex.EncodeToString(id)
+		command += "SUB " + n.inbox + ".* 1\r\n"
+	}
+	return n.conn.write([]byte(command), n.timeout())
+}
+
+func (n *NATSSink) readLine() (string, error) {
+	n.conn.conn.SetReadDeadline(time.Now().Add(n.timeout()))
+	line, err := n.conn.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// * MSG <subject> <sid> [reply-to] <size>
+func (n *NATSSink) readMessage(line string) ([]byte, error) {
+	fields := strings.Fields(line)
+	size, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || size < 0 || size > maxNATSPayload {
+		return nil, fmt.Errorf("malformed %q", line)
+	}
+	payload := make([]byte, size+2)
+	if _, err := io.ReadFull(n.conn.reader, payload); err != nil {
+		return nil, err
+	}
+	return payload[:size], nil
+}
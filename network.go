@@ -0,0 +1,226 @@
+package goLogger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dialTimeout = 10 * time.Second
+
+// * a lazily dialed stream connection shared by the socket sinks,
+// * dropped on any failure so the next write reconnects
+type netConn struct {
+	network string
+	addr    string
+	tls     *tls.Config
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+func (c *netConn) dial() error {
+	if c.conn != nil {
+		return nil
+	}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if c.tls != nil {
+		conn, err = tls.DialWithDialer(dialer, c.network, c.addr, c.tls)
+	} else {
+		conn, err = dialer.Dial(c.network, c.addr)
+	}
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+	return nil
+}
+
+func (c *netConn) write(data []byte, timeout time.Duration) error {
+	if err := c.dial(); err != nil {
+		return err
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := c.conn.Write(data); err != nil {
+		c.close()
+		return err
+	}
+	return nil
+}
+
+// * upgrades an established connection, for protocols that negotiate TLS in-band
+func (c *netConn) startTLS(config *tls.Config, timeout time.Duration) error {
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(c.addr)
+		if err != nil {
+			return err
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+	conn := tls.Client(c.conn, config)
+	conn.SetDeadline(time.Now().Add(timeout))
+	if err := conn.Handshake(); err != nil {
+		c.close()
+		return fmt.Errorf("TLS handshake: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+	return nil
+}
+
+func (c *netConn) close() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn, c.reader = nil, nil
+	}
+}
+
+// 通用網路輸出，支援 TCP 與 UDP、換行或長度前綴框架，斷線期間保留日誌並以退避重新連線
+type NetworkSink struct {
+	Network   string          // "tcp" 或 "udp"，預設 "tcp"
+	Addr      string          // 收集端位址，如 "collector:5170"
+	TLS       *tls.Config     // 設定後以 TLS 連線（僅限 TCP），可由 TLSFiles 載入憑證
+	Framing   string          // "newline"（JSON 行，預設）或 "length"（與 UnixSink 相同的長度前綴框架）
+	MinLevel  string          // 送出的最低層級，預設全部
+	BatchSize int             // 單次寫入最多筆數，預設 100
+	Interval  time.Duration   // 批次送出的間隔，預設 5 秒
+	Retry     *RetryPolicy    // 斷線時的重試策略，預設持續重試，等待由 1 秒倍增至 1 分鐘
+	Breaker   *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue     *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	Timeout   time.Duration   // 寫入逾時，預設 10 秒
+	once      sync.Once
+	batcher   *batcher
+	conn      netConn
+	minLevel  string
+	initErr   error
+}
+
+func (n *NetworkSink) init() error {
+	n.once.Do(func() {
+		if n.Addr == "" {
+			n.initErr = fmt.Errorf("Addr is required")
+			return
+		}
+		network := n.Network
+		switch network {
+		case "":
+			network = "tcp"
+		case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		default:
+			n.initErr = fmt.Errorf(`Network must be "tcp" or "udp", got %q`, n.Network)
+			return
+		}
+		if n.TLS != nil && strings.HasPrefix(network, "udp") {
+			n.initErr = fmt.Errorf("TLS is not supported over %s", network)
+			return
+		}
+		switch n.Framing {
+		case "", "newline", "length":
+		default:
+			n.initErr = fmt.Errorf(`Framing must be "newline" or "length", got %q`, n.Framing)
+			return
+		}
+		if n.MinLevel != "" {
+			if n.minLevel, n.initErr = parseLevel(n.MinLevel); n.initErr != nil {
+				return
+			}
+		}
+		n.conn = netConn{network: network, addr: n.Addr, tls: n.TLS}
+		retry := n.Retry
+		if retry == nil {
+			retry = &RetryPolicy{}
+		}
+		n.batcher, n.initErr = newBatcher(n.BatchSize, n.Interval, retry, n.Breaker, n.Queue, n.send)
+	})
+	return n.initErr
+}
+
+func (n *NetworkSink) reportErrors(report func(error)) {
+	if n.init() == nil {
+		n.batcher.setReport(report)
+	}
+}
+
+func (n *NetworkSink) Write(entry Entry) error {
+	if err := n.init(); err != nil {
+		return err
+	}
+	if n.minLevel != "" && levelRank[entry.Level] < levelRank[n.minLevel] {
+		return nil
+	}
+	n.batcher.add(entry)
+	return nil
+}
+
+func (n *NetworkSink) Flush() {
+	if n.init() == nil {
+		n.batcher.Flush()
+	}
+}
+
+func (n *NetworkSink) Close() {
+	if n.init() == nil {
+		n.batcher.Close()
+		n.conn.close()
+	}
+}
+
+func (n *NetworkSink) undelivered() int {
+	if n.init() != nil {
+		return 0
+	}
+	return n.batcher.undelivered()
+}
+
+func (n *NetworkSink) queued() int {
+	if n.init() != nil {
+		return 0
+	}
+	return n.batcher.queued()
+}
+
+func (n *NetworkSink) frame(entry Entry) ([]byte, error) {
+	if n.Framing == "length" {
+		return appendFrame(nil, entry)
+	}
+	return encodeJSON(&entry), nil
+}
+
+// * TCP writes the batch at once, UDP sends one datagram per entry
+func (n *NetworkSink) send(entries []Entry) error {
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	var buf []byte
+	for _, entry := range entries {
+		data, err := n.frame(entry)
+		if err != nil {
+			// * can't be encoded, retrying won't help
+			continue
+		}
+		if strings.HasPrefix(n.conn.network, "udp") {
+			if err := n.conn.write(data, timeout); err != nil {
+				return fmt.Errorf("Failed to send to %s: %w", n.Addr, err)
+			}
+			continue
+		}
+		buf = append(buf, data...)
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	if err := n.conn.write(buf, timeout); err != nil {
+		return fmt.Errorf("Failed to send to %s: %w", n.Addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package goLogger
+
+// * accept this in libraries instead of *Logger, Nop satisfies it without touching the filesystem
+type Interface interface {
+	Debug(messages ...any)
+	Trace(messages ...any)
+	Info(messages ...any)
+	Notice(messages ...any)
+	Warn(messages ...any)
+	WarnError(err error, messages ...any) error
+	Error(err error, messages ...any) error
+	Fatal(err error, messages ...any) error
+	Critical(err error, messages ...any) error
+}
+
+var _ Interface = (*Logger)(nil)
+
+type nopLogger struct{}
+
+func Nop() Interface {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(messages ...any)  {}
+func (nopLogger) Trace(messages ...any)  {}
+func (nopLogger) Info(messages ...any)   {}
+func (nopLogger) Notice(messages ...any) {}
+func (nopLogger) Warn(messages ...any)   {}
+
+// * same returned error as Logger so callers' control flow doesn't change
+func (nopLogger) WarnError(err error, messages ...any) error {
+	return composeError(err, resolveLazy(messages))
+}
+
+func (nopLogger) Error(err error, messages ...any) error {
+	return composeError(err, resolveLazy(messages))
+}
+
+func (nopLogger) Fatal(err error, messages ...any) error {
+	return composeError(err, resolveLazy(messages))
+}
+
+func (nopLogger) Critical(err error, messages ...any) error {
+	return composeError(err, resolveLazy(messages))
+}
@@ -0,0 +1,256 @@
+package goLogger
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+type Option func(*Log)
+
+// * starts from the same defaults as New(nil) and applies options in order
+func NewWithOptions(options ...Option) (*Logger, error) {
+	config := &Log{}
+	for _, option := range options {
+		option(config)
+	}
+	return New(config)
+}
+
+func WithPath(path string) Option {
+	return func(c *Log) { c.Path = path }
+}
+
+func WithStdout() Option {
+	return func(c *Log) { c.Stdout = true }
+}
+
+func WithJSON() Option {
+	return func(c *Log) { c.Type = "json" }
+}
+
+func WithText() Option {
+	return func(c *Log) { c.Type = "text" }
+}
+
+func WithLevel(level string) Option {
+	return func(c *Log) { c.Level = level }
+}
+
+// * level for loggers named name or name.*, see Logger.Named
+func WithNamedLevel(name, level string) Option {
+	return func(c *Log) {
+		if c.Levels == nil {
+			c.Levels = make(map[string]string)
+		}
+		c.Levels[name] = level
+	}
+}
+
+func WithRoute(route Route) Option {
+	return func(c *Log) { c.Routes = append(c.Routes, route) }
+}
+
+func WithAlert(rule AlertRule) Option {
+	return func(c *Log) { c.Alerts = append(c.Alerts, rule) }
+}
+
+func WithSink(sink Sink) Option {
+	return func(c *Log) { c.Sinks = append(c.Sinks, sink) }
+}
+
+func WithCloseTimeout(timeout time.Duration) Option {
+	return func(c *Log) { c.CloseTimeout = timeout }
+}
+
+func WithFlushInterval(interval time.Duration) Option {
+	return func(c *Log) { c.FlushInterval = interval }
+}
+
+func WithSyncPolicy(policy string) Option {
+	return func(c *Log) { c.SyncPolicy = policy }
+}
+
+// * e.g. WithDirMode(0700) and WithFileMode(0600) on shared hosts
+func WithDirMode(mode os.FileMode) Option {
+	return func(c *Log) { c.DirMode = mode }
+}
+
+func WithFileMode(mode os.FileMode) Option {
+	return func(c *Log) { c.FileMode = mode }
+}
+
+// * e.g. WithOwner("www-data", "www-data") when starting as root and dropping privileges
+func WithOwner(owner, group string) Option {
+	return func(c *Log) {
+		c.Owner = owner
+		c.Group = group
+	}
+}
+
+// * e.g. WithInstance("hostname") for replicas sharing a volume, WithInstance("pid") for processes on one host
+func WithInstance(instance string) Option {
+	return func(c *Log) { c.Instance = instance }
+}
+
+func WithWatchInterval(interval time.Duration) Option {
+	return func(c *Log) { c.WatchInterval = interval }
+}
+
+// * several processes writing the same Path, e.g. prefork workers
+func WithSharedPath() Option {
+	return func(c *Log) { c.SharedPath = true }
+}
+
+func WithTenantField(key string) Option {
+	return func(c *Log) { c.TenantField = key }
+}
+
+func WithMaxTenants(n int) Option {
+	return func(c *Log) { c.MaxTenants = n }
+}
+
+func WithVerbosity(level int) Option {
+	return func(c *Log) { c.Verbosity = level }
+}
+
+func WithMaxSize(size int64) Option {
+	return func(c *Log) { c.MaxSize = size }
+}
+
+func WithMaxBackup(count int) Option {
+	return func(c *Log) { c.MaxBackup = count }
+}
+
+func WithMaxAge(age time.Duration) Option {
+	return func(c *Log) { c.MaxAge = age }
+}
+
+func WithMaxTotalSize(size int64) Option {
+	return func(c *Log) { c.MaxTotalSize = size }
+}
+
+func WithMaxEntrySize(size int) Option {
+	return func(c *Log) { c.MaxEntrySize = size }
+}
+
+func WithLineLimits(file, stdout int) Option {
+	return func(c *Log) {
+		c.FileLineLimit = file
+		c.StdoutLineLimit = stdout
+	}
+}
+
+func WithBackupFormat(format string) Option {
+	return func(c *Log) { c.BackupFormat = format }
+}
+
+func WithDatedFiles() Option {
+	return func(c *Log) { c.DatedFiles = true }
+}
+
+func WithFilters(rules ...FilterRule) Option {
+	return func(c *Log) { c.Filters = append(c.Filters, rules...) }
+}
+
+func WithRedact(rules ...RedactRule) Option {
+	return func(c *Log) { c.Redact = append(c.Redact, rules...) }
+}
+
+func WithMaskKeys(keys ...string) Option {
+	return func(c *Log) { c.MaskKeys = append(c.MaskKeys, keys...) }
+}
+
+func WithHMAC(key string) Option {
+	return func(c *Log) { c.HMACKey = key }
+}
+
+func WithAudit() Option {
+	return func(c *Log) { c.Audit = true }
+}
+
+func WithChecksum() Option {
+	return func(c *Log) { c.Checksum = true }
+}
+
+func WithAppendOnly() Option {
+	return func(c *Log) { c.AppendOnly = true }
+}
+
+func WithStrictEncoding() Option {
+	return func(c *Log) { c.StrictEncoding = true }
+}
+
+func WithArchiver(archiver Archiver, deleteArchived bool) Option {
+	return func(c *Log) {
+		c.Archiver = archiver
+		c.DeleteArchived = deleteArchived
+	}
+}
+
+func WithReopenOnSIGHUP() Option {
+	return func(c *Log) { c.ReopenOnSIGHUP = true }
+}
+
+func WithFallback(writer io.Writer) Option {
+	return func(c *Log) { c.Fallback = writer }
+}
+
+func WithExpvar(prefix string) Option {
+	return func(c *Log) { c.Expvar = prefix }
+}
+
+func WithRecentSize(size int) Option {
+	return func(c *Log) { c.RecentSize = size }
+}
+
+// * pair with WithRecentSize, the report holds what the ring buffer keeps
+func WithCrashDump() Option {
+	return func(c *Log) { c.CrashDump = true }
+}
+
+func WithBuildFields() Option {
+	return func(c *Log) { c.BuildFields = true }
+}
+
+func WithSequence() Option {
+	return func(c *Log) { c.Sequence = true }
+}
+
+func WithEntryID() Option {
+	return func(c *Log) { c.EntryID = true }
+}
+
+// * e.g. WithTimePrecision("millisecond") for the same timestamps in text and json
+func WithTimePrecision(precision string) Option {
+	return func(c *Log) { c.TimePrecision = precision }
+}
+
+func WithColor() Option {
+	return func(c *Log) { c.Color = true }
+}
+
+func WithCaller() Option {
+	return func(c *Log) { c.Caller = true }
+}
+
+func WithFingerprint() Option {
+	return func(c *Log) { c.Fingerprint = true }
+}
+
+func WithSampling(initial, thereafter int, tick time.Duration) Option {
+	return func(c *Log) { c.Sampling = &Sampling{Initial: initial, Thereafter: thereafter, Tick: tick} }
+}
+
+func WithClock(clock Clock) Option {
+	return func(c *Log) { c.Clock = clock }
+}
+
+func WithFS(fsys FS) Option {
+	return func(c *Log) { c.FS = fsys }
+}
+
+// * escape hatch for fields without a dedicated option
+func WithConfig(apply func(*Log)) Option {
+	return Option(apply)
+}
@@ -0,0 +1,69 @@
+package goLogger
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// * optional for FS implementations, needed for Owner and Group
+type chowner interface {
+	Chown(name string, uid, gid int) error
+}
+
+// * uid and gid of Owner and Group, -1 keeps the current one like os.Chown
+type ownership struct {
+	uid int
+	gid int
+}
+
+func (o ownership) isSet() bool {
+	return o.uid != -1 || o.gid != -1
+}
+
+// * names are looked up in the user database, numbers are taken as they are
+func lookupOwnership(owner, group string) (ownership, error) {
+	o := ownership{uid: -1, gid: -1}
+	if owner != "" {
+		if id, err := strconv.ParseUint(owner, 10, 31); err == nil {
+			o.uid = int(id)
+		} else {
+			account, err := user.Lookup(owner)
+			if err != nil {
+				return o, fmt.Errorf("Failed to look up owner: %w", err)
+			}
+			o.uid, _ = strconv.Atoi(account.Uid)
+		}
+	}
+	if group != "" {
+		if id, err := strconv.ParseUint(group, 10, 31); err == nil {
+			o.gid = int(id)
+		} else {
+			entry, err := user.LookupGroup(group)
+			if err != nil {
+				return o, fmt.Errorf("Failed to look up group: %w", err)
+			}
+			o.gid, _ = strconv.Atoi(entry.Gid)
+		}
+	}
+	return o, nil
+}
+
+// * applied to existing paths as well, files left by an earlier root run stay writable
+func applyOwnership(fsys FS, path string, o ownership) error {
+	if !o.isSet() {
+		return nil
+	}
+	fs, ok := fsys.(chowner)
+	if !ok {
+		return fmt.Errorf("Failed to set owner of %s: FS does not support Chown", path)
+	}
+	if err := fs.Chown(path, o.uid, o.gid); err != nil {
+		return fmt.Errorf("Failed to set owner of %s: %w", path, err)
+	}
+	return nil
+}
+
+func (l *Logger) applyOwnership(path string) error {
+	return applyOwnership(l.fs(), path, l.owner)
+}
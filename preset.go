@@ -0,0 +1,25 @@
+package goLogger
+
+import "time"
+
+// * colored text on stdout with caller info, files still written under ./logs
+func NewDevelopment(options ...Option) (*Logger, error) {
+	presets := []Option{
+		WithText(),
+		WithStdout(),
+		WithLevel(logDebug),
+		WithColor(),
+		WithCaller(),
+	}
+	return NewWithOptions(append(presets, options...)...)
+}
+
+// * JSON files at INFO, repeated messages are sampled
+func NewProduction(options ...Option) (*Logger, error) {
+	presets := []Option{
+		WithJSON(),
+		WithLevel(logInfo),
+		WithSampling(100, 100, time.Second),
+	}
+	return NewWithOptions(append(presets, options...)...)
+}
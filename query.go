@@ -0,0 +1,116 @@
+package goLogger
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type QueryOptions struct {
+	From     time.Time // 起始時間，零值不限制
+	To       time.Time // 結束時間，零值不限制
+	Level    string    // 最低層級，空值不限制
+	Contains string    // 訊息須包含的字串
+	Limit    int       // 最多回傳筆數，保留最新的，0 不限制
+}
+
+func (l *Logger) Query(options QueryOptions) ([]Entry, error) {
+	l.Mutex.RLock()
+	dir := l.config.Path
+	fsys := l.fs()
+	l.Mutex.RUnlock()
+
+	return query(fsys, dir, options)
+}
+
+// * scans live files and backups of a directory, entries are returned oldest first
+func Query(dir string, options QueryOptions) ([]Entry, error) {
+	return query(osFS{}, dir, options)
+}
+
+func query(fsys FS, dir string, options QueryOptions) ([]Entry, error) {
+	minLevel := ""
+	if options.Level != "" {
+		level, err := parseLevel(options.Level)
+		if err != nil {
+			return nil, err
+		}
+		minLevel = level
+	}
+
+	items, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", dir, err)
+	}
+
+	var files []backupFile
+	for _, item := range items {
+		name := item.Name()
+		// * symlinks of DatedFiles mode point at files listed already
+		if !item.Type().IsRegular() || !isQueryFile(name) {
+			continue
+		}
+		info, err := item.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, name), modTime: info.ModTime()})
+	}
+
+	// * files older than From can't hold matching entries, allow for coarse mtime
+	var paths []string
+	for _, file := range files {
+		if options.From.IsZero() || file.modTime.After(options.From.Add(-time.Second)) {
+			paths = append(paths, file.path)
+		}
+	}
+
+	var result []Entry
+	reader := &Reader{fsys: fsys, paths: paths}
+	defer reader.Close()
+	for reader.Next() {
+		entry := reader.Entry()
+		if minLevel != "" && levelRank[entry.Level] < levelRank[minLevel] {
+			continue
+		}
+		if !options.From.IsZero() && entry.Time.Before(options.From) {
+			continue
+		}
+		if !options.To.IsZero() && entry.Time.After(options.To) {
+			continue
+		}
+		if options.Contains != "" && !entryContains(entry, options.Contains) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	if options.Limit > 0 && len(result) > options.Limit {
+		result = result[len(result)-options.Limit:]
+	}
+	return result, nil
+}
+
+func isQueryFile(name string) bool {
+	return strings.Contains(name, ".log") && !strings.HasPrefix(name, ".") && !strings.HasSuffix(name, checksumExt)
+}
+
+func entryContains(entry Entry, text string) bool {
+	if strings.Contains(entry.Message, text) {
+		return true
+	}
+	for _, data := range entry.Data {
+		if strings.Contains(data, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package goLogger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	queueSegmentSize = 8 << 20
+	defaultQueueSize = 256 << 20
+)
+
+// 將待送日誌寫入磁碟的佇列（WAL），斷線期間的日誌於程序重啟後依序重送
+type DiskQueue struct {
+	Dir     string // 佇列目錄，每個輸出需使用不同目錄
+	MaxSize int64  // 佇列檔案總大小上限（位元組），超過時捨棄最舊的片段，預設 256MB
+}
+
+// * segments hold UnixSink frames, the cursor file records the segment and
+// * offset of the first unsent frame
+type queueSegment struct {
+	id     int64
+	size   int64
+	frames int
+}
+
+type queueMark struct {
+	id     int64
+	end    int64
+	frames int
+}
+
+type diskQueue struct {
+	mutex    sync.Mutex
+	dir      string
+	maxSize  int64
+	segments []queueSegment
+	active   *os.File
+	offset   int64
+	consumed int
+	count    int
+	size     int64
+}
+
+func openDiskQueue(config *DiskQueue) (*diskQueue, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("Queue Dir is required")
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("Failed to create queue: %w", err)
+	}
+	q := &diskQueue{dir: config.Dir, maxSize: config.MaxSize}
+	if q.maxSize <= 0 {
+		q.maxSize = defaultQueueSize
+	}
+
+	var headID, headOffset int64
+	if data, err := os.ReadFile(filepath.Join(q.dir, "cursor")); err == nil {
+		parts := strings.Fields(string(data))
+		if len(parts) == 2 {
+			headID, _ = strconv.ParseInt(parts[0], 10, 64)
+			headOffset, _ = strconv.ParseInt(parts[1], 10, 64)
+		}
+	}
+
+	names, err := filepath.Glob(filepath.Join(q.dir, "*.wal"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list queue: %w", err)
+	}
+	var ids []int64
+	for _, name := range names {
+		if id, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(name), ".wal"), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var lastID int64
+	for _, id := range ids {
+		lastID = id
+		if id < headID {
+			// * fully sent before the last shutdown
+			os.Remove(q.segmentPath(id))
+			continue
+		}
+		segment, before, err := scanSegment(q.segmentPath(id), headOffset, id == headID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read queue: %w", err)
+		}
+		if len(q.segments) == 0 && id == headID {
+			q.offset, q.consumed = min(headOffset, segment.size), before
+		}
+		q.segments = append(q.segments, segment)
+		q.count += segment.frames - before
+		q.size += segment.size
+	}
+
+	// * a fresh segment each start, a torn frame at the end of the last one is never appended to
+	if err := q.roll(lastID + 1); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *diskQueue) segmentPath(id int64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d.wal", id))
+}
+
+// * counts the complete frames, and those ending at or before offset when it is the head
+func scanSegment(path string, offset int64, head bool) (queueSegment, int, error) {
+	segment := queueSegment{}
+	segment.id, _ = strconv.ParseInt(strings.TrimSuffix(filepath.Base(path), ".wal"), 10, 64)
+	file, err := os.Open(path)
+	if err != nil {
+		return segment, 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	before := 0
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(reader, size[:]); err != nil {
+			break
+		}
+		n := int64(binary.BigEndian.Uint32(size[:]))
+		if n > maxFrameSize {
+			break
+		}
+		if _, err := reader.Discard(int(n)); err != nil {
+			break
+		}
+		segment.size += 4 + n
+		segment.frames++
+		if head && segment.size <= offset {
+			before++
+		}
+	}
+	return segment, before, nil
+}
+
+func (q *diskQueue) roll(id int64) error {
+	file, err := os.OpenFile(q.segmentPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to create queue segment: %w", err)
+	}
+	if q.active != nil {
+		q.active.Close()
+	}
+	q.active = file
+	q.segments = append(q.segments, queueSegment{id: id})
+	return nil
+}
+
+// * returns how many unsent entries were dropped to stay under MaxSize
+func (q *diskQueue) append(entry Entry) (int, error) {
+	data, err := appendFrame(nil, entry)
+	if err != nil {
+		return 0, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	last := &q.segments[len(q.segments)-1]
+	if last.size > 0 && last.size+int64(len(data)) > queueSegmentSize {
+		if err := q.roll(last.id + 1); err != nil {
+			return 0, err
+		}
+		last = &q.segments[len(q.segments)-1]
+	}
+	if _, err := q.active.Write(data); err != nil {
+		// * a short write leaves a torn frame, move past it
+		q.roll(last.id + 1)
+		return 0, fmt.Errorf("Failed to write queue: %w", err)
+	}
+	last.size += int64(len(data))
+	last.frames++
+	q.size += int64(len(data))
+	q.count++
+
+	dropped := 0
+	for q.size > q.maxSize && len(q.segments) > 1 {
+		dropped += q.removeHead()
+	}
+	return dropped, nil
+}
+
+// * returns how many of its entries were still unsent
+func (q *diskQueue) removeHead() int {
+	head := q.segments[0]
+	unsent := head.frames - q.consumed
+	q.segments = q.segments[1:]
+	q.size -= head.size
+	q.count -= unsent
+	os.Remove(q.segmentPath(head.id))
+	q.offset, q.consumed = 0, 0
+	q.writeCursor()
+	return unsent
+}
+
+func (q *diskQueue) writeCursor() {
+	if len(q.segments) == 0 {
+		return
+	}
+	path := filepath.Join(q.dir, "cursor")
+	data := fmt.Sprintf("%d %d\n", q.segments[0].id, q.offset)
+	if err := os.WriteFile(path+".tmp", []byte(data), 0644); err == nil {
+		os.Rename(path+".tmp", path)
+	}
+}
+
+func (q *diskQueue) pending() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.count
+}
+
+// * reads up to n entries from the head without consuming them,
+// * frames that can't be decoded are skipped but still consumed by commit
+func (q *diskQueue) peek(n int) ([]Entry, queueMark, error) {
+	q.mutex.Lock()
+	for q.offset >= q.segments[0].size && len(q.segments) > 1 {
+		q.removeHead()
+	}
+	head, offset := q.segments[0], q.offset
+	q.mutex.Unlock()
+
+	mark := queueMark{id: head.id, end: offset}
+	if offset >= head.size {
+		return nil, mark, nil
+	}
+	file, err := os.Open(q.segmentPath(head.id))
+	if err != nil {
+		return nil, mark, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(io.NewSectionReader(file, offset, head.size-offset))
+	var entries []Entry
+	for len(entries) < n && mark.end < head.size {
+		var size [4]byte
+		if _, err := io.ReadFull(reader, size[:]); err != nil {
+			return entries, mark, err
+		}
+		data := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return entries, mark, err
+		}
+		mark.end += 4 + int64(len(data))
+		mark.frames++
+		if entry, err := decodeFrame(data); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, mark, nil
+}
+
+func (q *diskQueue) commit(mark queueMark) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	// * the head was dropped meanwhile for exceeding MaxSize
+	if q.segments[0].id != mark.id || mark.end <= q.offset {
+		return
+	}
+	q.offset = mark.end
+	q.consumed += mark.frames
+	q.count -= mark.frames
+	q.writeCursor()
+}
+
+func (q *diskQueue) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.active != nil {
+		q.active.Close()
+		q.active = nil
+	}
+}
@@ -0,0 +1,97 @@
+package goLogger
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// * delete oldest backups until live files plus backups fit in MaxTotalSize; one
+// * budget covers Path and every tenant directory under it, open or evicted, and
+// * the oldest backups go first wherever they are. Runs on rotation and hourly,
+// * so live files may grow past the budget by up to MaxSize each in between
+func (l *Logger) enforceQuota() error {
+	if l.config.MaxTotalSize <= 0 || l.config.AppendOnly {
+		return nil
+	}
+
+	dirs := []string{"."}
+	if l.config.TenantField != "" {
+		entries, err := l.fs().ReadDir(l.config.Path)
+		if err != nil {
+			return fmt.Errorf("Failed to read: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && tenantPattern.MatchString(entry.Name()) {
+				dirs = append(dirs, entry.Name())
+			}
+		}
+	}
+
+	var total int64
+	var backups []backupFile
+	sizes := make(map[string]int64)
+	for _, dir := range dirs {
+		size, found, err := l.quotaFiles(dir, sizes)
+		if err != nil {
+			return err
+		}
+		total += size
+		backups = append(backups, found...)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return olderBackup(backups[i], backups[j])
+	})
+
+	for _, backup := range backups {
+		if total <= l.config.MaxTotalSize {
+			break
+		}
+		if err := l.fs().Remove(backup.path); err != nil {
+			return fmt.Errorf("Failed to remove %s: %w", backup.path, err)
+		}
+		total -= sizes[backup.path]
+		if l.fs().Remove(backup.path+checksumExt) == nil {
+			total -= sizes[backup.path+checksumExt]
+		}
+	}
+	return nil
+}
+
+// * sums the regular files of one directory under Path and lists the backups of
+// * this logger's files in it, sizes collects backups and checksums for deletion
+func (l *Logger) quotaFiles(dir string, sizes map[string]int64) (int64, []backupFile, error) {
+	path := filepath.Join(l.config.Path, dir)
+	entries, err := l.fs().ReadDir(path)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed to read: %w", err)
+	}
+
+	var total int64
+	var backups []backupFile
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		total += info.Size()
+
+		name := entry.Name()
+		for _, base := range l.baseNames() {
+			filename := filepath.Join(dir, base)
+			disk := filepath.Base(l.diskName(filename))
+			if name != filepath.Base(l.dated[filename]) && l.backupPattern(disk).MatchString(name) {
+				backup := filepath.Join(path, name)
+				backups = append(backups, backupFile{path: backup, base: disk, modTime: info.ModTime()})
+				sizes[backup] = info.Size()
+				break
+			}
+		}
+		if strings.HasSuffix(name, checksumExt) {
+			sizes[filepath.Join(path, name)] = info.Size()
+		}
+	}
+	return total, backups, nil
+}
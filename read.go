@@ -0,0 +1,291 @@
+package goLogger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+type compressedFile struct {
+	io.Reader
+	file   File
+	closer io.Closer
+}
+
+func (c *compressedFile) Close() error {
+	if c.closer != nil {
+		c.closer.Close()
+	}
+	return c.file.Close()
+}
+
+// * open a live or rotated log file, decompressing by content instead of extension
+func openLogFile(fsys FS, path string) (io.ReadCloser, error) {
+	file, err := fsys.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s: %w", path, err)
+	}
+
+	reader := bufio.NewReader(file)
+	magic, _ := reader.Peek(maxMagicSize())
+
+	if bytes.HasPrefix(magic, zstdMagic) && findDecompression(magic) == nil {
+		file.Close()
+		return nil, fmt.Errorf("Failed to decompress %s: zstd is not supported, use RegisterDecompression", path)
+	}
+	newReader := findDecompression(magic)
+	if newReader == nil {
+		return &compressedFile{Reader: reader, file: file}, nil
+	}
+	decompressed, err := newReader(reader)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("Failed to decompress %s: %w", path, err)
+	}
+	return &compressedFile{Reader: decompressed, file: file, closer: decompressed}, nil
+}
+
+var (
+	textHeader    = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?) (?:\[([A-Z]+)\] )?(.*)$`)
+	textBranch    = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)? ((?:│   |    )*)(├|└)─(─|┬) (.*)$`)
+	textIntegrity = regexp.MustCompile(`( \[(?:hash|hmac):[0-9a-f]{64}\])+$`)
+	jsonDataKey   = regexp.MustCompile(`^msg[0-9]+$`)
+)
+
+// * iterates entries of text or JSON files, backups included, in the order given
+type Reader struct {
+	fsys    FS
+	paths   []string
+	stream  io.Reader
+	file    io.ReadCloser
+	scanner *bufio.Scanner
+	path    string
+	line    int
+	pending string
+	entry   Entry
+	err     error
+}
+
+func NewReader(paths ...string) *Reader {
+	return &Reader{fsys: osFS{}, paths: paths}
+}
+
+// * decode entries from stdin, a pipe or bytes appended to a followed file
+func NewStreamReader(stream io.Reader) *Reader {
+	return &Reader{stream: stream}
+}
+
+func (r *Reader) Next() bool {
+	for r.err == nil {
+		if r.scanner == nil && !r.openNext() {
+			return false
+		}
+
+		line, ok := r.readLine()
+		if !ok {
+			if err := r.scanner.Err(); err != nil {
+				r.err = fmt.Errorf("Failed to read %s: %w", r.path, err)
+				return false
+			}
+			r.file.Close()
+			r.file, r.scanner = nil, nil
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var err error
+		if strings.HasPrefix(line, "{") {
+			r.entry, err = parseJSONLine(line)
+		} else {
+			r.entry, err = r.parseText(line)
+		}
+		if err != nil {
+			r.err = fmt.Errorf("Failed to parse %s line %d: %w", r.path, r.line, err)
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func (r *Reader) Entry() Entry {
+	return r.entry
+}
+
+func (r *Reader) Err() error {
+	return r.err
+}
+
+func (r *Reader) Close() error {
+	r.paths = nil
+	r.stream = nil
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file, r.scanner = nil, nil
+	return err
+}
+
+func (r *Reader) openNext() bool {
+	if r.stream != nil {
+		r.path = "stream"
+		r.file = io.NopCloser(r.stream)
+		r.stream = nil
+	} else if len(r.paths) > 0 {
+		r.path, r.paths = r.paths[0], r.paths[1:]
+
+		file, err := openLogFile(r.fsys, r.path)
+		if err != nil {
+			r.err = err
+			return false
+		}
+		r.file = file
+	} else {
+		return false
+	}
+
+	r.scanner = bufio.NewScanner(r.file)
+	r.scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	r.line = 0
+	r.pending = ""
+	return true
+}
+
+func (r *Reader) readLine() (string, bool) {
+	if r.pending != "" {
+		line := r.pending
+		r.pending = ""
+		return line, true
+	}
+	if !r.scanner.Scan() {
+		return "", false
+	}
+	r.line++
+	return r.scanner.Text(), true
+}
+
+// * text entries span a header line plus ├── / └── branches
+func (r *Reader) parseText(line string) (Entry, error) {
+	match := textHeader.FindStringSubmatch(textIntegrity.ReplaceAllString(line, ""))
+	if match == nil || textBranch.MatchString(line) {
+		return Entry{}, fmt.Errorf("unexpected line %q", line)
+	}
+
+	// * a fraction of any length is accepted after the seconds, whatever TimePrecision wrote
+	timestamp, err := time.ParseInLocation("2006/01/02 15:04:05", match[1], time.Local)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := Entry{Time: timestamp, Level: logInfo, Message: match[3]}
+	if _, ok := levelRank[match[2]]; ok {
+		entry.Level = match[2]
+	} else if match[2] != "" {
+		entry.Message = fmt.Sprintf("[%s] %s", match[2], match[3])
+	}
+
+	// * open groups with whether they were the last branch of their level
+	type group struct {
+		name   string
+		isLast bool
+	}
+	var groups []group
+	for r.scanner.Scan() {
+		r.line++
+		next := r.scanner.Text()
+		branch := textBranch.FindStringSubmatch(textIntegrity.ReplaceAllString(next, ""))
+		if branch == nil {
+			r.pending = next
+			break
+		}
+		depth := utf8.RuneCountInString(branch[1]) / 4
+		if depth > len(groups) {
+			depth = len(groups)
+		}
+		groups = groups[:depth]
+		isLast := branch[2] == "└"
+		if branch[3] == "┬" {
+			groups = append(groups, group{name: branch[4], isLast: isLast})
+			continue
+		}
+
+		// * text output can't tell fields from messages, both come back as Data,
+		// * grouped fields as group.key=value
+		text := branch[4]
+		for i := len(groups) - 1; i >= 0; i-- {
+			text = groups[i].name + "." + text
+		}
+		entry.Data = append(entry.Data, text)
+
+		done := isLast
+		for _, g := range groups {
+			done = done && g.isLast
+		}
+		if done {
+			break
+		}
+	}
+	return entry, nil
+}
+
+func parseJSONLine(line string) (Entry, error) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return Entry{}, fmt.Errorf("expected object")
+	}
+
+	var entry Entry
+	var data []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return Entry{}, err
+		}
+		key, _ := token.(string)
+
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return Entry{}, err
+		}
+
+		switch {
+		case key == "time":
+			text, _ := value.(string)
+			if entry.Time, err = time.Parse(time.RFC3339Nano, text); err != nil {
+				return Entry{}, err
+			}
+		case key == "level":
+			// * TRACE, NOTICE... follow slog's level, the last one wins
+			text, _ := value.(string)
+			if entry.Level, err = parseLevel(text); err != nil {
+				return Entry{}, err
+			}
+		case key == "msg":
+			entry.Message, _ = value.(string)
+		case jsonDataKey.MatchString(key):
+			text, _ := value.(string)
+			data = append(data, text)
+		case key == "hash" || key == "hmac":
+			continue
+		default:
+			entry.Fields = append(entry.Fields, Field{Key: key, Value: value})
+		}
+	}
+	entry.Data = data
+	return entry, nil
+}
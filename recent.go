@@ -0,0 +1,44 @@
+package goLogger
+
+// * called under lock, overwrites the oldest entry once the buffer is full
+func (l *Logger) remember(entry Entry) {
+	size := cap(l.recent)
+	if size == 0 {
+		return
+	}
+
+	if len(l.recent) < size {
+		l.recent = append(l.recent, entry)
+		return
+	}
+	l.recent[l.recentNext] = entry
+	l.recentNext = (l.recentNext + 1) % size
+}
+
+// * oldest first, an empty level matches every entry
+func (l *Logger) Recent(n int, level string) []Entry {
+	if level != "" {
+		parsed, err := parseLevel(level)
+		if err != nil {
+			return nil
+		}
+		level = parsed
+	}
+
+	l.Mutex.RLock()
+	defer l.Mutex.RUnlock()
+
+	var result []Entry
+	total := len(l.recent)
+	for i := total - 1; i >= 0 && (n <= 0 || len(result) < n); i-- {
+		entry := l.recent[(l.recentNext+i)%total]
+		if level == "" || entry.Level == level {
+			result = append(result, entry)
+		}
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
@@ -0,0 +1,317 @@
+package goLogger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// * applied atomically under the lock, nothing changes when validation fails;
+// * options tied to open files or process state need a new logger
+func (l *Logger) Reconfigure(config *Log) error {
+	if config == nil {
+		return fmt.Errorf("Failed to reconfigure: config is nil")
+	}
+
+	cfg := copyConfig(config)
+	if err := applyEnv(&cfg); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := initSinks(cfg.Sinks); err != nil {
+		return err
+	}
+	if err := applyDefaults(&cfg); err != nil {
+		return err
+	}
+	filters, err := compileFilters(cfg.Filters)
+	if err != nil {
+		return err
+	}
+	redactors, err := compileRedactors(cfg.Redact)
+	if err != nil {
+		return err
+	}
+	levels, err := compileLevels(cfg.Levels)
+	if err != nil {
+		return err
+	}
+	alerts, err := compileAlerts(cfg.Alerts)
+	if err != nil {
+		return err
+	}
+
+	// * one at a time, sinks are built outside the main lock from the config they replace
+	l.reconfiguring.Lock()
+	defer l.reconfiguring.Unlock()
+
+	l.Mutex.RLock()
+	if l.IsClose {
+		l.Mutex.RUnlock()
+		return fmt.Errorf("logger is closed")
+	}
+	configSinks, created, err := rebuildSinks(l.config.SinkConfigs, l.configSinks, cfg.SinkConfigs)
+	l.Mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	applied := false
+	defer func() {
+		if !applied {
+			for _, sink := range created {
+				sink.Close()
+			}
+		}
+	}()
+
+	l.lock()
+	defer l.unlock()
+
+	if l.IsClose {
+		return fmt.Errorf("logger is closed")
+	}
+	if fixed := fixedChanges(l.config, &cfg); len(fixed) > 0 {
+		return fmt.Errorf("Failed to reconfigure: %s can't change at runtime", strings.Join(fixed, ", "))
+	}
+
+	applied = true
+	l.swapSinks(append(cfg.Sinks[:len(cfg.Sinks):len(cfg.Sinks)], configSinks...), configSinks)
+	l.config = &cfg
+	l.elevation = nil
+	l.filters = filters
+	l.redactors = redactors
+	l.levels = levels
+	l.alerts = alerts
+	l.maskKeys = make(map[string]bool, len(cfg.MaskKeys))
+	for _, key := range cfg.MaskKeys {
+		l.maskKeys[strings.ToLower(key)] = true
+	}
+	l.samples = nil
+	l.resizeRecent(cfg.RecentSize)
+
+	if err := l.initHandler(); err != nil {
+		return err
+	}
+	l.writeMeta()
+	l.emit(l.OutputHandler, logNotice, nil, "Configuration reloaded")
+	return nil
+}
+
+func fixedChanges(current, next *Log) []string {
+	var fixed []string
+	if filepath.Clean(current.Path) != filepath.Clean(next.Path) {
+		fixed = append(fixed, "path")
+	}
+	if current.DatedFiles != next.DatedFiles {
+		fixed = append(fixed, "dated_files")
+	}
+	if current.AppendOnly != next.AppendOnly {
+		fixed = append(fixed, "append_only")
+	}
+	if current.HMACKey != next.HMACKey {
+		fixed = append(fixed, "hmac_key")
+	}
+	if current.Audit != next.Audit {
+		fixed = append(fixed, "audit")
+	}
+	if current.ReopenOnSIGHUP != next.ReopenOnSIGHUP {
+		fixed = append(fixed, "reopen_on_sighup")
+	}
+	if current.Expvar != next.Expvar {
+		fixed = append(fixed, "expvar")
+	}
+	if current.FlushInterval != next.FlushInterval {
+		fixed = append(fixed, "flush_interval")
+	}
+	if current.DirMode != next.DirMode {
+		fixed = append(fixed, "dir_mode")
+	}
+	if current.FileMode != next.FileMode {
+		fixed = append(fixed, "file_mode")
+	}
+	if current.Owner != next.Owner || current.Group != next.Group {
+		fixed = append(fixed, "owner")
+	}
+	if current.Instance != next.Instance {
+		fixed = append(fixed, "instance")
+	}
+	if current.WatchInterval != next.WatchInterval {
+		fixed = append(fixed, "watch_interval")
+	}
+	if current.SharedPath != next.SharedPath {
+		fixed = append(fixed, "shared_path")
+	}
+	if current.TenantField != next.TenantField {
+		fixed = append(fixed, "tenant_field")
+	}
+	if !slices.Equal(compileRoutes(current.Routes), compileRoutes(next.Routes)) {
+		fixed = append(fixed, "routes")
+	}
+	if fileSystem(current) != fileSystem(next) {
+		fixed = append(fixed, "FS")
+	}
+	return fixed
+}
+
+// * entries equal to a current one keep its running sink, the others are built
+func rebuildSinks(current []SinkConfig, running []Sink, next []SinkConfig) (sinks []Sink, created []Sink, err error) {
+	used := make([]bool, len(current))
+	for _, config := range next {
+		i := slices.IndexFunc(current, func(c SinkConfig) bool {
+			return c.Name == config.Name && bytes.Equal(c.Options, config.Options)
+		})
+		for i >= 0 && used[i] {
+			offset := slices.IndexFunc(current[i+1:], func(c SinkConfig) bool {
+				return c.Name == config.Name && bytes.Equal(c.Options, config.Options)
+			})
+			if offset < 0 {
+				i = -1
+			} else {
+				i += 1 + offset
+			}
+		}
+		if i >= 0 {
+			used[i] = true
+			sinks = append(sinks, running[i])
+			continue
+		}
+		built, err := buildSinks([]SinkConfig{config})
+		if err != nil {
+			for _, sink := range created {
+				sink.Close()
+			}
+			return nil, nil, err
+		}
+		sinks = append(sinks, built[0])
+		created = append(created, built[0])
+	}
+	return sinks, created, nil
+}
+
+// * called under lock, entries after the swap go to the new set; removed sinks
+// * send what they hold and close in the background, Close waits for them
+func (l *Logger) swapSinks(sinks []Sink, configSinks []Sink) {
+	var retired []Sink
+	for _, sink := range l.sinks {
+		if !slices.Contains(sinks, sink) {
+			retired = append(retired, sink)
+		}
+	}
+	for _, sink := range sinks {
+		if reporter, ok := sink.(errorReporter); ok && !slices.Contains(l.sinks, sink) {
+			reporter.reportErrors(l.internalError)
+		}
+	}
+	l.sinks, l.configSinks = sinks, configSinks
+
+	if len(retired) == 0 {
+		return
+	}
+	l.retiring.Add(1)
+	go func() {
+		defer l.retiring.Done()
+		for _, sink := range retired {
+			sink.Flush()
+			sink.Close()
+		}
+	}()
+}
+
+// * called under lock, keeps the newest entries
+func (l *Logger) resizeRecent(size int) {
+	if size == cap(l.recent) {
+		return
+	}
+
+	total := len(l.recent)
+	ordered := make([]Entry, 0, total)
+	for i := 0; i < total; i++ {
+		ordered = append(ordered, l.recent[(l.recentNext+i)%total])
+	}
+	if len(ordered) > size {
+		ordered = ordered[len(ordered)-size:]
+	}
+
+	l.recent = nil
+	if size > 0 {
+		l.recent = append(make([]Entry, 0, size), ordered...)
+	}
+	l.recentNext = 0
+}
+
+// * polls instead of relying on inotify, editors that replace the file are handled too
+func (l *Logger) WatchConfig(path string, interval time.Duration) (func(), error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to watch config: %w", err)
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	l.Mutex.RLock()
+	closed := l.stopTimer
+	l.Mutex.RUnlock()
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		modTime, size := info.ModTime(), info.Size()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-closed:
+				// * Close ends the watch as well
+				return
+			case <-ticker.C:
+			}
+			if isClosed(closed) {
+				// * the tick and Close may be ready at once
+				return
+			}
+
+			info, err := os.Stat(path)
+			if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+				continue
+			}
+			modTime, size = info.ModTime(), info.Size()
+
+			config, err := LoadConfig(path)
+			if err == nil {
+				// * fields without a JSON tag can't come from the file, keep them
+				current := l.Config()
+				config.Archiver = current.Archiver
+				config.Fallback = current.Fallback
+				config.Clock = current.Clock
+				config.FS = current.FS
+				config.Sinks = current.Sinks
+				err = l.Reconfigure(config)
+			}
+			if err != nil && !isClosed(closed) {
+				l.internalError(fmt.Errorf("Failed to reload %s: %w", path, err))
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(stop) }) }, nil
+}
+
+func isClosed(closed <-chan struct{}) bool {
+	select {
+	case <-closed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+package goLogger
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+const maxStackFrames = 64
+
+// * defer logger.Recover() logs a panic at CRITICAL with its stack and lets the
+// * function return normally; messages add context, e.g. defer logger.Recover("worker", id)
+func (l *Logger) Recover(messages ...any) {
+	if value := recover(); value != nil {
+		l.writePanic(nil, value, nil, messages)
+	}
+}
+
+// * same as Recover, then panics again with the same value, e.g. to still crash the process
+func (l *Logger) RecoverRepanic(messages ...any) {
+	if value := recover(); value != nil {
+		l.writePanic(nil, value, nil, messages)
+		panic(value)
+	}
+}
+
+func (c *Child) Recover(messages ...any) {
+	if value := recover(); value != nil {
+		c.logger.writePanic(c, value, nil, messages)
+	}
+}
+
+func (c *Child) RecoverRepanic(messages ...any) {
+	if value := recover(); value != nil {
+		c.logger.writePanic(c, value, nil, messages)
+		panic(value)
+	}
+}
+
+// * answers 500 when a handler panics, with repanic the panic continues to the
+// * http.Server, which drops the connection; http.ErrAbortHandler is never logged
+func (l *Logger) RecoverHandler(next http.Handler, repanic bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+			l.writePanic(nil, value, []Field{
+				{Key: "method", Value: r.Method},
+				{Key: "path", Value: r.URL.Path},
+				{Key: "remote", Value: r.RemoteAddr},
+				{Key: "user_agent", Value: r.UserAgent()},
+			}, nil)
+			if repanic {
+				panic(value)
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *Logger) writePanic(scope *Child, value any, fields []Field, messages []any) {
+	if len(messages) == 0 {
+		messages = []any{fmt.Sprintf("panic: %v", value)}
+	}
+	fields = append(fields, Field{Key: "panic", Value: fmt.Sprintf("%v", value)})
+	if err, ok := value.(error); ok {
+		fields = append(fields, causeFields(err)...)
+	}
+	fields = append(fields, Field{Key: "stack", Value: panicStack()})
+	var name string
+	if scope != nil {
+		name = scope.name
+	}
+
+	// * one lock for both, no entry lands between the panic and its report
+	l.lock()
+	defer l.unlock()
+	l.writeLocked(scope, name, defaultErrorName, logCritical, fields, messages)
+	if !l.IsClose && l.config.CrashDump {
+		if scope != nil {
+			fields = append(cloneFields(scope.fields), fields...)
+		}
+		l.writeCrash(l.now(), logCritical, fields, messages)
+	}
+}
+
+// * frames from where the panic happened, without the runtime's panic machinery
+// * and this package's recover functions, as "function (file.go:42)"
+func panicStack() causeList {
+	pcs := make([]uintptr, maxStackFrames+16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	var stack causeList
+	started := false
+	for {
+		frame, more := frames.Next()
+		inside := strings.HasPrefix(frame.Function, "runtime.") ||
+			strings.HasPrefix(frame.Function, packagePath) && !strings.HasSuffix(frame.File, "_test.go")
+		if started || !inside {
+			started = true
+			stack = append(stack, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+		}
+		if !more || len(stack) >= maxStackFrames {
+			return stack
+		}
+	}
+}
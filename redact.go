@@ -0,0 +1,132 @@
+package goLogger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultRedactMask = "[REDACTED]"
+	defaultKeyMask    = "***"
+)
+
+var redactPresets = map[string]string{
+	"email":       `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	"credit_card": `\b(?:\d[ \-]?){12,18}\d\b`,
+	"bearer":      `(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`,
+}
+
+type redactor struct {
+	pattern *regexp.Regexp
+	replace string
+}
+
+func compileRedactors(rules []RedactRule) ([]redactor, error) {
+	redactors := make([]redactor, 0, len(rules))
+	for i, rule := range rules {
+		pattern := rule.Pattern
+		if pattern == "" {
+			preset, isExist := redactPresets[rule.Name]
+			if !isExist {
+				return nil, fmt.Errorf("Failed to compile redact rule %d: unknown preset %q", i, rule.Name)
+			}
+			pattern = preset
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to compile redact rule %d: %w", i, err)
+		}
+
+		replace := rule.Replace
+		if replace == "" {
+			replace = defaultRedactMask
+		}
+		redactors = append(redactors, redactor{pattern: re, replace: replace})
+	}
+	return redactors, nil
+}
+
+func (l *Logger) redact(entry *Entry) {
+	if len(l.maskKeys) > 0 {
+		for i, field := range entry.Fields {
+			entry.Fields[i].Value = l.maskValue(field.Key, field.Value)
+		}
+	}
+
+	if len(l.redactors) == 0 {
+		return
+	}
+
+	entry.Message = l.redactString(entry.Message)
+	for i, data := range entry.Data {
+		entry.Data[i] = l.redactString(data)
+	}
+	for i, field := range entry.Fields {
+		entry.Fields[i].Value = l.redactValue(field.Value)
+	}
+}
+
+func (l *Logger) redactValue(value any) any {
+	switch value := value.(type) {
+	case string:
+		return l.redactString(value)
+	case error:
+		return l.redactString(value.Error())
+	case fmt.Stringer:
+		return l.redactString(value.String())
+	case causeList:
+		redacted := make(causeList, len(value))
+		for i, cause := range value {
+			redacted[i] = l.redactString(cause)
+		}
+		return redacted
+	case []Field:
+		redacted := make([]Field, len(value))
+		for i, field := range value {
+			redacted[i] = Field{Key: field.Key, Value: l.redactValue(field.Value)}
+		}
+		return redacted
+	}
+	return value
+}
+
+func (l *Logger) redactString(text string) string {
+	for _, r := range l.redactors {
+		text = r.pattern.ReplaceAllString(text, r.replace)
+	}
+	return text
+}
+
+func (l *Logger) maskValue(key string, value any) any {
+	if l.maskKeys[strings.ToLower(key)] {
+		return defaultKeyMask
+	}
+
+	// * walk nested maps, structured secrets often hide one level down
+	switch nested := value.(type) {
+	case []Field:
+		masked := make([]Field, len(nested))
+		for i, field := range nested {
+			masked[i] = Field{Key: field.Key, Value: l.maskValue(field.Key, field.Value)}
+		}
+		return masked
+	case map[string]any:
+		masked := make(map[string]any, len(nested))
+		for k, v := range nested {
+			masked[k] = l.maskValue(k, v)
+		}
+		return masked
+	case map[string]string:
+		masked := make(map[string]string, len(nested))
+		for k, v := range nested {
+			if l.maskKeys[strings.ToLower(k)] {
+				v = defaultKeyMask
+			}
+			masked[k] = v
+		}
+		return masked
+	}
+	return value
+}
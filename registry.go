@@ -0,0 +1,211 @@
+package goLogger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 設定檔中以名稱引用的輸出，名稱需由 RegisterSink 註冊或為內建輸出
+type SinkConfig struct {
+	Name    string          `json:"name"`              // 註冊名稱，如 "loki"、"stdout"
+	Options json.RawMessage `json:"options,omitempty"` // 交給 factory 的設定
+}
+
+var (
+	sinkMutex sync.RWMutex
+	// * builtin options are the struct fields in snake_case, e.g. {"url": "http://loki:3100", "min_level": "WARNING"}
+	sinkFactories = map[string]func(options json.RawMessage) (Sink, error){
+		"stdout":   writerFactory(os.Stdout),
+		"stderr":   writerFactory(os.Stderr),
+		"azure":    optionsFactory[AzureSink],
+		"datadog":  optionsFactory[DatadogSink],
+		"fluentd":  optionsFactory[FluentdSink],
+		"gcp":      optionsFactory[GCPSink],
+		"journald": optionsFactory[JournaldSink],
+		"logstash": optionsFactory[LogstashSink],
+		"loki":     optionsFactory[LokiSink],
+		"mqtt":     optionsFactory[MQTTSink],
+		"nats":     optionsFactory[NATSSink],
+		"network":  optionsFactory[NetworkSink],
+		"smtp":     optionsFactory[SMTPSink],
+		"unix":     optionsFactory[UnixSink],
+	}
+)
+
+// * e.g. RegisterSink("kafka", func(options json.RawMessage) (goLogger.Sink, error) { ... }),
+// * then {"sinks": [{"name": "kafka", "options": {...}}]} in the config file
+func RegisterSink(name string, factory func(options json.RawMessage) (Sink, error)) {
+	sinkMutex.Lock()
+	defer sinkMutex.Unlock()
+
+	sinkFactories[name] = factory
+}
+
+func sinkFactory(name string) func(options json.RawMessage) (Sink, error) {
+	sinkMutex.RLock()
+	defer sinkMutex.RUnlock()
+
+	return sinkFactories[name]
+}
+
+func sinkNames() string {
+	sinkMutex.RLock()
+	defer sinkMutex.RUnlock()
+
+	names := make([]string, 0, len(sinkFactories))
+	for name := range sinkFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func buildSinks(configs []SinkConfig) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(configs))
+	for _, config := range configs {
+		var sink Sink
+		var err error
+		if factory := sinkFactory(config.Name); factory == nil {
+			err = fmt.Errorf("not registered")
+		} else {
+			sink, err = factory(config.Options)
+		}
+		if err == nil && sink == nil {
+			err = fmt.Errorf("factory returned nil")
+		}
+		if err == nil {
+			if checker, ok := sink.(sinkChecker); ok {
+				err = checker.init()
+			}
+		}
+		if err != nil {
+			// * batching sinks already run their goroutine
+			for _, sink := range sinks {
+				sink.Close()
+			}
+			return nil, fmt.Errorf("Failed to create sink %q: %w", config.Name, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+func writerFactory(writer *os.File) func(options json.RawMessage) (Sink, error) {
+	return func(options json.RawMessage) (Sink, error) {
+		sink := &WriterSink{}
+		if err := decodeSinkOptions(options, sink); err != nil {
+			return nil, err
+		}
+		sink.Writer = writer
+		return sink, nil
+	}
+}
+
+func optionsFactory[T any, P interface {
+	*T
+	Sink
+}](options json.RawMessage) (Sink, error) {
+	sink := P(new(T))
+	if err := decodeSinkOptions(options, sink); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// * keys match exported fields ignoring case and underscores, durations may be
+// * written as "5s" and *tls.Config fields take TLSFiles, e.g. {"tls": {"ca_file": "ca.pem"}}
+func decodeSinkOptions(options json.RawMessage, target any) error {
+	if len(bytes.TrimSpace(options)) == 0 {
+		return nil
+	}
+	var values map[string]any
+	if err := json.Unmarshal(options, &values); err != nil {
+		return fmt.Errorf("Failed to parse options: %w", err)
+	}
+	value := reflect.ValueOf(target).Elem()
+	values, tlsConfigs, err := normalizeOptions(values, value.Type())
+	if err != nil {
+		return fmt.Errorf("Failed to parse options: %w", err)
+	}
+
+	normalized, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("Failed to parse options: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(normalized))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("Failed to parse options: %w", err)
+	}
+	for name, config := range tlsConfigs {
+		value.FieldByName(name).Set(reflect.ValueOf(config))
+	}
+	return nil
+}
+
+// * keys renamed to their field, plus the TLS configs to set after decoding
+func normalizeOptions(values map[string]any, kind reflect.Type) (map[string]any, map[string]*tls.Config, error) {
+	normalized := make(map[string]any, len(values))
+	tlsConfigs := map[string]*tls.Config{}
+	for key, item := range values {
+		field, ok := optionField(kind, key)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown option %q", key)
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		switch {
+		case field.Type == reflect.TypeOf(&tls.Config{}):
+			data, _ := json.Marshal(item)
+			var files TLSFiles
+			if err := json.Unmarshal(data, &files); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", key, err)
+			}
+			config, err := files.Config()
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", key, err)
+			}
+			tlsConfigs[field.Name] = config
+			continue
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			if text, ok := item.(string); ok {
+				duration, err := time.ParseDuration(text)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s: %w", key, err)
+				}
+				item = int64(duration)
+			}
+		case fieldType.Kind() == reflect.Struct:
+			if nested, ok := item.(map[string]any); ok {
+				var err error
+				if item, _, err = normalizeOptions(nested, fieldType); err != nil {
+					return nil, nil, fmt.Errorf("%s.%w", key, err)
+				}
+			}
+		}
+		normalized[field.Name] = item
+	}
+	return normalized, tlsConfigs, nil
+}
+
+func optionField(kind reflect.Type, key string) (reflect.StructField, bool) {
+	name := strings.ReplaceAll(key, "_", "")
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if field.IsExported() && field.Type.Kind() != reflect.Func && strings.EqualFold(field.Name, name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,168 @@
+package goLogger
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// * for external logrotate, files were moved away so only close and open again
+func (l *Logger) Reopen() error {
+	l.lock()
+	defer l.unlock()
+
+	if l.IsClose {
+		return fmt.Errorf("logger is closed")
+	}
+
+	var errs []error
+	for filename := range l.File {
+		if err := l.reopenFile(filename); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := l.initHandler(); err != nil {
+		errs = append(errs, fmt.Errorf("Failed to re-init: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors reopening log files: %v", errs)
+	}
+	return nil
+}
+
+// * called under lock, the handlers still need initHandler
+func (l *Logger) reopenFile(filename string) error {
+	newFile, err := l.open(filename, fileMode(l.config))
+	if err != nil {
+		return err
+	}
+	l.File[filename].Close()
+	l.File[filename] = newFile
+	delete(l.sizes, filename)
+
+	if l.config.Audit {
+		l.chain[filename] = lastChainHash(l.fs(), l.livePath(filename))
+	}
+	return applyMode(l.fs(), l.livePath(filename), l.config.FileMode)
+}
+
+// * the live path no longer leads to the open file, it was deleted or replaced;
+// * file systems that can't tell count as unchanged
+func (l *Logger) fileMoved(file File, path string) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	live, err := l.fs().Stat(path)
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	if node, ok := opened.Sys().(*memNode); ok {
+		return node != live.Sys()
+	}
+	if _, ok := l.fs().(osFS); ok {
+		return !os.SameFile(opened, live)
+	}
+	return false
+}
+
+// * called under lock; a deleted or replaced file is opened again instead of
+// * writing to an unlinked inode, a truncated one restarts its audit chain
+func (l *Logger) checkExternal(filename string) (bool, error) {
+	file, isExist := l.File[filename]
+	if !isExist {
+		return false, nil
+	}
+	if l.fileMoved(file, l.livePath(filename)) {
+		if err := l.reopenFile(filename); err != nil {
+			return false, fmt.Errorf("Failed to recreate %s: %w", filename, err)
+		}
+		if !l.config.SharedPath {
+			// * with SharedPath it is usually another process rotating
+			l.internalError(fmt.Errorf("%s was removed or replaced externally, reopened", filename))
+		}
+		return true, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, nil
+	}
+	if size, isExist := l.sizes[filename]; isExist && info.Size() < size {
+		if l.config.Audit {
+			l.chain[filename] = lastChainHash(l.fs(), l.livePath(filename))
+		}
+		l.internalError(fmt.Errorf("%s was truncated externally from %d to %d bytes", filename, size, info.Size()))
+	}
+	l.sizes[filename] = info.Size()
+	return false, nil
+}
+
+func (l *Logger) startWatchTimer() {
+	interval, stop := l.config.WatchInterval, l.stopTimer
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.lock()
+				if !l.IsClose {
+					l.watchFiles()
+				}
+				l.unlock()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// * called under lock
+func (l *Logger) watchFiles() {
+	reopened := false
+	for _, filename := range l.fileNames() {
+		moved, err := l.checkExternal(filename)
+		if err != nil {
+			l.internalError(err)
+		}
+		reopened = reopened || moved
+	}
+	if reopened {
+		if err := l.initHandler(); err != nil {
+			l.internalError(fmt.Errorf("Failed to re-init: %w", err))
+		}
+	}
+}
+
+func (l *Logger) handleSIGHUP() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	stop := l.stopTimer
+
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				if err := l.Reopen(); err != nil {
+					l.internalError(err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
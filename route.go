@@ -0,0 +1,94 @@
+package goLogger
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// * file names without .log get it appended, like Rotate and FlushFile accept
+func compileRoutes(routes []Route) []Route {
+	compiled := make([]Route, len(routes))
+	for i, route := range routes {
+		route.Prefix = strings.Trim(route.Prefix, ".")
+		if !strings.HasSuffix(route.File, ".log") {
+			route.File += ".log"
+		}
+		compiled[i] = route
+	}
+	return compiled
+}
+
+// * http.client → http.client, http
+func namePrefixes(name string) []string {
+	var prefixes []string
+	for name != "" {
+		prefixes = append(prefixes, name)
+		index := strings.LastIndexByte(name, '.')
+		if index < 0 {
+			break
+		}
+		name = name[:index]
+	}
+	return prefixes
+}
+
+// * level files first, then one file per route
+func (l *Logger) baseNames() []string {
+	names := []string{defaultDebugName, defaultOutputName, defaultErrorName}
+	for _, route := range l.routes {
+		names = append(names, route.File)
+	}
+	return names
+}
+
+// * shared files, then the same set under every opened tenant
+func (l *Logger) fileNames() []string {
+	names := l.baseNames()
+	for _, tenant := range l.tenantNames() {
+		for _, name := range l.baseNames() {
+			names = append(names, filepath.Join(tenant, name))
+		}
+	}
+	return names
+}
+
+// * longest matching prefix wins, the level file is used when nothing matches
+func (l *Logger) routeFile(name string, fields []Field, filename string) string {
+	if len(l.routes) == 0 {
+		return filename
+	}
+	if name == "" {
+		name = entryComponent(&Entry{Fields: fields})
+	}
+	for _, prefix := range namePrefixes(name) {
+		for _, route := range l.routes {
+			if route.Prefix == prefix {
+				return route.File
+			}
+		}
+	}
+	return filename
+}
+
+func (l *Logger) findRoute(filename string) (Route, bool) {
+	for _, route := range l.routes {
+		if route.File == filename {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+func (l *Logger) maxSize(filename string) int64 {
+	if route, isExist := l.findRoute(filepath.Base(filename)); isExist && route.MaxSize > 0 {
+		return route.MaxSize
+	}
+	return l.config.MaxSize
+}
+
+func (l *Logger) maxBackup(filename string) int {
+	if route, isExist := l.findRoute(filepath.Base(filename)); isExist && route.MaxBackup > 0 {
+		return route.MaxBackup
+	}
+	return l.config.MaxBackup
+}
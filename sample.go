@@ -0,0 +1,33 @@
+package goLogger
+
+import (
+	"fmt"
+	"time"
+)
+
+// * called under lock, zap-style: the first Initial entries with the same level
+// * and message per Tick are kept, then every Thereafter-th
+func (l *Logger) sampled(level string, message any) bool {
+	sampling := l.config.Sampling
+	if sampling == nil {
+		return true
+	}
+
+	tick := sampling.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	now := l.now()
+	if l.samples == nil || now.Sub(l.sampleStart) >= tick {
+		l.samples = make(map[string]int)
+		l.sampleStart = now
+	}
+
+	key := level + "\x00" + fmt.Sprint(message)
+	l.samples[key]++
+	count := l.samples[key]
+	if count <= sampling.Initial {
+		return true
+	}
+	return sampling.Thereafter > 0 && (count-sampling.Initial)%sampling.Thereafter == 0
+}
@@ -0,0 +1,155 @@
+package goLogger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// * entries beyond this drop the oldest, a long request can't grow without bound
+const maxScopedEntries = 1000
+
+// 請求範圍的 logger，DEBUG 與 TRACE 先暫存於記憶體，發生 ERROR 以上時連同錯誤一併寫入，否則於 End 捨棄
+type Scoped struct {
+	child   *Child
+	mutex   sync.Mutex
+	pending []scopedEntry
+	dropped int
+	failed  bool
+}
+
+type scopedEntry struct {
+	time     time.Time
+	level    string
+	messages []any
+	file     string
+	line     int
+}
+
+var _ Interface = (*Scoped)(nil)
+
+// * e.g. one per request: scope := logger.Scoped(); defer scope.End()
+func (l *Logger) Scoped() *Scoped {
+	return &Scoped{child: &Child{logger: l}}
+}
+
+func (c *Child) Scoped() *Scoped {
+	return &Scoped{child: c}
+}
+
+// * buffered entries skip the level filter and sampling, they are only written on failure
+func (s *Scoped) record(level string, messages []any) {
+	if len(messages) == 0 {
+		return
+	}
+	// * Scoped.Debug → record → caller
+	_, file, line, _ := runtime.Caller(2)
+
+	l := s.child.logger
+	l.Mutex.RLock()
+	closed, now := l.IsClose, l.now()
+	l.Mutex.RUnlock()
+	if closed {
+		return
+	}
+
+	entry := scopedEntry{time: now, level: level, messages: messages, file: file, line: line}
+	s.mutex.Lock()
+	failed := s.failed
+	if !failed {
+		if len(s.pending) >= maxScopedEntries {
+			s.pending = s.pending[1:]
+			s.dropped++
+		}
+		s.pending = append(s.pending, entry)
+	}
+	s.mutex.Unlock()
+
+	if failed {
+		// * the request already failed, detail is written as it comes
+		s.write([]scopedEntry{entry}, 0)
+	}
+}
+
+// * called before an ERROR or above is written, the context goes out first with its own time
+func (s *Scoped) fail() {
+	s.mutex.Lock()
+	pending, dropped := s.pending, s.dropped
+	s.pending, s.dropped, s.failed = nil, 0, true
+	s.mutex.Unlock()
+
+	s.write(pending, dropped)
+}
+
+func (s *Scoped) write(entries []scopedEntry, dropped int) {
+	if len(entries) == 0 {
+		return
+	}
+	l := s.child.logger
+	l.lock()
+	defer l.unlock()
+
+	if l.IsClose {
+		return
+	}
+	if dropped > 0 {
+		l.compose(s.child, defaultDebugName, logDebug, nil, entries[0].time, nil,
+			[]any{fmt.Sprintf("%d earlier entries dropped from the scoped buffer", dropped)})
+	}
+	for _, entry := range entries {
+		var caller *Field
+		if entry.file != "" {
+			caller = &Field{Key: "caller", Value: fmt.Sprintf("%s:%d", filepath.Base(entry.file), entry.line)}
+		}
+		l.compose(s.child, levelFile[entry.level], entry.level, nil, entry.time, caller, entry.messages)
+	}
+}
+
+// * discards the buffered entries, for a request that ended without an error
+func (s *Scoped) End() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending, s.dropped, s.failed = nil, 0, false
+}
+
+func (s *Scoped) Debug(messages ...any) {
+	s.record(logDebug, messages)
+}
+
+func (s *Scoped) Trace(messages ...any) {
+	s.record(logTrace, messages)
+}
+
+func (s *Scoped) Info(messages ...any) {
+	s.child.Info(messages...)
+}
+
+func (s *Scoped) Notice(messages ...any) {
+	s.child.Notice(messages...)
+}
+
+func (s *Scoped) Warn(messages ...any) {
+	s.child.Warn(messages...)
+}
+
+func (s *Scoped) WarnError(err error, messages ...any) error {
+	return s.child.WarnError(err, messages...)
+}
+
+func (s *Scoped) Error(err error, messages ...any) error {
+	s.fail()
+	return s.child.Error(err, messages...)
+}
+
+func (s *Scoped) Fatal(err error, messages ...any) error {
+	s.fail()
+	return s.child.Fatal(err, messages...)
+}
+
+func (s *Scoped) Critical(err error, messages ...any) error {
+	s.fail()
+	return s.child.Critical(err, messages...)
+}
@@ -0,0 +1,576 @@
+package goLogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultBatchInterval = 5 * time.Second
+	maxPendingEntries    = 10000
+	remoteTimeout        = 30 * time.Second
+	defaultMinBackoff    = time.Second
+	defaultMaxBackoff    = time.Minute
+	defaultRetryJitter   = 0.2
+	defaultBreakerFails  = 5
+	defaultBreakerCool   = 30 * time.Second
+)
+
+// 日誌檔案以外的輸出目的地，Write 於寫入鎖內呼叫，不可阻塞，遠端傳送應於背景進行
+type Sink interface {
+	Write(entry Entry) error
+	Flush()
+	Close()
+}
+
+// * sinks configured by fields check them once and start their goroutines,
+// * New and Reconfigure call it after Validate
+type sinkChecker interface {
+	init() error
+}
+
+// * sinks shipping in the background report failures through the logger
+type errorReporter interface {
+	reportErrors(report func(error))
+}
+
+// * sinks holding entries count those never delivered, CloseContext sums them
+type deliveryCounter interface {
+	undelivered() int
+}
+
+// * sinks holding entries report how many wait to be sent, Stats sums them
+type queueCounter interface {
+	queued() int
+}
+
+func initSinks(sinks []Sink) error {
+	var errs []error
+	for i, sink := range sinks {
+		if checker, ok := sink.(sinkChecker); ok {
+			if err := checker.init(); err != nil {
+				errs = append(errs, fmt.Errorf("Invalid config: sink %d: %w", i, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l *Logger) startSinks() {
+	for _, sink := range l.sinks {
+		if reporter, ok := sink.(errorReporter); ok {
+			reporter.reportErrors(l.internalError)
+		}
+	}
+}
+
+// * called under lock after the entry is written to its file, data is its
+// * encoding in the file format and is reused by line sinks of that format
+func (l *Logger) writeSinks(entry Entry, data []byte) {
+	if len(l.sinks) == 0 {
+		return
+	}
+	var jsonLine, textLine []byte
+	if l.config.Type == "json" {
+		jsonLine = data
+	} else {
+		textLine = data
+	}
+	line := func(format string) []byte {
+		if format == "" {
+			format = l.config.Type
+		}
+		if format == "json" {
+			if jsonLine == nil {
+				jsonLine = l.encode(&entry, "json")
+			}
+			return jsonLine
+		}
+		if textLine == nil {
+			textLine = l.encode(&entry, "text")
+		}
+		return textLine
+	}
+
+	for _, sink := range l.sinks {
+		var err error
+		if writer, ok := sink.(lineSink); ok {
+			err = writer.writeLine(entry, line)
+		} else {
+			err = sink.Write(entry)
+		}
+		if err != nil {
+			l.internalError(fmt.Errorf("Failed to write %T: %w", sink, err))
+		}
+	}
+}
+
+// 遠端輸出失敗時的重試策略，延遲由 MinBackoff 起倍增至 MaxBackoff 並加入隨機抖動
+type RetryPolicy struct {
+	MaxAttempts int                              // 每批最多嘗試次數（含第一次），預設 0 不限制
+	MinBackoff  time.Duration                    // 第一次重試前的等待，預設 1 秒
+	MaxBackoff  time.Duration                    // 等待上限，預設 1 分鐘
+	Jitter      float64                          // 隨機縮短等待的比例（0~1），預設 0.2，避免多個實例同時重試
+	DeadLetter  func(entries []Entry, err error) // 放棄的日誌（超過嘗試次數或關閉時仍失敗），預設經由 OnInternalError 回報筆數
+}
+
+// 熔斷器，連續失敗達門檻後於冷卻期間停止嘗試並捨棄新日誌，冷卻結束後以下一批試探
+type CircuitBreaker struct {
+	Threshold int           // 連續失敗幾批後斷開，預設 5
+	Cooldown  time.Duration // 斷開後停止嘗試的時間，預設 30 秒
+}
+
+// * batches entries and sends them from its own goroutine,
+// * shared by the remote sinks
+type batcher struct {
+	mutex    sync.Mutex
+	pending  []Entry
+	dropped  int
+	size     int
+	interval time.Duration
+	send     func([]Entry) error
+	report   func(error)
+	// * with a retry policy failed batches stay queued and are retried after a backoff
+	retry    *RetryPolicy
+	attempts int
+	retryAt  time.Time
+	// * consecutive failed sends, the circuit is open until openUntil
+	breaker   *CircuitBreaker
+	failures  int
+	openUntil time.Time
+	rejected  int
+	// * entries taken into memory and sent from there, lost counts those
+	// * turned away or given up from the disk queue
+	accepted  int
+	delivered int
+	lost      int
+	// * with a disk queue entries are appended there instead of pending
+	queue    *diskQueue
+	queueErr error
+	kick     chan struct{}
+	flush    chan chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	close    sync.Once
+}
+
+func newBatcher(size int, interval time.Duration, retry *RetryPolicy, breaker *CircuitBreaker, queue *DiskQueue, send func([]Entry) error) (*batcher, error) {
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	if interval <= 0 {
+		interval = defaultBatchInterval
+	}
+	var disk *diskQueue
+	if queue != nil {
+		var err error
+		if disk, err = openDiskQueue(queue); err != nil {
+			return nil, err
+		}
+		// * queued entries are kept until delivered
+		if retry == nil {
+			retry = &RetryPolicy{}
+		}
+	}
+	b := &batcher{
+		size:     size,
+		interval: interval,
+		send:     send,
+		retry:    retry,
+		breaker:  breaker,
+		queue:    disk,
+		kick:     make(chan struct{}, 1),
+		flush:    make(chan chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if disk != nil && disk.pending() > 0 {
+		// * replay what was left from the last run
+		b.kick <- struct{}{}
+	}
+	go b.loop()
+	return b, nil
+}
+
+func (b *batcher) setReport(report func(error)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.report = report
+}
+
+func (b *batcher) add(entry Entry) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.queue != nil {
+		// * spilled to disk even while the circuit is open, MaxSize bounds it
+		dropped, err := b.queue.append(entry)
+		b.dropped += dropped
+		b.lost += dropped
+		if err != nil {
+			b.dropped++
+			b.lost++
+			b.queueErr = err
+		}
+		if b.queue.pending() >= b.size {
+			select {
+			case b.kick <- struct{}{}:
+			default:
+			}
+		}
+		return
+	}
+
+	if time.Now().Before(b.openUntil) {
+		b.rejected++
+		b.lost++
+		return
+	}
+
+	// * a collector that stays down must not grow the process without bound
+	if len(b.pending) >= maxPendingEntries {
+		b.pending = b.pending[1:]
+		b.dropped++
+	}
+	b.pending = append(b.pending, entry)
+	b.accepted++
+	if len(b.pending) >= b.size {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (b *batcher) loop() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.kick:
+		case reply := <-b.flush:
+			// * a held batch is sent once the running backoff ends, not skipped;
+			// * Close cuts the wait short and sends it right away
+			if wait := time.Until(b.retryAt); b.retry != nil && wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-b.stop:
+					timer.Stop()
+					b.ship(true)
+					close(reply)
+					return
+				}
+			}
+			b.ship(false)
+			close(reply)
+			continue
+		case <-b.stop:
+			b.ship(true)
+			return
+		}
+		b.ship(false)
+	}
+}
+
+// * final ignores the backoff, what still fails then is dead-lettered
+func (b *batcher) ship(final bool) {
+	if b.retry != nil && !final && time.Now().Before(b.retryAt) {
+		return
+	}
+
+	b.mutex.Lock()
+	open := time.Now().Before(b.openUntil)
+	if open && !final {
+		b.mutex.Unlock()
+		return
+	}
+	entries, dropped, queueErr, report := b.pending, b.dropped, b.queueErr, b.report
+	b.pending, b.dropped, b.queueErr = nil, 0, nil
+	rejected := 0
+	if open {
+		rejected, b.rejected = b.rejected, 0
+	}
+	b.mutex.Unlock()
+
+	if report == nil {
+		report = func(error) {}
+	}
+	if queueErr != nil {
+		report(fmt.Errorf("Failed to queue: %w", queueErr))
+	}
+	if dropped > 0 {
+		report(fmt.Errorf("Failed to queue: dropped %d entries while the destination was unavailable", dropped))
+	}
+	if open {
+		// * a dead destination must not hold up Close
+		if rejected > 0 {
+			report(fmt.Errorf("Failed to send: dropped %d entries while the circuit was open", rejected))
+		}
+		if len(entries) > 0 {
+			b.giveUp(entries, fmt.Errorf("circuit open"), report)
+		}
+		return
+	}
+	if b.queue != nil {
+		b.shipQueue(final, report)
+		return
+	}
+	for len(entries) > 0 {
+		n := min(b.size, len(entries))
+		err := b.send(entries[:n])
+		if err == nil {
+			b.mutex.Lock()
+			b.delivered += n
+			b.mutex.Unlock()
+			b.recover(report)
+			b.attempts = 0
+			entries = entries[n:]
+			continue
+		}
+		report(err)
+		opened := b.trip(report)
+		if b.retry == nil {
+			entries = entries[n:]
+			if opened && !final {
+				b.requeue(entries)
+				return
+			}
+			continue
+		}
+		if final {
+			b.giveUp(entries, err, report)
+			return
+		}
+		if b.attempts++; b.retry.MaxAttempts > 0 && b.attempts >= b.retry.MaxAttempts {
+			// * this batch is abandoned, the rest starts over with fresh attempts
+			b.giveUp(entries[:n], err, report)
+			b.attempts = 0
+			entries = entries[n:]
+		}
+		b.requeue(entries)
+		b.retryAt = time.Now().Add(b.backoff())
+		return
+	}
+}
+
+// * entries leave the disk queue only once sent or dead-lettered,
+// * on Close the rest stays there for the next start
+func (b *batcher) shipQueue(final bool, report func(error)) {
+	for {
+		entries, mark, err := b.queue.peek(b.size)
+		if err != nil {
+			report(fmt.Errorf("Failed to read queue: %w", err))
+			return
+		}
+		if mark.frames == 0 {
+			return
+		}
+		if len(entries) > 0 {
+			err = b.send(entries)
+		}
+		if err == nil {
+			b.recover(report)
+			b.attempts = 0
+			b.queue.commit(mark)
+			continue
+		}
+		report(err)
+		b.trip(report)
+		if final {
+			return
+		}
+		if b.attempts++; b.retry.MaxAttempts > 0 && b.attempts >= b.retry.MaxAttempts {
+			b.giveUp(entries, err, report)
+			b.attempts = 0
+			b.queue.commit(mark)
+			b.mutex.Lock()
+			b.lost += len(entries)
+			b.mutex.Unlock()
+		}
+		b.retryAt = time.Now().Add(b.backoff())
+		return
+	}
+}
+
+// * counts a failed send, opening the circuit once the threshold is reached
+// * and again whenever the probe after a cooldown fails
+func (b *batcher) trip(report func(error)) bool {
+	if b.breaker == nil {
+		return false
+	}
+	threshold, cooldown := b.breaker.Threshold, b.breaker.Cooldown
+	if threshold <= 0 {
+		threshold = defaultBreakerFails
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCool
+	}
+	if b.failures++; b.failures < threshold {
+		return false
+	}
+	if b.failures == threshold {
+		report(fmt.Errorf("Failed to send: circuit open for %s after %d consecutive failures", cooldown, threshold))
+	}
+	b.mutex.Lock()
+	b.openUntil = time.Now().Add(cooldown)
+	b.mutex.Unlock()
+	return true
+}
+
+func (b *batcher) recover(report func(error)) {
+	if b.failures == 0 {
+		return
+	}
+	b.failures = 0
+	b.mutex.Lock()
+	rejected := b.rejected
+	b.rejected = 0
+	b.mutex.Unlock()
+	if rejected > 0 {
+		report(fmt.Errorf("Failed to send: dropped %d entries while the circuit was open", rejected))
+	}
+}
+
+func (b *batcher) giveUp(entries []Entry, err error, report func(error)) {
+	if b.retry != nil && b.retry.DeadLetter != nil {
+		b.retry.DeadLetter(entries, err)
+		return
+	}
+	report(fmt.Errorf("Failed to send: dropped %d entries: %w", len(entries), err))
+}
+
+// * unsent entries go back in front of those added meanwhile
+func (b *batcher) requeue(entries []Entry) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.pending = append(entries, b.pending...)
+	if over := len(b.pending) - maxPendingEntries; over > 0 {
+		b.pending = b.pending[over:]
+		b.dropped += over
+	}
+}
+
+func (b *batcher) backoff() time.Duration {
+	minBackoff, maxBackoff, jitter := b.retry.MinBackoff, b.retry.MaxBackoff, b.retry.Jitter
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if jitter <= 0 {
+		jitter = defaultRetryJitter
+	}
+	delay := min(minBackoff<<min(max(b.attempts, 1)-1, 16), maxBackoff)
+	if spread := int64(float64(delay) * min(jitter, 1)); spread > 0 {
+		delay -= time.Duration(rand.Int64N(spread))
+	}
+	return delay
+}
+
+// * blocks until everything queued so far has been sent
+func (b *batcher) Flush() {
+	reply := make(chan struct{})
+	select {
+	case b.flush <- reply:
+		<-reply
+	case <-b.done:
+	}
+}
+
+// * accepted but not sent, whether given up or still held, plus those lost;
+// * entries left in a disk queue are kept for the next start
+func (b *batcher) undelivered() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.accepted - b.delivered + b.lost
+}
+
+// * entries waiting in memory plus those in the disk queue, not the batch being sent
+func (b *batcher) queued() int {
+	b.mutex.Lock()
+	queued := len(b.pending)
+	b.mutex.Unlock()
+
+	if b.queue != nil {
+		queued += b.queue.pending()
+	}
+	return queued
+}
+
+func (b *batcher) Close() {
+	b.close.Do(func() {
+		close(b.stop)
+		<-b.done
+		if b.queue != nil {
+			b.queue.close()
+		}
+	})
+	<-b.done
+}
+
+// * the attributes of the JSON file format, so remote entries match local ones
+func entryAttributes(entry Entry) map[string]any {
+	attrs := make(map[string]any)
+	if err := json.Unmarshal(encodeJSON(&entry), &attrs); err != nil {
+		attrs = map[string]any{"msg": entry.Message}
+	}
+	// * slog writes DEBUG, INFO, WARN and ERROR, keep ours
+	attrs["level"] = entry.Level
+	return attrs
+}
+
+// * encoding is a registered compression sent as Content-Encoding, empty for none
+func postBatch(ctx context.Context, client *http.Client, target string, body []byte, encoding string, headers map[string]string) error {
+	if encoding != "" {
+		var err error
+		if body, err = compress(encoding, body); err != nil {
+			return fmt.Errorf("Failed to compress: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: remoteTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s", resp.Status, bytes.TrimSpace(message))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
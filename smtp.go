@@ -0,0 +1,232 @@
+package goLogger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultSMTPSubject = `[{{.Level}}] {{.Count}} log entries from {{.Host}}`
+	defaultSMTPBody    = `{{range .Entries}}{{text .}}{{end}}`
+)
+
+// 以電子郵件批次寄送 FATAL、CRITICAL 日誌，適合沒有告警系統的小型部署
+type SMTPSink struct {
+	Addr      string          // SMTP 伺服器，如 "smtp.example.com:587"
+	Username  string          // 驗證帳號，空值代表不驗證
+	Password  string          // 驗證密碼
+	From      string          // 寄件者
+	To        []string        // 收件者
+	TLS       bool            // 使用隱含式 TLS（通常為 465 埠），否則於伺服器支援時使用 STARTTLS
+	TLSConfig *tls.Config     // 自訂 TLS 設定（如 mTLS），設定後伺服器不支援 STARTTLS 時拒絕以明文寄送
+	Subject   string          // 主旨 text/template，可用 .Level、.Count、.Host、.Entries，預設 "[{{.Level}}] {{.Count}} log entries from {{.Host}}"
+	Body      string          // 內文 text/template，{{text .}} 輸出單筆的文字格式，預設列出所有日誌
+	MinLevel  string          // 寄送的最低層級，預設 "FATAL"
+	BatchSize int             // 單封郵件最多筆數，預設 100
+	Interval  time.Duration   // 彙整寄送的間隔，預設 5 秒
+	Retry     *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker   *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue     *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	once      sync.Once
+	batcher   *batcher
+	subject   *template.Template
+	body      *template.Template
+	minLevel  string
+	initErr   error
+}
+
+type smtpMessage struct {
+	Level   string  // 此封郵件中最高的層級
+	Count   int     // 筆數
+	Host    string  // 主機名稱
+	Entries []Entry // 日誌
+}
+
+func (s *SMTPSink) init() error {
+	s.once.Do(func() {
+		if s.Addr == "" || s.From == "" || len(s.To) == 0 {
+			s.initErr = fmt.Errorf("Addr, From and To are required")
+			return
+		}
+		funcs := template.FuncMap{"text": func(entry Entry) string { return string(encodeText(&entry)) }}
+		subject, body := s.Subject, s.Body
+		if subject == "" {
+			subject = defaultSMTPSubject
+		}
+		if body == "" {
+			body = defaultSMTPBody
+		}
+		if s.subject, s.initErr = template.New("subject").Funcs(funcs).Parse(subject); s.initErr != nil {
+			s.initErr = fmt.Errorf("Failed to parse subject: %w", s.initErr)
+			return
+		}
+		if s.body, s.initErr = template.New("body").Funcs(funcs).Parse(body); s.initErr != nil {
+			s.initErr = fmt.Errorf("Failed to parse body: %w", s.initErr)
+			return
+		}
+		s.minLevel = logFatal
+		if s.MinLevel != "" {
+			if s.minLevel, s.initErr = parseLevel(s.MinLevel); s.initErr != nil {
+				return
+			}
+		}
+		s.batcher, s.initErr = newBatcher(s.BatchSize, s.Interval, s.Retry, s.Breaker, s.Queue, s.send)
+	})
+	return s.initErr
+}
+
+func (s *SMTPSink) reportErrors(report func(error)) {
+	if s.init() == nil {
+		s.batcher.setReport(report)
+	}
+}
+
+func (s *SMTPSink) Write(entry Entry) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+	if levelRank[entry.Level] < levelRank[s.minLevel] {
+		return nil
+	}
+	s.batcher.add(entry)
+	return nil
+}
+
+func (s *SMTPSink) Flush() {
+	if s.init() == nil {
+		s.batcher.Flush()
+	}
+}
+
+func (s *SMTPSink) Close() {
+	if s.init() == nil {
+		s.batcher.Close()
+	}
+}
+
+func (s *SMTPSink) undelivered() int {
+	if s.init() != nil {
+		return 0
+	}
+	return s.batcher.undelivered()
+}
+
+func (s *SMTPSink) queued() int {
+	if s.init() != nil {
+		return 0
+	}
+	return s.batcher.queued()
+}
+
+func (s *SMTPSink) send(entries []Entry) error {
+	host, _ := os.Hostname()
+	message := smtpMessage{Count: len(entries), Host: host, Entries: entries}
+	for _, entry := range entries {
+		if message.Level == "" || levelRank[entry.Level] > levelRank[message.Level] {
+			message.Level = entry.Level
+		}
+	}
+
+	var subject, body bytes.Buffer
+	if err := s.subject.Execute(&subject, message); err != nil {
+		return fmt.Errorf("Failed to render subject: %w", err)
+	}
+	if err := s.body.Execute(&body, message); err != nil {
+		return fmt.Errorf("Failed to render body: %w", err)
+	}
+
+	var data bytes.Buffer
+	fmt.Fprintf(&data, "From: %s\r\n", s.From)
+	fmt.Fprintf(&data, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&data, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())))
+	fmt.Fprintf(&data, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	data.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	data.WriteString(strings.ReplaceAll(body.String(), "\n", "\r\n"))
+
+	if err := s.deliver(data.Bytes()); err != nil {
+		return fmt.Errorf("Failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPSink) deliver(data []byte) error {
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	if !s.TLS && s.TLSConfig == nil {
+		// * upgrades with STARTTLS when the server offers it
+		return smtp.SendMail(s.Addr, auth, s.From, s.To, data)
+	}
+
+	config := &tls.Config{ServerName: host}
+	if s.TLSConfig != nil {
+		config = s.TLSConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName = host
+		}
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	if s.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.Addr, config)
+	} else {
+		conn, err = dialer.Dial("tcp", s.Addr)
+	}
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !s.TLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS", s.Addr)
+		}
+		if err := client.StartTLS(config); err != nil {
+			return err
+		}
+	}
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.From); err != nil {
+		return err
+	}
+	for _, to := range s.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
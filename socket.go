@@ -0,0 +1,335 @@
+package goLogger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// * frames are a big-endian uint32 length followed by a wireEntry in JSON
+const maxFrameSize = 16 << 20
+
+type wireEntry struct {
+	Time    time.Time   `json:"time"`
+	Level   string      `json:"level"`
+	Message string      `json:"message"`
+	Data    []string    `json:"data,omitempty"`
+	Fields  []wireField `json:"fields,omitempty"`
+}
+
+// * groups and cause lists keep their shape across the wire
+type wireField struct {
+	Key    string      `json:"key"`
+	Value  any         `json:"value,omitempty"`
+	Group  []wireField `json:"group,omitempty"`
+	Causes []string    `json:"causes,omitempty"`
+}
+
+func toWireFields(fields []Field) []wireField {
+	wire := make([]wireField, len(fields))
+	for i, field := range fields {
+		wire[i].Key = field.Key
+		switch value := field.Value.(type) {
+		case []Field:
+			wire[i].Group = toWireFields(value)
+		case causeList:
+			wire[i].Causes = value
+		case error:
+			wire[i].Value = value.Error()
+		case fmt.Stringer:
+			wire[i].Value = value.String()
+		default:
+			wire[i].Value = value
+		}
+	}
+	return wire
+}
+
+func fromWireFields(wire []wireField) []Field {
+	fields := make([]Field, len(wire))
+	for i, field := range wire {
+		fields[i].Key = field.Key
+		switch {
+		case field.Group != nil:
+			fields[i].Value = fromWireFields(field.Group)
+		case field.Causes != nil:
+			fields[i].Value = causeList(field.Causes)
+		default:
+			fields[i].Value = field.Value
+		}
+	}
+	return fields
+}
+
+func appendFrame(buf []byte, entry Entry) ([]byte, error) {
+	data, err := json.Marshal(wireEntry{
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Message: entry.Message,
+		Data:    entry.Data,
+		Fields:  toWireFields(entry.Fields),
+	})
+	if err != nil {
+		return buf, err
+	}
+	if len(data) > maxFrameSize {
+		return buf, fmt.Errorf("entry of %d bytes exceeds the frame limit", len(data))
+	}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+	return append(buf, data...), nil
+}
+
+func readFrame(r *bufio.Reader) (Entry, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return Entry{}, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxFrameSize {
+		return Entry{}, fmt.Errorf("frame of %d bytes exceeds the limit", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return Entry{}, err
+	}
+	return decodeFrame(data)
+}
+
+func decodeFrame(data []byte) (Entry, error) {
+	var wire wireEntry
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return Entry{}, fmt.Errorf("Failed to decode frame: %w", err)
+	}
+	if _, err := parseLevel(wire.Level); err != nil {
+		return Entry{}, fmt.Errorf("Failed to decode frame: %w", err)
+	}
+	return Entry{
+		Time:    wire.Time,
+		Level:   wire.Level,
+		Message: wire.Message,
+		Data:    wire.Data,
+		Fields:  fromWireFields(wire.Fields),
+	}, nil
+}
+
+// 將日誌以長度前綴的框架寫入 Unix socket，搭配 ListenUnix 於本機收集
+type UnixSink struct {
+	Path      string          // socket 路徑，如 "/run/app/logs.sock"
+	MinLevel  string          // 送出的最低層級，預設全部
+	BatchSize int             // 單次寫入最多筆數，預設 100
+	Interval  time.Duration   // 批次送出的間隔，預設 5 秒
+	Retry     *RetryPolicy    // 失敗時的重試策略，預設不重試
+	Breaker   *CircuitBreaker // 連續失敗時暫停嘗試，預設不啟用
+	Queue     *DiskQueue      // 待送日誌寫入磁碟，重啟後依序重送，預設僅存於記憶體
+	once      sync.Once
+	batcher   *batcher
+	conn      netConn
+	minLevel  string
+	initErr   error
+}
+
+func (u *UnixSink) init() error {
+	u.once.Do(func() {
+		if u.Path == "" {
+			u.initErr = fmt.Errorf("Path is required")
+			return
+		}
+		if u.MinLevel != "" {
+			if u.minLevel, u.initErr = parseLevel(u.MinLevel); u.initErr != nil {
+				return
+			}
+		}
+		u.conn = netConn{network: "unix", addr: u.Path}
+		u.batcher, u.initErr = newBatcher(u.BatchSize, u.Interval, u.Retry, u.Breaker, u.Queue, u.send)
+	})
+	return u.initErr
+}
+
+func (u *UnixSink) reportErrors(report func(error)) {
+	if u.init() == nil {
+		u.batcher.setReport(report)
+	}
+}
+
+func (u *UnixSink) Write(entry Entry) error {
+	if err := u.init(); err != nil {
+		return err
+	}
+	if u.minLevel != "" && levelRank[entry.Level] < levelRank[u.minLevel] {
+		return nil
+	}
+	u.batcher.add(entry)
+	return nil
+}
+
+func (u *UnixSink) Flush() {
+	if u.init() == nil {
+		u.batcher.Flush()
+	}
+}
+
+func (u *UnixSink) Close() {
+	if u.init() == nil {
+		u.batcher.Close()
+		u.conn.close()
+	}
+}
+
+func (u *UnixSink) undelivered() int {
+	if u.init() != nil {
+		return 0
+	}
+	return u.batcher.undelivered()
+}
+
+func (u *UnixSink) queued() int {
+	if u.init() != nil {
+		return 0
+	}
+	return u.batcher.queued()
+}
+
+func (u *UnixSink) send(entries []Entry) error {
+	var buf []byte
+	for _, entry := range entries {
+		var err error
+		if buf, err = appendFrame(buf, entry); err != nil {
+			return fmt.Errorf("Failed to encode entry for %s: %w", u.Path, err)
+		}
+	}
+	// * the collector may have restarted, retry once on a fresh connection
+	err := u.conn.write(buf, 10*time.Second)
+	if err != nil {
+		err = u.conn.write(buf, 10*time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to send to %s: %w", u.Path, err)
+	}
+	return nil
+}
+
+// 接收 UnixSink 或 NetworkSink（Framing "length"）送出的日誌
+type SocketListener struct {
+	listener net.Listener
+	handle   func(Entry)
+	mutex    sync.Mutex
+	conns    map[net.Conn]bool
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// * a stale socket file left by a crashed collector is replaced
+func ListenUnix(path string, handle func(Entry)) (*SocketListener, error) {
+	if handle == nil {
+		return nil, fmt.Errorf("Failed to listen on %s: handler is nil", path)
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("Failed to listen on %s: already in use", path)
+		}
+		os.Remove(path)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen on %s: %w", path, err)
+	}
+	return serveFrames(listener, handle), nil
+}
+
+// * e.g. ListenTCP(":5170", nil, aggregator.Ingest) for NetworkSink with Framing "length",
+// * with a tls.Config using ClientAuth for mutual TLS
+func ListenTCP(addr string, config *tls.Config, handle func(Entry)) (*SocketListener, error) {
+	if handle == nil {
+		return nil, fmt.Errorf("Failed to listen on %s: handler is nil", addr)
+	}
+	var listener net.Listener
+	var err error
+	if config != nil {
+		listener, err = tls.Listen("tcp", addr, config)
+	} else {
+		listener, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen on %s: %w", addr, err)
+	}
+	return serveFrames(listener, handle), nil
+}
+
+func serveFrames(listener net.Listener, handle func(Entry)) *SocketListener {
+	s := &SocketListener{listener: listener, handle: handle, conns: make(map[net.Conn]bool)}
+	s.wg.Add(1)
+	go s.accept()
+	return s
+}
+
+func (s *SocketListener) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *SocketListener) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.wg.Add(1)
+		s.mutex.Unlock()
+
+		go s.serve(conn)
+	}
+}
+
+// * a malformed frame ends the connection, the sender reconnects
+func (s *SocketListener) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		entry, err := readFrame(reader)
+		if err != nil {
+			return
+		}
+		s.handle(entry)
+	}
+}
+
+// * stops accepting, drops open connections and waits for handlers to return
+func (s *SocketListener) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
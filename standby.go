@@ -0,0 +1,74 @@
+package goLogger
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func (l *Logger) standbyPath(filename string) string {
+	return filepath.Join(l.config.Path, filepath.Dir(filename), "."+filepath.Base(l.diskName(filename))+".next")
+}
+
+// * pre-open the next file so rotation is only a rename and pointer swap
+func (l *Logger) prepareStandby(filename string) {
+	if l.config.DatedFiles {
+		// * next dated name isn't known ahead of time
+		return
+	}
+	if l.config.SharedPath {
+		// * every process would share and rename the same .next file
+		return
+	}
+	if _, isExist := l.standby[filename]; isExist {
+		return
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !l.config.AppendOnly {
+		flags |= os.O_TRUNC
+	}
+	file, err := l.fs().OpenFile(l.standbyPath(filename), flags, fileMode(l.config))
+	if err != nil {
+		// * rotation falls back to opening a new file
+		return
+	}
+	if err := l.applyOwnership(l.standbyPath(filename)); err != nil {
+		file.Close()
+		return
+	}
+	l.standby[filename] = file
+}
+
+func (l *Logger) takeStandby(filename string) File {
+	file, isExist := l.standby[filename]
+	if !isExist {
+		return nil
+	}
+	delete(l.standby, filename)
+
+	if err := l.fs().Rename(l.standbyPath(filename), filepath.Join(l.config.Path, l.diskName(filename))); err != nil {
+		file.Close()
+		l.fs().Remove(l.standbyPath(filename))
+		return nil
+	}
+	return file
+}
+
+func (l *Logger) refillStandby(filename string) {
+	l.lock()
+	defer l.unlock()
+
+	if _, isOpen := l.File[filename]; l.IsClose || !isOpen {
+		// * closed meanwhile, e.g. an idle tenant
+		return
+	}
+	l.prepareStandby(filename)
+}
+
+func (l *Logger) closeStandby() {
+	for filename, file := range l.standby {
+		file.Close()
+		l.fs().Remove(l.standbyPath(filename))
+	}
+	l.standby = nil
+}
@@ -0,0 +1,22 @@
+package goLogger
+
+func (l *Logger) Stats() Stats {
+	l.Mutex.RLock()
+	defer l.Mutex.RUnlock()
+
+	stats := l.stats
+	stats.Entries = make(map[string]uint64, len(l.stats.Entries))
+	for level, count := range l.stats.Entries {
+		stats.Entries[level] = count
+	}
+	stats.Bytes = make(map[string]uint64, len(l.stats.Bytes))
+	for name, size := range l.stats.Bytes {
+		stats.Bytes[name] = size
+	}
+	for _, sink := range l.sinks {
+		if counter, ok := sink.(queueCounter); ok {
+			stats.QueueDepth += counter.queued()
+		}
+	}
+	return stats
+}
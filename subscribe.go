@@ -0,0 +1,79 @@
+package goLogger
+
+import "fmt"
+
+const subscriberBuffer = 256
+
+type subscriber struct {
+	ch     chan Entry
+	levels map[string]bool
+}
+
+// * entries are delivered after they are written, an unknown level yields a closed channel
+func (l *Logger) Subscribe(levels ...string) (<-chan Entry, func()) {
+	ch, cancel, err := l.subscribe(levels...)
+	if err != nil {
+		l.internalError(fmt.Errorf("Failed to subscribe: %w", err))
+		ch = make(chan Entry)
+		close(ch)
+		return ch, func() {}
+	}
+	return ch, cancel
+}
+
+func (l *Logger) subscribe(levels ...string) (chan Entry, func(), error) {
+	sub := &subscriber{ch: make(chan Entry, subscriberBuffer)}
+	if len(levels) > 0 {
+		sub.levels = make(map[string]bool, len(levels))
+		for _, level := range levels {
+			parsed, err := parseLevel(level)
+			if err != nil {
+				return nil, nil, err
+			}
+			sub.levels[parsed] = true
+		}
+	}
+
+	l.lock()
+	defer l.unlock()
+
+	if l.IsClose {
+		close(sub.ch)
+		return sub.ch, func() {}, nil
+	}
+	l.subscribers = append(l.subscribers, sub)
+
+	cancel := func() {
+		l.lock()
+		defer l.unlock()
+
+		for i, item := range l.subscribers {
+			if item == sub {
+				l.subscribers = append(l.subscribers[:i], l.subscribers[i+1:]...)
+				close(sub.ch)
+				return
+			}
+		}
+	}
+	return sub.ch, cancel, nil
+}
+
+// * called under lock, a slow subscriber loses entries instead of blocking writes
+func (l *Logger) publish(entry Entry) {
+	for _, sub := range l.subscribers {
+		if sub.levels != nil && !sub.levels[entry.Level] {
+			continue
+		}
+		select {
+		case sub.ch <- entry:
+		default:
+		}
+	}
+}
+
+func (l *Logger) closeSubscribers() {
+	for _, sub := range l.subscribers {
+		close(sub.ch)
+	}
+	l.subscribers = nil
+}
@@ -0,0 +1,52 @@
+package goLogger
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// * no dots, the instance sits between the name and .log
+var instancePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// * "pid" and "hostname" are resolved once on start, anything else is used as is
+func resolveInstance(instance string) (string, error) {
+	switch instance {
+	case "":
+		return "", nil
+	case "pid":
+		return strconv.Itoa(os.Getpid()), nil
+	case "hostname":
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", fmt.Errorf("Failed to resolve instance: %w", err)
+		}
+		// * the short name, e.g. the pod name on Kubernetes
+		instance, _, _ = strings.Cut(hostname, ".")
+	}
+	if !instancePattern.MatchString(instance) {
+		return "", fmt.Errorf("Failed to resolve instance: %q has characters other than letters, digits, _ and -", instance)
+	}
+	return instance, nil
+}
+
+// * output.log -> output.12345.log, tenant files keep their directory
+func (l *Logger) diskName(filename string) string {
+	if l.instance == "" {
+		return filename
+	}
+	return strings.TrimSuffix(filename, ".log") + "." + l.instance + ".log"
+}
+
+// * reverse of diskName, for code working from paths
+func (l *Logger) logicalName(name string) string {
+	if l.instance == "" {
+		return name
+	}
+	if base, isExist := strings.CutSuffix(name, "."+l.instance+".log"); isExist {
+		return base + ".log"
+	}
+	return name
+}
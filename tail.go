@@ -0,0 +1,68 @@
+package goLogger
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// * server-sent events, e.g. curl -N "host/tail?level=ERROR,WARNING"
+func (l *Logger) TailHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		var levels []string
+		if value := r.URL.Query().Get("level"); value != "" {
+			levels = strings.Split(value, ",")
+		}
+		entries, cancel, err := l.subscribe(levels...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case entry, ok := <-entries:
+				if !ok {
+					return
+				}
+				if _, err := w.Write(l.encodeEvent(&entry)); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+func (l *Logger) encodeEvent(entry *Entry) []byte {
+	l.Mutex.RLock()
+	data := l.encode(entry, l.config.Type)
+	l.Mutex.RUnlock()
+
+	var buf bytes.Buffer
+	buf.WriteString("event: ")
+	buf.WriteString(entry.Level)
+	buf.WriteByte('\n')
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		buf.WriteString("data: ")
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
@@ -0,0 +1,171 @@
+package goLogger
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// * values become directory names, anything else stays in the shared files
+var tenantPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+const (
+	defaultMaxTenants = 100
+	// * distinct invalid values reported, a client sending garbage can't flood the callback
+	maxReportedTenants = 100
+)
+
+func (l *Logger) tenantOf(fields []Field) string {
+	if l.config.TenantField == "" {
+		return ""
+	}
+	for _, field := range fields {
+		if field.Key != l.config.TenantField {
+			continue
+		}
+		tenant := fmt.Sprintf("%v", field.Value)
+		if !tenantPattern.MatchString(tenant) {
+			if !l.badTenants[tenant] && len(l.badTenants) < maxReportedTenants {
+				// * once per value, later entries with it go to the shared files quietly
+				l.badTenants[tenant] = true
+				l.internalError(fmt.Errorf("Failed to partition: invalid tenant %q", tenant))
+			}
+			return ""
+		}
+		return tenant
+	}
+	return ""
+}
+
+// * called under lock, a tenant's files are opened on its first entry
+func (l *Logger) tenantFile(tenant string, filename string) string {
+	if _, isOpen := l.tenants[tenant]; !isOpen {
+		if err := l.openTenant(tenant); err != nil {
+			l.internalError(err)
+			return filename
+		}
+	}
+	l.tenantUse++
+	l.tenants[tenant] = l.tenantUse
+	return filepath.Join(tenant, filename)
+}
+
+func (l *Logger) maxTenants() int {
+	if l.config.MaxTenants > 0 {
+		return l.config.MaxTenants
+	}
+	return defaultMaxTenants
+}
+
+// * at MaxTenants the least recently written tenants give up their files first
+func (l *Logger) openTenant(tenant string) error {
+	if len(l.tenants) >= l.maxTenants() {
+		for len(l.tenants) >= l.maxTenants() {
+			l.closeTenant(l.idleTenant())
+		}
+		if err := l.initHandler(); err != nil {
+			return err
+		}
+	}
+
+	if err := l.fs().MkdirAll(filepath.Join(l.config.Path, tenant), dirMode(l.config)); err != nil {
+		return fmt.Errorf("Failed to create tenant %s: %w", tenant, err)
+	}
+	if err := applyMode(l.fs(), filepath.Join(l.config.Path, tenant), l.config.DirMode); err != nil {
+		return err
+	}
+	if err := l.applyOwnership(filepath.Join(l.config.Path, tenant)); err != nil {
+		return err
+	}
+
+	var opened []string
+	for _, name := range l.baseNames() {
+		filename := filepath.Join(tenant, name)
+		if err := l.openLive(filename, fileMode(l.config)); err != nil {
+			for _, filename := range opened {
+				l.File[filename].Close()
+				delete(l.File, filename)
+			}
+			return err
+		}
+		opened = append(opened, filename)
+	}
+
+	l.tenants[tenant] = l.tenantUse
+	return l.initHandler()
+}
+
+func (l *Logger) idleTenant() string {
+	var idle string
+	for tenant, used := range l.tenants {
+		if idle == "" || used < l.tenants[idle] {
+			idle = tenant
+		}
+	}
+	return idle
+}
+
+// * called under lock, the files are reopened on the tenant's next entry;
+// * the handlers still need initHandler
+func (l *Logger) closeTenant(tenant string) {
+	for _, name := range l.baseNames() {
+		filename := filepath.Join(tenant, name)
+		if file, isExist := l.File[filename]; isExist {
+			if err := file.Close(); err != nil {
+				l.internalError(fmt.Errorf("Failed to close %s: %w", filename, err))
+			}
+			delete(l.File, filename)
+		}
+		if file, isExist := l.standby[filename]; isExist {
+			file.Close()
+			l.fs().Remove(l.standbyPath(filename))
+			delete(l.standby, filename)
+		}
+		delete(l.chain, filename)
+		delete(l.sizes, filename)
+		delete(l.dated, filename)
+	}
+	delete(l.tenants, tenant)
+}
+
+func (l *Logger) tenantNames() []string {
+	names := make([]string, 0, len(l.tenants))
+	for tenant := range l.tenants {
+		names = append(names, tenant)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// * tenants with open files, those idle longest are closed beyond MaxTenants
+func (l *Logger) Tenants() []string {
+	l.Mutex.RLock()
+	defer l.Mutex.RUnlock()
+
+	return l.tenantNames()
+}
+
+// * entries of the returned logger carry the tenant field and land in its directory
+func (l *Logger) Tenant(tenant string) *Child {
+	return (&Child{logger: l}).Tenant(tenant)
+}
+
+func (c *Child) Tenant(tenant string) *Child {
+	c.logger.Mutex.RLock()
+	key := c.logger.config.TenantField
+	c.logger.Mutex.RUnlock()
+	if key == "" {
+		key = "tenant"
+	}
+
+	fields := make([]Field, 0, len(c.fields)+1)
+	for _, field := range c.fields {
+		if field.Key != key {
+			fields = append(fields, field)
+		}
+	}
+	child := *c
+	child.fields = append(fields, Field{Key: key, Value: tenant})
+	return &child
+}
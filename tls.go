@@ -0,0 +1,43 @@
+package goLogger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// 以 PEM 檔案路徑設定 TLS，CertFile 與 KeyFile 同時設定時啟用雙向驗證（mTLS）
+type TLSFiles struct {
+	CAFile     string `json:"ca_file,omitempty"`     // 驗證伺服器憑證的 CA，預設使用系統憑證
+	CertFile   string `json:"cert_file,omitempty"`   // 用戶端憑證
+	KeyFile    string `json:"key_file,omitempty"`    // 用戶端私鑰
+	ServerName string `json:"server_name,omitempty"` // 驗證憑證時使用的主機名稱，預設取自連線位址
+}
+
+// * the result can be shared by several sinks
+func (f TLSFiles) Config() (*tls.Config, error) {
+	config := &tls.Config{ServerName: f.ServerName, MinVersion: tls.VersionTLS12}
+	if f.CAFile != "" {
+		data, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("Failed to parse CA file %s: no certificates found", f.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if (f.CertFile == "") != (f.KeyFile == "") {
+		return nil, fmt.Errorf("CertFile and KeyFile must be set together")
+	}
+	if f.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
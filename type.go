@@ -1,6 +1,7 @@
 package goLogger
 
 import (
+	"io"
 	"log"
 	"os"
 	"sync"
@@ -22,26 +23,201 @@ const (
 )
 
 type Log struct {
-	Path      string `json:"path,omitempty"`        // 日誌檔案路徑，預設 `./logs`
-	Stdout    bool   `json:"stdout,omitempty"`      // 是否輸出到標準輸出，預設 false
-	MaxSize   int64  `json:"max_size,omitempty"`    // 日誌檔案最大大小（位元組），預設 16 * 1024 * 1024
-	MaxBackup int    `json:"max_backups,omitempty"` // 新增：最大備份檔案數量，預設 5
-	Type      string `json:"type,omitempty"`        // 日誌類型，預設 "text"，可選 "json" 或 "text"
+	Path            string            `json:"path,omitempty"`              // 日誌檔案路徑，預設 `./logs`
+	Stdout          bool              `json:"stdout,omitempty"`            // 是否輸出到標準輸出，預設 false
+	MaxSize         int64             `json:"max_size,omitempty"`          // 日誌檔案最大大小（位元組），預設 16 * 1024 * 1024
+	MaxBackup       int               `json:"max_backups,omitempty"`       // 新增：最大備份檔案數量，預設 5
+	Type            string            `json:"type,omitempty"`              // 日誌類型，預設 "text"，可選 "json" 或 "text"
+	MaxEntrySize    int               `json:"max_entry_size,omitempty"`    // 單筆日誌最大大小（位元組），超過時截斷，預設 0 不限制
+	Level           string            `json:"level,omitempty"`             // 最低輸出層級，預設 "DEBUG"
+	FileLineLimit   int               `json:"file_line_limit,omitempty"`   // 檔案單行長度上限（位元組），預設 0 不限制
+	StdoutLineLimit int               `json:"stdout_line_limit,omitempty"` // 標準輸出單行長度上限（位元組），如 journald/docker 的 16KB，預設 0 不限制
+	Filters         []FilterRule      `json:"filters,omitempty"`           // 寫入前套用的過濾規則
+	Redact          []RedactRule      `json:"redact,omitempty"`            // 寫入前遮蔽敏感資料的規則
+	MaskKeys        []string          `json:"mask_keys,omitempty"`         // 值會被替換為 "***" 的欄位名稱（不分大小寫），如 "password"
+	HMACKey         string            `json:"hmac_key,omitempty"`          // 設定後每筆日誌附加 HMAC-SHA256 簽章，可用 Verify 驗證
+	Audit           bool              `json:"audit,omitempty"`             // 稽核模式，每筆日誌附加與前一筆串連的雜湊，可用 VerifyChain 驗證
+	Checksum        bool              `json:"checksum,omitempty"`          // 輪替時於備份旁寫入 SHA-256 校驗檔（.sha256）
+	MaxAge          time.Duration     `json:"max_age,omitempty"`           // 備份保留時間，超過即刪除（AppendOnly 時改為觸發 OnExpire），預設 0 不限制
+	AppendOnly      bool              `json:"append_only,omitempty"`       // 合規（WORM）模式，只追加與輪替，不刪除、不截斷任何紀錄
+	StrictEncoding  bool              `json:"strict_encoding,omitempty"`   // JSON 欄位無法編碼時捨棄該筆並回傳錯誤，預設 false 以佔位文字取代
+	Archiver        Archiver          `json:"-"`                           // 輪替後上傳備份的物件儲存，如 S3Archiver
+	DeleteArchived  bool              `json:"delete_archived,omitempty"`   // 上傳成功後刪除本機備份（AppendOnly 時無效）
+	ReopenOnSIGHUP  bool              `json:"reopen_on_sighup,omitempty"`  // 收到 SIGHUP 時重新開啟檔案，搭配外部 logrotate 使用
+	BackupFormat    string            `json:"backup_format,omitempty"`     // 備份檔名格式："timestamp"（預設）、"millis"、"sequence" 或自訂時間 layout
+	DatedFiles      bool              `json:"dated_files,omitempty"`       // 使用日期檔名（output-2025-06-01.log），並以 output.log 符號連結指向目前檔案
+	MaxTotalSize    int64             `json:"max_total_size,omitempty"`    // 日誌目錄總大小上限（位元組），於輪替時與每小時檢查，超過時由最舊備份開始刪除，預設 0 不限制
+	Fallback        io.Writer         `json:"-"`                           // 檔案寫入失敗時的備援輸出，預設 os.Stderr
+	Expvar          string            `json:"expvar,omitempty"`            // 以此前綴發佈統計至 expvar，如 "goLogger"，預設不發佈
+	RecentSize      int               `json:"recent_size,omitempty"`       // 記憶體中保留的最近日誌筆數，供 Recent 查詢，預設 0 不保留
+	Color           bool              `json:"color,omitempty"`             // 標準輸出依層級上色（僅 text 格式），預設 false
+	Caller          bool              `json:"caller,omitempty"`            // 附加呼叫位置欄位 caller（file.go:42），預設 false
+	Sampling        *Sampling         `json:"sampling,omitempty"`          // 取樣設定，同一訊息在週期內超過 Initial 筆後每 Thereafter 筆保留一筆
+	Clock           Clock             `json:"-"`                           // 時間來源，用於日誌時間與備份檔名，預設 time.Now
+	FS              FS                `json:"-"`                           // 檔案系統，預設作業系統，測試可使用 NewMemFS()
+	Levels          map[string]string `json:"levels,omitempty"`            // 具名 logger 的層級，依名稱前綴套用，如 {"http": "DEBUG"}，未設定者使用 Level
+	Routes          []Route           `json:"routes,omitempty"`            // 依 logger 名稱前綴寫入獨立檔案，如 db → db.log，可個別設定輪替
+	TenantField     string            `json:"tenant_field,omitempty"`      // 依此欄位值將日誌寫入 <Path>/<值>/ 子目錄，各租戶獨立輪替與保留，MaxTotalSize 為所有租戶共用，如 "tenant_id"
+	Verbosity       int               `json:"verbosity,omitempty"`         // glog 風格詳細程度，V(n) 於 n <= Verbosity 時輸出，預設 0
+	Fingerprint     bool              `json:"fingerprint,omitempty"`       // ERROR 以上附加 fingerprint 欄位（正規化訊息與呼叫函式的雜湊），供分組與去重，預設 false
+	Alerts          []AlertRule       `json:"alerts,omitempty"`            // 門檻告警規則，如 60 秒內 10 筆 ERROR 時呼叫 Callback 或 Webhook 一次
+	Sinks           []Sink            `json:"-"`                           // 日誌檔案以外的輸出目的地，如 SMTPSink，於 Close 時一併關閉
+	SinkConfigs     []SinkConfig      `json:"sinks,omitempty"`             // 以名稱引用的輸出，如 {"name": "loki", "options": {...}}，與 Sinks 一併使用
+	CloseTimeout    time.Duration     `json:"close_timeout,omitempty"`     // Close 等待輸出送出剩餘日誌的上限，預設 0 等待至完成
+	FlushInterval   time.Duration     `json:"flush_interval,omitempty"`    // 定期將日誌檔案同步至磁碟（fsync）的間隔，限制當機時遺失的範圍，預設 0 不定期同步
+	SyncPolicy      string            `json:"sync_policy,omitempty"`       // 同步至磁碟的時機："never"（僅系統快取）、"interval"（依 FlushInterval）、"error"（ERROR 以上每筆）或 "always"（每筆），預設依 FlushInterval
+	DirMode         os.FileMode       `json:"dir_mode,omitempty"`          // 建立日誌目錄的權限，如 0700，受 umask 影響，預設 0755
+	FileMode        os.FileMode       `json:"file_mode,omitempty"`         // 建立日誌檔案的權限，如 0600，受 umask 影響，預設 0644
+	Owner           string            `json:"owner,omitempty"`             // 目錄與檔案的擁有者（名稱或 uid），以 root 啟動後降權時使用，預設不變更
+	Group           string            `json:"group,omitempty"`             // 目錄與檔案的群組（名稱或 gid），預設不變更
+	SharedPath      bool              `json:"shared_path,omitempty"`       // 多個程序寫入同一 Path，輪替時以檔案鎖（flock）互斥並略過預備檔案，預設 false
+	Instance        string            `json:"instance,omitempty"`          // 加入檔名的實例識別，"pid"、"hostname" 或自訂值，如 output.12345.log，預設不加入
+	WatchInterval   time.Duration     `json:"watch_interval,omitempty"`    // 定期檢查日誌檔案是否被外部刪除、取代或截斷，刪除或取代時重新建立，預設 0 僅於寫入失敗時檢查
+	CrashDump       bool              `json:"crash_dump,omitempty"`        // FATAL 或 panic 時另寫崩潰報告 crash-<時間>.txt，含訊息、所有 goroutine 堆疊與 Recent 保留的日誌，預設 false
+	BuildFields     bool              `json:"build_fields,omitempty"`      // 每筆日誌附加建置資訊欄位 version 與 revision（runtime/debug.ReadBuildInfo），預設 false
+	Sequence        bool              `json:"sequence,omitempty"`          // 每筆日誌附加遞增序號欄位 seq，供下游偵測遺失並排序同一時間的日誌，隨程序重新開始，預設 false
+	EntryID         bool              `json:"entry_id,omitempty"`          // 每筆日誌附加隨機 UUID 欄位 entry_id，供工單與告警引用及重送後去重，預設 false
+	TimePrecision   string            `json:"time_precision,omitempty"`    // 時間戳記精度："second"、"millisecond"、"microsecond" 或 "nanosecond"，兩種格式皆以固定位數輸出，預設 text 為微秒、json 為毫秒
+	MaxTenants      int               `json:"max_tenants,omitempty"`       // 同時開啟檔案的租戶上限，超過時關閉最久未寫入者的檔案，下次寫入時重新開啟，預設 100
+}
+
+// * DEBUG is the most verbose, TRACE sits between it and INFO; unlike slog or zap,
+// * Level "TRACE" therefore hides DEBUG entries
+var levelRank = map[string]int{
+	logDebug:    0,
+	logTrace:    1,
+	logInfo:     2,
+	logNotice:   3,
+	logWarning:  4,
+	logError:    5,
+	logFatal:    6,
+	logCritical: 7,
+}
+
+type FilterRule struct {
+	Match     string `json:"match,omitempty"`     // 訊息符合此正規表示式時套用
+	Prefix    string `json:"prefix,omitempty"`    // 訊息以此開頭時套用
+	Component string `json:"component,omitempty"` // component 或 logger 欄位等於此值時套用
+	MinLevel  string `json:"min_level,omitempty"` // 符合時僅保留此層級以上，空值代表全部捨棄
+}
+
+type Route struct {
+	Prefix    string `json:"prefix"`                // logger 名稱前綴，http 同時符合 http.client；無名稱時比對 component 欄位
+	File      string `json:"file"`                  // 寫入的檔案名稱，如 "db.log"
+	MaxSize   int64  `json:"max_size,omitempty"`    // 此檔案的最大大小（位元組），預設沿用 MaxSize
+	MaxBackup int    `json:"max_backups,omitempty"` // 此檔案的最大備份數量，預設沿用 MaxBackup
+}
+
+type Sampling struct {
+	Initial    int           `json:"initial,omitempty"`    // 每個週期內同一層級與訊息完整保留的筆數
+	Thereafter int           `json:"thereafter,omitempty"` // 超過 Initial 後每 N 筆保留一筆，0 代表全部捨棄
+	Tick       time.Duration `json:"tick,omitempty"`       // 計數週期，預設 1 秒
+}
+
+type RedactRule struct {
+	Name    string `json:"name,omitempty"`    // 內建規則名稱："email"、"credit_card"、"bearer"，Pattern 為空時使用
+	Pattern string `json:"pattern,omitempty"` // 自訂正規表示式
+	Replace string `json:"replace,omitempty"` // 取代文字，預設 "[REDACTED]"
+}
+
+var levelFile = map[string]string{
+	logDebug:    defaultDebugName,
+	logTrace:    defaultDebugName,
+	logInfo:     defaultOutputName,
+	logNotice:   defaultOutputName,
+	logWarning:  defaultOutputName,
+	logError:    defaultErrorName,
+	logFatal:    defaultErrorName,
+	logCritical: defaultErrorName,
 }
 
 type Logger struct {
-	Config        *Log
-	DebugHandler  *log.Logger
-	OutputHandler *log.Logger
-	ErrorHandler  *log.Logger
-	File          map[string]*os.File
-	Mutex         sync.RWMutex
-	IsClose       bool
-	timer         *time.Timer
-	stopTimer     chan struct{}
+	config          *Log
+	DebugHandler    *log.Logger
+	OutputHandler   *log.Logger
+	ErrorHandler    *log.Logger
+	File            map[string]File
+	standby         map[string]File
+	chain           map[string]string
+	dated           map[string]string
+	expired         map[string]bool
+	onExpire        []func(path string)
+	onRotate        []func(oldPath, newPath string)
+	archiving       sync.WaitGroup
+	stats           Stats
+	errorMutex      sync.Mutex
+	locked          bool
+	pending         []func()
+	onInternalError []func(error)
+	Mutex           sync.RWMutex
+	IsClose         bool
+	timer           *time.Timer
+	stopTimer       chan struct{}
+	owner           ownership
+	rotationLocked  bool
+	sizes           map[string]int64
+	instance        string
+	sequence        uint64
+	elevation       *elevation
+	hooks           []Hook
+	filters         []filter
+	redactors       []redactor
+	maskKeys        map[string]bool
+	onWrite         []func(Entry, error)
+	subscribers     []*subscriber
+	recent          []Entry
+	recentNext      int
+	samples         map[string]int
+	sampleStart     time.Time
+	levels          map[string]string
+	routes          []Route
+	handlers        map[string]*log.Logger
+	handlerNames    map[*log.Logger]string
+	tenants         map[string]uint64
+	alerts          []*alert
+	alerting        sync.WaitGroup
+	sinks           []Sink
+	configSinks     []Sink
+	retiring        sync.WaitGroup
+	reconfiguring   sync.Mutex
+	tenantUse       uint64
+	badTenants      map[string]bool
+}
+
+type Stats struct {
+	Entries      map[string]uint64 `json:"entries"`       // 各層級已寫入筆數
+	Bytes        map[string]uint64 `json:"bytes"`         // 各檔案已寫入位元組
+	Suppressed   uint64            `json:"suppressed"`    // 低於最低層級而略過的筆數
+	Dropped      uint64            `json:"dropped"`       // 被 hook、過濾規則、取樣或嚴格編碼捨棄的筆數
+	Failed       uint64            `json:"failed"`        // 寫入失敗筆數
+	LastRotation time.Time         `json:"last_rotation"` // 最近一次輪替時間
+	QueueDepth   int               `json:"queue_depth"`   // 遠端輸出待送筆數，含磁碟佇列
+}
+
+type Entry struct {
+	Time    time.Time // 寫入時間
+	Level   string    // 日誌層級
+	Message string    // 主要訊息
+	Data    []string  // 其餘訊息，text 為樹狀分支，json 為 msg1、msg2...
+	Fields  []Field   // 結構化欄位
+}
+
+type Field struct {
+	Key   string
+	Value any
+}
+
+// 回傳 nil 代表捨棄該筆日誌
+type Hook func(*Entry) *Entry
+
+type elevation struct {
+	previous string
+	active   map[int]string
+	next     int
 }
 
 type backupFile struct {
 	path    string
+	base    string
 	modTime time.Time
 }
@@ -0,0 +1,218 @@
+package goLogger
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// * zero values are valid and mean defaults, every problem is reported at once;
+// * nothing is started, settings of Sinks are checked by New when it starts them
+func (c *Log) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("Invalid config: "+format, args...))
+	}
+
+	for _, limit := range []struct {
+		name  string
+		value int64
+	}{
+		{"max_size", c.MaxSize},
+		{"max_backups", int64(c.MaxBackup)},
+		{"max_entry_size", int64(c.MaxEntrySize)},
+		{"file_line_limit", int64(c.FileLineLimit)},
+		{"stdout_line_limit", int64(c.StdoutLineLimit)},
+		{"max_age", int64(c.MaxAge)},
+		{"max_total_size", c.MaxTotalSize},
+		{"recent_size", int64(c.RecentSize)},
+		{"verbosity", int64(c.Verbosity)},
+		{"flush_interval", int64(c.FlushInterval)},
+		{"watch_interval", int64(c.WatchInterval)},
+		{"max_tenants", int64(c.MaxTenants)},
+	} {
+		if limit.value < 0 {
+			invalid("%s must not be negative, got %d", limit.name, limit.value)
+		}
+	}
+
+	switch c.Type {
+	case "", "text", "json":
+	default:
+		invalid(`type must be "text" or "json", got %q`, c.Type)
+	}
+
+	if _, ok := timePrecisions[c.TimePrecision]; c.TimePrecision != "" && !ok {
+		invalid(`time_precision must be "second", "millisecond", "microsecond" or "nanosecond", got %q`, c.TimePrecision)
+	}
+
+	switch c.SyncPolicy {
+	case "", syncNever, syncInterval, syncError, syncAlways:
+	default:
+		invalid(`sync_policy must be "never", "interval", "error" or "always", got %q`, c.SyncPolicy)
+	}
+	if c.SyncPolicy == syncInterval && c.FlushInterval <= 0 {
+		invalid(`sync_policy "interval" needs flush_interval`)
+	}
+	if c.SyncPolicy == syncNever && c.FlushInterval > 0 {
+		invalid(`flush_interval conflicts with sync_policy "never", which leaves syncing to the OS`)
+	}
+	switch {
+	case c.DirMode&^os.ModePerm != 0:
+		invalid("dir_mode %#o must only hold permission bits", uint32(c.DirMode))
+	case c.DirMode != 0 && c.DirMode&0700 != 0700:
+		invalid("dir_mode %#o must keep rwx for the owner", uint32(c.DirMode))
+	}
+	switch {
+	case c.FileMode&^os.ModePerm != 0:
+		invalid("file_mode %#o must only hold permission bits", uint32(c.FileMode))
+	case c.FileMode != 0 && c.FileMode&0200 == 0:
+		invalid("file_mode %#o must keep write for the owner", uint32(c.FileMode))
+	}
+	switch c.Instance {
+	case "", "pid", "hostname":
+	default:
+		if !instancePattern.MatchString(c.Instance) {
+			invalid(`instance %q must be "pid", "hostname" or letters, digits, _ and -`, c.Instance)
+		}
+	}
+	if c.Level != "" {
+		if _, err := parseLevel(c.Level); err != nil {
+			invalid("level %q is unknown, use DEBUG, TRACE, INFO, NOTICE, WARNING, ERROR, FATAL or CRITICAL", c.Level)
+		}
+	}
+	for name, level := range c.Levels {
+		if _, err := parseLevel(level); err != nil {
+			invalid("level %q of logger %q is unknown", level, name)
+		}
+	}
+	files := map[string]bool{defaultDebugName: true, defaultOutputName: true, defaultErrorName: true}
+	for i, route := range compileRoutes(c.Routes) {
+		switch {
+		case route.Prefix == "":
+			invalid("route %d needs a prefix", i)
+		case route.File == ".log" || route.File != filepath.Base(route.File) || strings.HasPrefix(route.File, "."):
+			invalid("route %d file %q must be a plain file name", i, c.Routes[i].File)
+		case files[route.File]:
+			invalid("route %d file %q is already in use", i, route.File)
+		}
+		if route.MaxSize < 0 || route.MaxBackup < 0 {
+			invalid("route %d limits must not be negative", i)
+		}
+		files[route.File] = true
+	}
+	for i, rule := range c.Alerts {
+		switch {
+		case rule.Count < 1:
+			invalid("alert %d count must be at least 1, got %d", i, rule.Count)
+		case rule.Window < 0 || rule.Cooldown < 0:
+			invalid("alert %d window and cooldown must not be negative", i)
+		case rule.Webhook == "" && rule.Callback == nil:
+			invalid("alert %d needs a webhook or a callback", i)
+		}
+		if rule.Level != "" {
+			if _, err := parseLevel(rule.Level); err != nil {
+				invalid("alert %d level %q is unknown", i, rule.Level)
+			}
+		}
+		if rule.Match != "" {
+			if _, err := regexp.Compile(rule.Match); err != nil {
+				invalid("alert %d match %q does not compile", i, rule.Match)
+			}
+		}
+		if rule.Webhook != "" {
+			if target, err := url.Parse(rule.Webhook); err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+				invalid("alert %d webhook %q must be an http or https URL", i, rule.Webhook)
+			}
+		}
+	}
+	for i, sink := range c.Sinks {
+		if sink == nil {
+			invalid("sink %d is nil", i)
+		}
+	}
+	for i, sink := range c.SinkConfigs {
+		if sink.Name == "" {
+			invalid("sink %d has no name", i)
+		} else if sinkFactory(sink.Name) == nil {
+			invalid("sink %q is not registered, use one of %s or RegisterSink", sink.Name, sinkNames())
+		}
+	}
+	if c.Sampling != nil && (c.Sampling.Initial < 0 || c.Sampling.Thereafter < 0 || c.Sampling.Tick < 0) {
+		invalid("sampling values must not be negative, got %+v", *c.Sampling)
+	}
+
+	if c.DeleteArchived && c.Archiver == nil {
+		invalid("delete_archived needs an Archiver, backups would never be removed")
+	}
+	if c.DeleteArchived && c.AppendOnly {
+		invalid("delete_archived conflicts with append_only, which never deletes records")
+	}
+	if c.MaxTotalSize > 0 && c.AppendOnly {
+		invalid("max_total_size conflicts with append_only, which never deletes records")
+	}
+	if c.Color && c.Type == "json" {
+		invalid("color only applies to the text type")
+	}
+	if c.Color && !c.Stdout {
+		invalid("color needs stdout, files are never colored")
+	}
+
+	if c.Archiver != nil && c.FS != nil {
+		if _, isOS := c.FS.(osFS); !isOS {
+			invalid("archiver reads backups from the OS filesystem and can't be combined with a custom FS")
+		}
+	}
+
+	if err := checkWritable(fileSystem(c), c.Path); err != nil {
+		invalid("path %s", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// * an existing directory must accept new files, checked with a probe file removed
+// * right after; a missing one only needs an existing parent directory, nothing is
+// * written outside the configured path and New reports if it can't be created
+func checkWritable(fsys FS, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	dir := path
+	for {
+		info, err := fsys.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%q is not a directory", dir)
+			}
+			break
+		}
+		if !os.IsNotExist(err) && !errors.Is(err, syscall.ENOTDIR) {
+			return fmt.Errorf("%q: %v", dir, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("%q has no existing parent", path)
+		}
+		dir = parent
+	}
+	if dir != path {
+		return nil
+	}
+
+	probe := filepath.Join(dir, fmt.Sprintf(".golog-check-%d", time.Now().UnixNano()))
+	file, err := fsys.OpenFile(probe, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("%q is not writable: %v", dir, err)
+	}
+	file.Close()
+	fsys.Remove(probe)
+	return nil
+}
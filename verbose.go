@@ -0,0 +1,54 @@
+package goLogger
+
+import "fmt"
+
+// * glog-style, V(n) entries are INFO and only written when n <= Verbosity
+type Verbose struct {
+	scope   *Child
+	enabled bool
+}
+
+func (l *Logger) Enabled(level string) bool {
+	return (&Child{logger: l}).Enabled(level)
+}
+
+// * guard for work done only to build a message, per-name levels apply
+func (c *Child) Enabled(level string) bool {
+	level, err := parseLevel(level)
+	if err != nil {
+		return false
+	}
+
+	c.logger.Mutex.RLock()
+	defer c.logger.Mutex.RUnlock()
+
+	return !c.logger.IsClose && c.logger.enabled(c.name, level)
+}
+
+func (l *Logger) V(level int) Verbose {
+	return (&Child{logger: l}).V(level)
+}
+
+func (c *Child) V(level int) Verbose {
+	c.logger.Mutex.RLock()
+	verbosity := c.logger.config.Verbosity
+	c.logger.Mutex.RUnlock()
+
+	return Verbose{scope: c, enabled: level <= verbosity && c.Enabled(logInfo)}
+}
+
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+func (v Verbose) Info(messages ...any) {
+	if v.enabled {
+		v.scope.write(logInfo, defaultOutputName, messages...)
+	}
+}
+
+func (v Verbose) Infof(format string, args ...any) {
+	if v.enabled {
+		v.scope.write(logInfo, defaultOutputName, fmt.Sprintf(format, args...))
+	}
+}
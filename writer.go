@@ -1,150 +1,408 @@
 package goLogger
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"log/slog"
+	"os"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
-func (l *Logger) writeToLog(target *log.Logger, level string, filename string, messages ...any) {
+func (l *Logger) writeToLog(level string, filename string, messages ...any) error {
+	return l.writeFields(filename, level, nil, messages...)
+}
+
+func (l *Logger) writeFields(filename string, level string, fields []Field, messages ...any) error {
+	return l.writeScoped(nil, filename, level, fields, messages...)
+}
+
+// * scope carries the name, fields and component of a Child, nil for the root logger
+func (l *Logger) writeScoped(scope *Child, filename string, level string, fields []Field, messages ...any) error {
+	var name string
+	if scope != nil {
+		name = scope.name
+	}
+
 	level = strings.ToUpper(level)
-	isValid := map[string]bool{
-		logDebug:    true,
-		logTrace:    true,
-		logInfo:     true,
-		logNotice:   true,
-		logWarning:  true,
-		logError:    true,
-		logFatal:    true,
-		logCritical: true,
-	}[level]
-
-	if !isValid {
-		return
+	if _, isValid := levelRank[level]; !isValid {
+		return nil
 	}
 
-	l.Mutex.Lock()
-	defer l.Mutex.Unlock()
+	l.lock()
+	defer l.unlock()
 
+	return l.writeLocked(scope, name, filename, level, fields, messages)
+}
+
+// * called under lock with a valid level
+func (l *Logger) writeLocked(scope *Child, name string, filename string, level string, fields []Field, messages []any) error {
 	if l.IsClose || len(messages) == 0 {
-		return
+		return nil
+	}
+	if !l.enabled(name, level) {
+		l.stats.Suppressed++
+		return nil
+	}
+	if !l.sampled(level, messages[0]) {
+		l.stats.Dropped++
+		return nil
 	}
+	return l.compose(scope, filename, level, fields, l.now(), nil, messages)
+}
 
-	if l.Config.Type == "json" {
-		jsonLogger := slog.New(slog.NewJSONHandler(target.Writer(), &slog.HandlerOptions{
-			Level: slog.LevelDebug, // 確保 DEBUG 層級會被輸出
-		}))
+// * called under lock once the entry passed the level filter and sampling; caller is
+// * the call site recorded earlier, nil to look it up now
+func (l *Logger) compose(scope *Child, filename string, level string, fields []Field, at time.Time, caller *Field, messages []any) error {
+	var name, component string
+	if scope != nil {
+		name, component = scope.name, scope.component
+		// * deep copy, hooks and redaction must not change the Child
+		fields = append(cloneFields(scope.fields), fields...)
+	}
 
-		msg := fmt.Sprintf("%v", messages[0])
-		remaining := messages[1:]
-		attrs := make([]any, len(remaining))
-		for i, m := range remaining {
-			attrs[i] = slog.String(fmt.Sprintf("msg%d", i+1), fmt.Sprintf("%v", m))
+	messages = resolveLazy(messages)
+	if name != "" {
+		fields = append([]Field{{Key: "logger", Value: name}}, fields...)
+	}
+	if errs := messageErrors(messages); len(errs) > 1 && l.config.Type == "json" {
+		// * every cause on its own, msgN only keeps their text in order
+		texts := make([]string, len(errs))
+		for i, err := range errs {
+			texts[i] = err.Error()
 		}
+		fields = append(fields[:len(fields):len(fields)], Field{Key: "errors", Value: texts})
+	}
+	if l.config.Fingerprint && levelRank[level] >= levelRank[logError] {
+		fields = append(fields[:len(fields):len(fields)], fingerprintField(messages[0]))
+	}
+	if l.config.BuildFields {
+		fields = append(fields[:len(fields):len(fields)], buildFields()...)
+	}
+	if component != "" {
+		if l.config.Type == "json" {
+			fields = append(fields[:len(fields):len(fields)], Field{Key: "component", Value: component})
+		} else {
+			// * copy, the caller's slice must not change
+			messages = append([]any{fmt.Sprintf("[%s] %v", component, messages[0])}, messages[1:]...)
+		}
+	}
+	if l.config.Caller {
+		if caller != nil {
+			fields = append(fields[:len(fields):len(fields)], *caller)
+		} else if field, ok := callerField(); ok {
+			fields = append(fields[:len(fields):len(fields)], field)
+		}
+	}
+
+	if name == "" {
+		name = component
+	}
+	filename = l.routeFile(name, fields, filename)
+	if tenant := l.tenantOf(fields); tenant != "" {
+		filename = l.tenantFile(tenant, filename)
+	}
+	if l.config.DatedFiles {
+		l.checkDate(filename)
+	}
+
+	// * resolve under lock, rotation replaces handlers
+	target := l.handler(filename)
+
+	entry := l.newEntry(at, level, fields, messages)
+	err := l.emitEntry(target, entry)
+	if level == logFatal && l.config.CrashDump {
+		// * the entry's fields, with the seq and entry_id the files got
+		l.writeCrash(at, level, entry.Fields, messages)
+	}
+	return err
+}
+
+// * writes an entry received from another process, e.g. through ListenUnix, as if it
+// * was logged here: same level filter, routes, hooks and rotation, its own time kept
+func (l *Logger) Ingest(entry Entry) {
+	level, err := parseLevel(entry.Level)
+	if err != nil {
+		return
+	}
+
+	l.lock()
+	defer l.unlock()
 
-		switch level {
-		case logDebug:
-			jsonLogger.Debug(msg, attrs...)
-		case logTrace:
-			jsonLogger.Info(msg, append(attrs, slog.String("level", "TRACE"))...)
-		case logInfo:
-			jsonLogger.Info(msg, attrs...)
-		case logNotice:
-			jsonLogger.Info(msg, append(attrs, slog.String("level", "NOTICE"))...)
-		case logWarning:
-			jsonLogger.Warn(msg, attrs...)
-		case logError:
-			jsonLogger.Error(msg, attrs...)
-		case logFatal:
-			jsonLogger.Error(msg, append(attrs, slog.String("level", "FATAL"))...)
-		case logCritical:
-			jsonLogger.Error(msg, append(attrs, slog.String("level", "CRITICAL"))...)
+	if l.IsClose {
+		return
+	}
+	var name string
+	for _, field := range entry.Fields {
+		if field.Key == "logger" {
+			name, _ = field.Value.(string)
+			break
 		}
+	}
+	if !l.enabled(name, level) {
+		l.stats.Suppressed++
 		return
 	}
+	texts := truncate(append([]string{entry.Message}, entry.Data...), l.config.MaxEntrySize)
+	entry.Level, entry.Message, entry.Data = level, texts[0], texts[1:]
+
+	filename := l.routeFile(name, entry.Fields, levelFile[level])
+	if tenant := l.tenantOf(entry.Fields); tenant != "" {
+		filename = l.tenantFile(tenant, filename)
+	}
+	if l.config.DatedFiles {
+		l.checkDate(filename)
+	}
+	l.emitEntry(l.handler(filename), &entry)
+}
+
+func (l *Logger) emit(target *log.Logger, level string, fields []Field, messages ...any) error {
+	return l.emitAt(target, l.now(), level, fields, messages...)
+}
+
+func (l *Logger) emitAt(target *log.Logger, at time.Time, level string, fields []Field, messages ...any) error {
+	return l.emitEntry(target, l.newEntry(at, level, fields, messages))
+}
+
+func (l *Logger) newEntry(at time.Time, level string, fields []Field, messages []any) *Entry {
+	texts := truncate(toStrings(messages), l.config.MaxEntrySize)
+	if unit := timePrecisions[l.config.TimePrecision].unit; unit > 0 {
+		// * sinks, subscribers and Recent see the time the files show
+		at = at.Truncate(unit)
+	}
+
+	return &Entry{
+		Time:    at,
+		Level:   level,
+		Message: texts[0],
+		Data:    texts[1:],
+		Fields:  fields,
+	}
+}
+
+// * every entry passes here, notices and ingested ones included
+func (l *Logger) emitEntry(target *log.Logger, entry *Entry) error {
+	l.stamp(entry)
+
+	for _, hook := range l.hooks {
+		if entry = hook(entry); entry == nil {
+			// * dropped by hook
+			l.stats.Dropped++
+			return nil
+		}
+	}
+
+	for i := range l.filters {
+		if l.filters[i].drops(entry) {
+			l.stats.Dropped++
+			return nil
+		}
+	}
 
-	prefix := ""
-	if level != logInfo {
-		prefix = fmt.Sprintf("[%s] ", level)
+	l.redact(entry)
+
+	var encodeErr error
+	if l.config.Type == "json" {
+		encodeErr = checkFields(entry)
+		if encodeErr != nil && l.config.StrictEncoding {
+			l.stats.Dropped++
+			l.notifyWrite(*entry, encodeErr)
+			return encodeErr
+		}
+	}
+	data := l.encode(entry, l.config.Type)
+
+	// * sinks get the entry as encoded, without the file's chain or signature
+	line := data
+	if l.config.Audit {
+		chainName := l.targetName(target)
+		data, l.chain[chainName] = chainEntry(l.chain[chainName], data)
 	}
 
+	if l.config.HMACKey != "" {
+		data = signEntry([]byte(l.config.HMACKey), data)
+	}
+
+	name := l.targetName(target)
+	_, err := target.Writer().Write(data)
+	if err == nil {
+		l.stats.Entries[entry.Level]++
+		l.stats.Bytes[name] += uint64(len(data))
+		if file, isExist := l.File[name]; isExist && l.syncsOnWrite(entry.Level) {
+			if syncErr := file.Sync(); syncErr != nil {
+				l.internalError(fmt.Errorf("Failed to sync %s: %w", name, syncErr))
+			}
+		}
+	} else {
+		l.stats.Failed++
+		// * disk full or permission lost, don't discard the entry silently
+		fallback := l.config.Fallback
+		if fallback == nil {
+			fallback = os.Stderr
+		}
+		fallback.Write(data)
+		l.internalError(fmt.Errorf("Failed to write %s: %w", name, err))
+		// * e.g. a stale handle after the file was removed, later entries go to a new one
+		if reopened, checkErr := l.checkExternal(name); checkErr != nil {
+			l.internalError(checkErr)
+		} else if reopened {
+			if initErr := l.initHandler(); initErr != nil {
+				l.internalError(fmt.Errorf("Failed to re-init: %w", initErr))
+			}
+		}
+	}
+	l.notifyWrite(*entry, err)
+	l.remember(*entry)
+	l.publish(*entry)
+	l.writeSinks(*entry, line)
+	l.checkAlerts(*entry)
+
+	if encodeErr != nil {
+		l.emit(l.ErrorHandler, logWarning, nil, fmt.Sprintf("Failed to encode fields of %q", entry.Message), encodeErr.Error())
+	}
+	return err
+}
+
+// * called under lock before hooks, an entry they drop still leaves a gap in seq;
+// * an ingested entry is numbered here, its own seq belongs to another process, but
+// * keeps its entry_id so resends still dedupe
+func (l *Logger) stamp(entry *Entry) {
+	if l.config.Sequence {
+		// * under lock, numbers follow the order entries reach the files
+		l.sequence++
+		seq := Field{Key: "seq", Value: l.sequence}
+		if i := slices.IndexFunc(entry.Fields, func(field Field) bool { return field.Key == "seq" }); i >= 0 {
+			// * copy, the caller's slice must not change
+			entry.Fields = slices.Clone(entry.Fields)
+			entry.Fields[i] = seq
+		} else {
+			entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], seq)
+		}
+	}
+	if l.config.EntryID && !slices.ContainsFunc(entry.Fields, func(field Field) bool { return field.Key == "entry_id" }) {
+		entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], Field{Key: "entry_id", Value: newEntryID()})
+	}
+}
+
+func (l *Logger) targetName(target *log.Logger) string {
+	if filename, isExist := l.handlerNames[target]; isExist {
+		return filename
+	}
+	return defaultOutputName
+}
+
+func withWriteError(err error, writeErr error) error {
+	if writeErr == nil {
+		return err
+	}
+	return errors.Join(err, writeErr)
+}
+
+func toStrings(messages []any) []string {
+	texts := make([]string, len(messages))
 	for i, msg := range messages {
+		texts[i] = fmt.Sprintf("%v", msg)
+	}
+	return texts
+}
+
+func truncate(texts []string, limit int) []string {
+	if limit <= 0 {
+		return texts
+	}
+
+	remain := limit
+	for i, text := range texts {
+		if len(text) <= remain {
+			remain -= len(text)
+			continue
+		}
+
+		// * keep cut on a rune boundary
+		cut := remain
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		texts[i] = fmt.Sprintf("%s...truncated (%d bytes)", text[:cut], len(text)-cut)
+		remain = 0
+	}
+	return texts
+}
+
+func sanitize(text string) string {
+	if strings.IndexFunc(text, isUnsafe) < 0 {
+		return text
+	}
+
+	var builder strings.Builder
+	for _, r := range text {
 		switch {
-		case i == 0:
-			target.Printf("%s%s", prefix, msg)
-		case i == len(messages)-1:
-			target.Printf("└── %s", msg)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case isUnsafe(r):
+			if r < 0x100 {
+				fmt.Fprintf(&builder, `\x%02x`, r)
+			} else {
+				fmt.Fprintf(&builder, `\u%04x`, r)
+			}
 		default:
-			target.Printf("├── %s", msg)
+			builder.WriteRune(r)
 		}
 	}
+	return builder.String()
+}
+
+func isUnsafe(r rune) bool {
+	return unicode.IsControl(r) || r == '\u2028' || r == '\u2029'
 }
 
 func (l *Logger) Debug(messages ...any) {
-	l.writeToLog(l.DebugHandler, logDebug, defaultDebugName, messages...)
+	l.writeToLog(logDebug, defaultDebugName, messages...)
 }
 
 func (l *Logger) Trace(messages ...any) {
-	l.writeToLog(l.DebugHandler, logTrace, defaultDebugName, messages...)
+	l.writeToLog(logTrace, defaultDebugName, messages...)
 }
 
 func (l *Logger) Info(messages ...any) {
-	l.writeToLog(l.OutputHandler, logInfo, defaultOutputName, messages...)
+	l.writeToLog(logInfo, defaultOutputName, messages...)
 }
 
 func (l *Logger) Notice(messages ...any) {
-	l.writeToLog(l.OutputHandler, logNotice, defaultOutputName, messages...)
+	l.writeToLog(logNotice, defaultOutputName, messages...)
 }
 
 func (l *Logger) Warn(messages ...any) {
-	l.writeToLog(l.OutputHandler, logWarning, defaultOutputName, messages...)
+	l.writeToLog(logWarning, defaultOutputName, messages...)
 }
 
 func (l *Logger) WarnError(err error, messages ...any) error {
-	if err != nil {
-		messages = append(messages, err.Error())
-	}
-	l.writeToLog(l.ErrorHandler, logWarning, defaultErrorName, messages...)
-	strMessages := make([]string, len(messages))
-	for i, msg := range messages {
-		strMessages[i] = fmt.Sprintf("%v", msg)
-	}
-	return fmt.Errorf("%s", strings.Join(strMessages, " "))
+	return l.writeError(nil, logWarning, err, messages...)
 }
 
 func (l *Logger) Error(err error, messages ...any) error {
-	if err != nil {
-		messages = append(messages, err.Error())
-	}
-	l.writeToLog(l.ErrorHandler, logError, defaultErrorName, messages...)
-	strMessages := make([]string, len(messages))
-	for i, msg := range messages {
-		strMessages[i] = fmt.Sprintf("%v", msg)
-	}
-	return fmt.Errorf("%s", strings.Join(strMessages, " "))
+	return l.writeError(nil, logError, err, messages...)
 }
 
 func (l *Logger) Fatal(err error, messages ...any) error {
-	if err != nil {
-		messages = append(messages, err.Error())
-	}
-	l.writeToLog(l.ErrorHandler, logFatal, defaultErrorName, messages...)
-	strMessages := make([]string, len(messages))
-	for i, msg := range messages {
-		strMessages[i] = fmt.Sprintf("%v", msg)
-	}
-	return fmt.Errorf("%s", strings.Join(strMessages, " "))
+	return l.writeError(nil, logFatal, err, messages...)
 }
 
 func (l *Logger) Critical(err error, messages ...any) error {
-	if err != nil {
-		messages = append(messages, err.Error())
-	}
-	l.writeToLog(l.ErrorHandler, logCritical, defaultErrorName, messages...)
-	strMessages := make([]string, len(messages))
-	for i, msg := range messages {
-		strMessages[i] = fmt.Sprintf("%v", msg)
-	}
-	return fmt.Errorf("%s", strings.Join(strMessages, " "))
+	return l.writeError(nil, logCritical, err, messages...)
+}
+
+// * error levels always go to error.log, WARNING included
+func (l *Logger) writeError(scope *Child, level string, err error, messages ...any) error {
+	// * the returned error needs the values whether or not the entry is written
+	messages = resolveLazy(messages)
+	writeErr := l.writeScoped(scope, defaultErrorName, level, causeFields(err), appendError(messages, err)...)
+	return withWriteError(composeError(err, messages), writeErr)
 }
@@ -0,0 +1,261 @@
+package goLogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// * a YAML subset covering config files: nested mappings, sequences of scalars
+// * or mappings, flow sequences, quoted scalars and comments; anchors, block
+// * scalars and flow mappings are rejected instead of being misread
+
+type yamlLine struct {
+	number  int
+	indent  int
+	content string
+}
+
+func parseYAML(data []byte) (any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(raw, "---") || strings.HasPrefix(raw, "...") {
+			continue
+		}
+		if strings.Contains(raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))], "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		content := strings.TrimRight(stripYAMLComment(raw), " ")
+		trimmed := strings.TrimLeft(content, " ")
+		if trimmed == "" {
+			continue
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(content) - len(trimmed), content: trimmed})
+	}
+	if len(lines) == 0 {
+		return map[string]any{}, nil
+	}
+
+	value, next, err := parseYAMLNode(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[next].number)
+	}
+	return value, nil
+}
+
+func parseYAMLNode(lines []yamlLine, i int, indent int) (any, int, error) {
+	if isYAMLItem(lines[i].content) {
+		return parseYAMLSequence(lines, i, indent)
+	}
+	if _, _, ok := splitYAMLKey(lines[i].content); ok {
+		return parseYAMLMapping(lines, i, indent)
+	}
+	value, err := parseYAMLScalar(lines[i])
+	return value, i + 1, err
+}
+
+func parseYAMLMapping(lines []yamlLine, i int, indent int) (any, int, error) {
+	result := make(map[string]any)
+	for i < len(lines) && lines[i].indent == indent && !isYAMLItem(lines[i].content) {
+		line := lines[i]
+		key, rest, ok := splitYAMLKey(line.content)
+		if !ok {
+			return nil, i, fmt.Errorf("line %d: expected key: value", line.number)
+		}
+		if _, exists := result[key]; exists {
+			return nil, i, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		i++
+
+		if rest != "" {
+			value, err := parseYAMLScalar(yamlLine{number: line.number, content: rest})
+			if err != nil {
+				return nil, i, err
+			}
+			result[key] = value
+			continue
+		}
+
+		switch {
+		case i < len(lines) && lines[i].indent > indent:
+			value, next, err := parseYAMLNode(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, i, err
+			}
+			result[key], i = value, next
+		case i < len(lines) && lines[i].indent == indent && isYAMLItem(lines[i].content):
+			// * sequences may sit at the same indentation as their key
+			value, next, err := parseYAMLSequence(lines, i, indent)
+			if err != nil {
+				return nil, i, err
+			}
+			result[key], i = value, next
+		default:
+			result[key] = nil
+		}
+	}
+	if i < len(lines) && lines[i].indent > indent {
+		return nil, i, fmt.Errorf("line %d: unexpected indentation", lines[i].number)
+	}
+	return result, i, nil
+}
+
+func parseYAMLSequence(lines []yamlLine, i int, indent int) (any, int, error) {
+	result := []any{}
+	for i < len(lines) && lines[i].indent == indent && isYAMLItem(lines[i].content) {
+		line := lines[i]
+		rest := strings.TrimLeft(strings.TrimPrefix(line.content, "-"), " ")
+
+		if rest == "" {
+			i++
+			if i < len(lines) && lines[i].indent > indent {
+				value, next, err := parseYAMLNode(lines, i, lines[i].indent)
+				if err != nil {
+					return nil, i, err
+				}
+				result, i = append(result, value), next
+			} else {
+				result = append(result, nil)
+			}
+			continue
+		}
+
+		// * "- key: value" opens a mapping whose keys align after the dash
+		childIndent := indent + len(line.content) - len(rest)
+		lines[i] = yamlLine{number: line.number, indent: childIndent, content: rest}
+		value, next, err := parseYAMLNode(lines, i, childIndent)
+		if err != nil {
+			return nil, i, err
+		}
+		result, i = append(result, value), next
+	}
+	return result, i, nil
+}
+
+func parseYAMLScalar(line yamlLine) (any, error) {
+	text := line.content
+	switch {
+	case strings.HasPrefix(text, `"`):
+		var value string
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil, fmt.Errorf("line %d: invalid quoted string %s", line.number, text)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("line %d: invalid quoted string %s", line.number, text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("line %d: unterminated flow sequence", line.number)
+		}
+		result := []any{}
+		for _, item := range splitYAMLFlow(text[1 : len(text)-1]) {
+			value, err := parseYAMLScalar(yamlLine{number: line.number, content: item})
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	case strings.HasPrefix(text, "{"), strings.HasPrefix(text, "&"), strings.HasPrefix(text, "*"),
+		strings.HasPrefix(text, "|"), strings.HasPrefix(text, ">"), strings.HasPrefix(text, "!"):
+		return nil, fmt.Errorf("line %d: unsupported YAML syntax %q", line.number, text)
+	}
+
+	switch text {
+	case "true", "True", "TRUE", "yes", "on":
+		return true, nil
+	case "false", "False", "FALSE", "no", "off":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	if value, ok := parseYAMLInt(text); ok {
+		return value, nil
+	}
+	if value, err := strconv.ParseFloat(text, 64); err == nil {
+		return value, nil
+	}
+	return text, nil
+}
+
+// * YAML 1.2 integers: decimal, 0x hex and 0o octal, so 010 stays ten as in a
+// * JSON config instead of turning octal
+func parseYAMLInt(text string) (int64, bool) {
+	text = strings.ReplaceAll(text, "_", "")
+	base := 10
+	if digits, ok := strings.CutPrefix(text, "0x"); ok {
+		text, base = digits, 16
+	} else if digits, ok := strings.CutPrefix(text, "0o"); ok {
+		text, base = digits, 8
+	}
+	if base != 10 && (text == "" || text[0] == '-' || text[0] == '+') {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(text, base, 64)
+	return value, err == nil
+}
+
+func isYAMLItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+func splitYAMLKey(content string) (string, string, bool) {
+	if strings.HasPrefix(content, `"`) || strings.HasPrefix(content, "'") || strings.HasPrefix(content, "[") {
+		return "", "", false
+	}
+	if key, ok := strings.CutSuffix(content, ":"); ok && !strings.Contains(key, ": ") {
+		return strings.TrimSpace(key), "", true
+	}
+	key, rest, ok := strings.Cut(content, ": ")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(rest), true
+}
+
+func splitYAMLFlow(text string) []string {
+	var items []string
+	var quote rune
+	start := 0
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" || len(items) > 0 {
+		items = append(items, last)
+	}
+	return items
+}
+
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}